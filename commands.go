@@ -0,0 +1,332 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"perennial-wisdom/db"
+	"perennial-wisdom/store"
+)
+
+// runCommand dispatches CLI subcommands, e.g. `perennial-wisdom migrate up`.
+// With no subcommand, main starts the web server instead.
+func runCommand(args []string) error {
+	switch args[0] {
+	case "migrate":
+		return migrateCommand(args[1:])
+	case "seed":
+		return seedCommand(args[1:])
+	case "import":
+		return importCommand(args[1:])
+	case "export":
+		return exportCommand(args[1:])
+	case "validate":
+		return validateCommand(args[1:])
+	case "graph":
+		return graphCommand(args[1:])
+	default:
+		return fmt.Errorf("unknown command %q (available: migrate, seed, import, export, validate, graph)", args[0])
+	}
+}
+
+// migrateCommand runs schema migrations:
+//
+//	migrate [up]   [-to N] [-dry-run]   apply pending migrations (up to N)
+//	migrate down   [-to N] [-dry-run]   revert to version N (default: previous)
+//	migrate status                      list migrations and applied state
+func migrateCommand(args []string) error {
+	direction := "up"
+	if len(args) > 0 && args[0] != "" && args[0][0] != '-' {
+		direction, args = args[0], args[1:]
+	}
+
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	to := fs.Int("to", -1, "target version")
+	dryRun := fs.Bool("dry-run", false, "print SQL without executing it")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	database := db.Open(os.Getenv("DB_PATH"))
+	defer database.Close()
+
+	m := db.NewMigrator(database)
+	m.DryRun = *dryRun
+	m.Out = os.Stdout
+
+	switch direction {
+	case "up":
+		target := *to
+		if target < 0 {
+			target = 0
+		}
+		ran, err := m.Up(target)
+		fmt.Printf("applied %d migration(s)\n", len(ran))
+		return err
+
+	case "down":
+		target := *to
+		if target < 0 {
+			// Default: revert only the most recent applied migration.
+			status, err := m.Status()
+			if err != nil {
+				return err
+			}
+			target = 0
+			latest := 0
+			for _, s := range status {
+				if s.Applied {
+					target, latest = latest, s.Version
+				}
+			}
+		}
+		ran, err := m.Down(target)
+		fmt.Printf("reverted %d migration(s)\n", len(ran))
+		return err
+
+	case "status":
+		status, err := m.Status()
+		if err != nil {
+			return err
+		}
+		for _, s := range status {
+			mark := " "
+			if s.Applied {
+				mark = "x"
+			}
+			fmt.Printf("[%s] %04d_%s\n", mark, s.Version, s.Name)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unknown migrate direction %q (use up, down or status)", direction)
+	}
+}
+
+// seedCommand upserts the store seed corpus into the database,
+// applying pending migrations first so it works on a fresh environment.
+func seedCommand(args []string) error {
+	fs := flag.NewFlagSet("seed", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	database := db.Open(os.Getenv("DB_PATH"))
+	defer database.Close()
+
+	if err := db.Migrate(database); err != nil {
+		return err
+	}
+	if err := db.Seed(database); err != nil {
+		return err
+	}
+	fmt.Println("seed corpus loaded")
+	return nil
+}
+
+// importCommand bulk-loads quotes from a spreadsheet export as drafts:
+//
+//	import [-format csv|json|yaml] [-dry-run] [-author NAME] [-note TEXT] FILE
+//
+// FILE "-" reads standard input. The format defaults to FILE's extension.
+// Every row is checked before anything is written; any problem aborts the
+// whole import, and -dry-run only reports.
+func importCommand(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	format := fs.String("format", "", "csv, json or yaml (default: from the file extension)")
+	dryRun := fs.Bool("dry-run", false, "check and report without writing")
+	author := fs.String("author", "import", "author recorded in the revision history")
+	note := fs.String("note", "", "change note (default: import FILE)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: import [flags] FILE")
+	}
+	path := fs.Arg(0)
+
+	in := os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	if *format == "" {
+		*format = store.FormatOf(path)
+	}
+	if *note == "" {
+		*note = "import " + filepath.Base(path)
+	}
+
+	database := db.Open(os.Getenv("DB_PATH"))
+	defer database.Close()
+	if err := db.Migrate(database); err != nil {
+		return err
+	}
+	repo := db.NewRepository(db.NewQueries(database))
+
+	report, err := store.Import(repo, in, store.ImportOptions{
+		Format: *format,
+		DryRun: *dryRun,
+		Change: store.Change{Author: *author, Note: *note},
+	})
+	if err != nil {
+		return err
+	}
+	for _, p := range report.Problems {
+		fmt.Printf("row %d (%s) %s: %s\n", p.Row, p.ID, p.Field, p.Message)
+	}
+	switch {
+	case !report.OK():
+		return fmt.Errorf("%d problem(s) in %d row(s); nothing imported", len(report.Problems), report.Rows)
+	case report.DryRun:
+		fmt.Printf("dry run: %d row(s) would import as drafts: %s\n", report.Rows, strings.Join(report.Created, ", "))
+	default:
+		fmt.Printf("imported %d quote(s) as drafts: %s\n", len(report.Created), strings.Join(report.Created, ", "))
+	}
+	return nil
+}
+
+// exportCommand streams the public corpus, as GET /api/export does:
+//
+//	export [-format ndjson|jsonld] [-base URL] [-o FILE]
+//
+// Output goes to standard output unless -o names a file. -base is the
+// site URL JSON-LD node IDs resolve against.
+func exportCommand(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := fs.String("format", store.FormatNDJSON, "ndjson or jsonld")
+	base := fs.String("base", "http://localhost:8080", "site URL for JSON-LD node IDs")
+	out := fs.String("o", "", "write to FILE instead of standard output")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	database := db.Open(os.Getenv("DB_PATH"))
+	defer database.Close()
+	if err := db.Migrate(database); err != nil {
+		return err
+	}
+	repo := db.NewRepository(db.NewQueries(database))
+
+	if *out == "" {
+		return store.Export(repo, os.Stdout, *format, *base)
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if err := store.Export(repo, f, *format, *base); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// graphCommand writes the corpus graph, as GET /api/graph does:
+//
+//	graph [-format graphml|dot|json] [-root ID] [-depth N] [-o FILE]
+//
+// With -root only the nodes within -depth links of it are written.
+// Output goes to standard output unless -o names a file.
+func graphCommand(args []string) error {
+	fs := flag.NewFlagSet("graph", flag.ContinueOnError)
+	format := fs.String("format", store.FormatJSONGraph, "graphml, dot or json")
+	root := fs.String("root", "", "only the neighborhood of this entity ID (or kind:id)")
+	depth := fs.Int("depth", 1, "links to follow from -root")
+	out := fs.String("o", "", "write to FILE instead of standard output")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	database := db.Open(os.Getenv("DB_PATH"))
+	defer database.Close()
+	if err := db.Migrate(database); err != nil {
+		return err
+	}
+	g, err := store.BuildGraph(db.NewRepository(db.NewQueries(database)))
+	if err != nil {
+		return err
+	}
+	if *root != "" {
+		if g, err = g.Neighborhood(*root, *depth); err != nil {
+			return fmt.Errorf("root %q: %w", *root, err)
+		}
+	}
+
+	if *out == "" {
+		return store.WriteGraph(os.Stdout, g, *format)
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if err := store.WriteGraph(f, g, *format); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// validateCommand checks the integrity of the whole corpus:
+//
+//	validate [-seed] [-json]
+//
+// It reads the database unless -seed asks for the built-in seed corpus.
+// -json prints the full report for tools; otherwise one line per issue.
+// Errors make the command fail; warnings don't.
+func validateCommand(args []string) error {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	seed := fs.Bool("seed", false, "validate the seed corpus instead of the database")
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var repo store.Repository
+	if *seed {
+		repo = store.New()
+	} else {
+		database := db.Open(os.Getenv("DB_PATH"))
+		defer database.Close()
+		if err := db.Migrate(database); err != nil {
+			return err
+		}
+		repo = db.NewRepository(db.NewQueries(database))
+	}
+
+	report, err := store.Validate(repo)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		for _, is := range report.Issues {
+			field := ""
+			if is.Field != "" {
+				field = " " + is.Field
+			}
+			fmt.Printf("%-7s %-19s %s %s%s: %s\n", is.Severity, is.Check, is.Kind, is.ID, field, is.Message)
+		}
+		fmt.Printf("checked %d quotes, %d philosophers, %d schools, %d themes, %d evidence: %d error(s), %d warning(s)\n",
+			report.Counts["quote"], report.Counts["philosopher"], report.Counts["philosophy"],
+			report.Counts["theme"], report.Counts["evidence"],
+			report.Errors(), len(report.Issues)-report.Errors())
+	}
+	if !report.OK() {
+		return fmt.Errorf("corpus has %d integrity error(s)", report.Errors())
+	}
+	return nil
+}
@@ -4,14 +4,32 @@ import (
 	"database/sql"
 	"log"
 	"os"
+	"path/filepath"
 
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
 )
 
-// Open connects to PostgreSQL using DATABASE_URL or constructs from components.
-// Returns a sqlx.DB which wraps database/sql with named-query support.
+// Open connects to the application database and returns a sqlx.DB,
+// which wraps database/sql with named-query support.
+//
+// Backend selection:
+//   - DATABASE_URL or DB_HOST set → PostgreSQL
+//   - otherwise dbPath (or DB_PATH) set → SQLite file, pure Go, no server
+//   - neither → PostgreSQL with local defaults
 func Open(dbPath string) *sqlx.DB {
+	if dbPath == "" {
+		dbPath = os.Getenv("DB_PATH")
+	}
+	if os.Getenv("DATABASE_URL") == "" && os.Getenv("DB_HOST") == "" && dbPath != "" {
+		return openSQLite(dbPath)
+	}
+	return openPostgres()
+}
+
+// openPostgres connects using DATABASE_URL or constructs it from components.
+func openPostgres() *sqlx.DB {
 	// Check for PostgreSQL connection string
 	connStr := os.Getenv("DATABASE_URL")
 	if connStr == "" {
@@ -56,3 +74,34 @@ func Open(dbPath string) *sqlx.DB {
 	log.Println("Connected to PostgreSQL")
 	return sqlx.NewDb(conn, "postgres")
 }
+
+// openSQLite opens (creating if needed) a SQLite database file.
+// WAL lets readers proceed during writes; busy_timeout absorbs brief
+// write contention; immediate transactions avoid lock-upgrade deadlocks.
+func openSQLite(path string) *sqlx.DB {
+	dsn := path
+	if path != ":memory:" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			log.Fatalf("failed to create database directory: %v", err)
+		}
+		dsn = "file:" + path + "?_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_txlock=immediate"
+	}
+
+	conn, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		log.Fatalf("failed to open database: %v", err)
+	}
+
+	if path == ":memory:" {
+		// Every connection to :memory: is a separate database — keep one.
+		conn.SetMaxOpenConns(1)
+		conn.Exec("PRAGMA foreign_keys=ON")
+	}
+
+	if err := conn.Ping(); err != nil {
+		log.Fatalf("failed to ping database: %v", err)
+	}
+
+	log.Printf("Opened SQLite database at %s", path)
+	return sqlx.NewDb(conn, "sqlite")
+}
@@ -0,0 +1,29 @@
+package db
+
+import "github.com/jmoiron/sqlx"
+
+// SQL dialects understood by the db package.
+const (
+	Postgres = "postgres"
+	SQLite   = "sqlite"
+)
+
+// DialectOf reports which SQL dialect a connection speaks,
+// based on the driver name it was opened with.
+func DialectOf(db *sqlx.DB) string {
+	switch db.DriverName() {
+	case "sqlite", "sqlite3":
+		return SQLite
+	default:
+		return Postgres
+	}
+}
+
+// groupConcat aggregates a column into one comma-separated string.
+// Order is unspecified; callers sort after splitting.
+func groupConcat(dialect, col string) string {
+	if dialect == SQLite {
+		return "group_concat(" + col + ", ',')"
+	}
+	return "string_agg(" + col + ", ',')"
+}
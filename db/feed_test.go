@@ -0,0 +1,75 @@
+package db_test
+
+import (
+	"testing"
+	"time"
+
+	"perennial-wisdom/db"
+	"perennial-wisdom/models"
+	"perennial-wisdom/store"
+)
+
+// checkStamps publishes a new quote through any ReadWriter and checks
+// the stamps the feeds date it by.
+func checkStamps(t *testing.T, rw store.ReadWriter) {
+	t.Helper()
+	ch := store.Change{Author: "ana"}
+	wf := store.Workflow{}
+	start := time.Now().UTC().Truncate(time.Second)
+	later := start.Add(48 * time.Hour)
+
+	for _, id := range []string{"f-now", "f-later"} {
+		if err := rw.CreateQuote(models.Quote{ID: id, Text: "Feed " + id}, ch); err != nil {
+			t.Fatalf("CreateQuote: %v", err)
+		}
+	}
+	stamps, err := rw.QuoteStamps()
+	if err != nil {
+		t.Fatalf("QuoteStamps: %v", err)
+	}
+	if st := stamps["f-now"]; !st.Published.IsZero() || st.Updated.Before(start) {
+		t.Errorf("draft stamped %+v", st)
+	}
+	if st := stamps["e1"]; st.Published.IsZero() || st.Updated.IsZero() {
+		t.Errorf("seeded quote stamped %+v", st)
+	}
+
+	if err := wf.Transition(rw, "f-now", store.StatusPublished, nil, ch); err != nil {
+		t.Fatalf("Transition: %v", err)
+	}
+	if err := wf.Transition(rw, "f-later", store.StatusPublished, &later, ch); err != nil {
+		t.Fatalf("Transition: %v", err)
+	}
+	if stamps, err = rw.QuoteStamps(); err != nil {
+		t.Fatalf("QuoteStamps: %v", err)
+	}
+	if st := stamps["f-now"]; st.Published.Before(start) || st.Published.After(time.Now()) {
+		t.Errorf("published quote stamped %+v, want about %v", st, start)
+	}
+	if st := stamps["f-later"]; !st.Published.Equal(later) {
+		t.Errorf("scheduled quote published %v, want %v", st.Published, later)
+	}
+
+	// Editing a published quote leaves when it was published alone.
+	published := stamps["f-now"].Published
+	q, _ := rw.GetQuote("f-now")
+	q.Source = "Fragments"
+	if err := rw.UpdateQuote(q, ch); err != nil {
+		t.Fatalf("UpdateQuote: %v", err)
+	}
+	if stamps, _ = rw.QuoteStamps(); !stamps["f-now"].Published.Equal(published) {
+		t.Errorf("edit moved publication from %v to %v", published, stamps["f-now"].Published)
+	}
+
+	if err := rw.DeleteQuote("f-now", ch); err != nil {
+		t.Fatalf("DeleteQuote: %v", err)
+	}
+	if stamps, _ = rw.QuoteStamps(); !stamps["f-now"].Updated.IsZero() {
+		t.Errorf("deleted quote still stamped")
+	}
+}
+
+func TestQuoteStampsMatchMemoryStore(t *testing.T) {
+	checkStamps(t, db.NewRepository(db.NewQueries(seededDB(t))))
+	checkStamps(t, store.New())
+}
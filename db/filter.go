@@ -0,0 +1,179 @@
+package db
+
+import (
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"perennial-wisdom/store"
+)
+
+// builder accumulates WHERE conditions and their bound arguments,
+// numbering placeholders ($1, $2, ...) as it goes — any number of them.
+type builder struct {
+	where []string
+	args  []any
+}
+
+// arg binds a value and returns its placeholder.
+func (b *builder) arg(v any) string {
+	b.args = append(b.args, v)
+	return "$" + strconv.Itoa(len(b.args))
+}
+
+// list binds each value and returns a comma-separated placeholder list.
+func (b *builder) list(values []string) string {
+	ph := make([]string, len(values))
+	for i, v := range values {
+		ph[i] = b.arg(v)
+	}
+	return strings.Join(ph, ", ")
+}
+
+// and adds a condition.
+func (b *builder) and(cond string) {
+	b.where = append(b.where, cond)
+}
+
+// sql renders " WHERE ..." (or "" when there are no conditions).
+func (b *builder) sql() string {
+	if len(b.where) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(b.where, " AND ")
+}
+
+// dimension maps a filter dimension onto SQL: either a column on the
+// main table, or a join table linking the main table's id to values.
+type dimension struct {
+	column string // scalar: q.philosopher_id
+
+	owner    string // join: q.id
+	table    string // join: quote_themes
+	ownerCol string // join: quote_id
+	valueCol string // join: theme_id
+}
+
+var quoteDimensions = map[string]dimension{
+	"philosopher": {column: "q.philosopher_id"},
+	"philosophy":  {column: "q.tradition_id"},
+	"tradition":   {column: "q.tradition_id"},
+	"theme":       {owner: "q.id", table: "quote_themes", ownerCol: "quote_id", valueCol: "theme_id"},
+	"evidence":    {owner: "q.id", table: "quote_evidence", ownerCol: "quote_id", valueCol: "evidence_id"},
+}
+
+var philosopherDimensions = map[string]dimension{
+	"philosophy": {column: "p.tradition_id"},
+	"tradition":  {column: "p.tradition_id"},
+}
+
+var evidenceDimensions = map[string]dimension{
+	"field": {column: "e.field"},
+	"theme": {owner: "e.id", table: "evidence_themes", ownerCol: "evidence_id", valueCol: "theme_id"},
+}
+
+// applyFilter translates f into conditions on b. Dimensions missing from
+// dims are ignored. Map iteration is sorted so generated SQL is stable.
+func applyFilter(b *builder, f store.Filter, dims map[string]dimension) {
+	names := make([]string, 0, len(f))
+	for name := range f {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		d, ok := dims[name]
+		if !ok {
+			continue
+		}
+		c := f[name]
+
+		if d.column != "" {
+			if len(c.Values) > 0 {
+				if c.All && len(uniq(c.Values)) > 1 {
+					// A scalar column can't hold two different values at once.
+					b.and("1=0")
+				} else {
+					b.and(d.column + " IN (" + b.list(c.Values) + ")")
+				}
+			}
+			if len(c.Exclude) > 0 {
+				b.and("(" + d.column + " IS NULL OR " + d.column + " NOT IN (" + b.list(c.Exclude) + "))")
+			}
+			continue
+		}
+
+		sub := "SELECT " + d.ownerCol + " FROM " + d.table + " WHERE " + d.valueCol + " IN ("
+		if len(c.Values) > 0 {
+			if c.All {
+				b.and(d.owner + " IN (" + sub + b.list(c.Values) + ") GROUP BY " + d.ownerCol +
+					" HAVING COUNT(DISTINCT " + d.valueCol + ") = " + strconv.Itoa(len(uniq(c.Values))) + ")")
+			} else {
+				b.and(d.owner + " IN (" + sub + b.list(c.Values) + "))")
+			}
+		}
+		if len(c.Exclude) > 0 {
+			b.and(d.owner + " NOT IN (" + sub + b.list(c.Exclude) + "))")
+		}
+	}
+}
+
+// uniq returns values without duplicates, preserving order.
+func uniq(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var out []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// paginate adds p's keyset condition on the key columns cols — the ID
+// column, or the parts of a composite key such as store.NameKey, ending
+// with the ID — and returns the ORDER BY / LIMIT tail. It fetches one
+// row past p.Limit so store.Trim can tell whether another page exists.
+// Backward pages are fetched in descending order; ascending puts them
+// back.
+func paginate(b *builder, p store.Page, cols ...string) string {
+	if p.Limit <= 0 {
+		return " ORDER BY " + strings.Join(cols, ", ")
+	}
+	dir, op, key := " ASC", " > ", p.After
+	if p.Before != "" {
+		dir, op, key = " DESC", " < ", p.Before
+	}
+	if key != "" {
+		parts := store.KeyParts(key, len(cols))
+		args := make([]string, len(parts))
+		for i, part := range parts {
+			args[i] = b.arg(part)
+		}
+		b.and(row(cols) + op + row(args))
+	}
+	order := make([]string, len(cols))
+	for i, col := range cols {
+		order[i] = col + dir
+	}
+	return " ORDER BY " + strings.Join(order, ", ") + " LIMIT " + strconv.Itoa(p.Limit+1)
+}
+
+// row is a row value of exprs, compared column by column; one
+// expression is just itself.
+func row(exprs []string) string {
+	if len(exprs) == 1 {
+		return exprs[0]
+	}
+	return "(" + strings.Join(exprs, ", ") + ")"
+}
+
+// ascending restores key order for rows fetched by a backward page.
+func ascending[T any](rows []T, p store.Page) []T {
+	if p.Limit > 0 && p.Before != "" {
+		slices.Reverse(rows)
+	}
+	return rows
+}
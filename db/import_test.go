@@ -0,0 +1,51 @@
+package db_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"perennial-wisdom/db"
+	"perennial-wisdom/models"
+	"perennial-wisdom/store"
+)
+
+func TestImportRunsInOneTransaction(t *testing.T) {
+	repo := db.NewRepository(db.NewQueries(seededDB(t)))
+
+	csv := "id,text,philosopher,tradition,themes\n" +
+		"imp-1,Imported in a batch.,Epictetus,Stoicism,control;virtue\n" +
+		"imp-2,Also imported.,epictetus,stoic,Impermanence\n"
+	report, err := store.Import(repo, strings.NewReader(csv), store.ImportOptions{Format: store.FormatCSV, Change: edit})
+	if err != nil || !report.OK() || len(report.Created) != 2 {
+		t.Fatalf("import: %+v (%v)", report, err)
+	}
+	q, err := repo.GetQuote("imp-1")
+	if err != nil || q.PhilosophyID != "stoic" || len(q.ThemeIDs) != 2 || q.Status != store.StatusDraft {
+		t.Errorf("imported quote: %+v (%v)", q, err)
+	}
+	if revs, _ := repo.ListRevisions("quote", "imp-2"); len(revs) != 1 || revs[0].Note != "test edit" {
+		t.Errorf("expected a create revision for imp-2, got %+v", revs)
+	}
+
+	// A failure part-way through a batch leaves no trace.
+	boom := errors.New("boom")
+	err = repo.Batch(func(rw store.ReadWriter) error {
+		if err := rw.CreateQuote(models.Quote{ID: "imp-3", Text: "Rolled back."}, edit); err != nil {
+			return err
+		}
+		if _, err := rw.GetQuote("imp-3"); err != nil {
+			t.Errorf("a batch should see its own writes: %v", err)
+		}
+		return boom
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("expected the batch's error, got %v", err)
+	}
+	if _, err := repo.GetQuote("imp-3"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("write inside a failed batch was committed: %v", err)
+	}
+	if revs, _ := repo.ListRevisions("quote", "imp-3"); len(revs) != 0 {
+		t.Errorf("revision inside a failed batch was committed: %+v", revs)
+	}
+}
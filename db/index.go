@@ -0,0 +1,89 @@
+package db
+
+import (
+	"errors"
+	"log"
+	"sync"
+
+	"perennial-wisdom/store"
+)
+
+// searchIndex serves full-text search on SQLite, which has none worth
+// the name, from a store.Index over every quote. It is built on the
+// first search (or by WarmSearch) and kept up to date after each write
+// commits; a write it can't follow drops it, to be rebuilt on demand.
+type searchIndex struct {
+	mu    sync.Mutex // held while building, and while applying a write
+	index *store.Index
+}
+
+// written is a write a Batch made, to apply to the index if it commits.
+type written struct{ kind, id, action string }
+
+// WarmSearch builds the search index now rather than on the first
+// search, so the first visitor doesn't wait for it. It does nothing on
+// PostgreSQL, which searches in SQL.
+func (r *Repository) WarmSearch() error {
+	if r.search == nil {
+		return nil
+	}
+	_, err := r.searchIndex()
+	return err
+}
+
+// searchIndex returns the search index, building it if need be.
+func (r *Repository) searchIndex() (*store.Index, error) {
+	r.search.mu.Lock()
+	defer r.search.mu.Unlock()
+	if r.search.index == nil {
+		quotes, _, err := r.ListQuotes(store.Where("status", store.Statuses...), store.Page{})
+		if err != nil {
+			return nil, err
+		}
+		r.search.index = store.NewIndex(quotes)
+	}
+	return r.search.index, nil
+}
+
+// reindex applies a committed write to the search index. Inside a Batch
+// the write is only noted, for Batch to apply once it commits.
+func (r *Repository) reindex(kind, id, action string) {
+	if r.search == nil {
+		return
+	}
+	if r.pending != nil {
+		*r.pending = append(*r.pending, written{kind, id, action})
+		return
+	}
+	r.search.mu.Lock()
+	defer r.search.mu.Unlock()
+	if r.search.index == nil {
+		return // built from scratch on the next search
+	}
+	if kind != "quote" {
+		if action == "delete" {
+			// ON DELETE rules edited the quotes that referred to it.
+			r.search.index = nil
+		}
+		return
+	}
+	q, err := r.GetQuote(id)
+	switch {
+	case errors.Is(err, store.ErrNotFound) || err == nil && q.ID != id:
+		r.search.index.Remove(id)
+	case err != nil:
+		log.Printf("search index: rereading quote %s: %v; rebuilding", id, err)
+		r.search.index = nil
+	default:
+		r.search.index.Put(q)
+	}
+}
+
+// searchIndexed answers a search from the index.
+func (r *Repository) searchIndexed(s store.SearchQuery, name func(kind, id string) string) (store.SearchResults, error) {
+	x, err := r.searchIndex()
+	if err != nil {
+		return store.SearchResults{}, err
+	}
+	return x.Search(s, name), nil
+}
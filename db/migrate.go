@@ -0,0 +1,288 @@
+package db
+
+import (
+	"embed"
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Migrations are plain SQL files compiled into the binary:
+//
+//	migrations/NNNN_name.up.sql            — applied on every dialect
+//	migrations/NNNN_name.down.sql
+//	migrations/NNNN_name.postgres.up.sql   — dialect-specific override
+//	migrations/NNNN_name.sqlite.down.sql
+//
+// A dialect-specific file wins over the generic one for the same version.
+// A version with files only for another dialect is recorded as a no-op.
+//
+//go:embed migrations/*.sql
+var migrationFS embed.FS
+
+// Migration is one versioned schema change.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus pairs a migration with whether it has been applied.
+type MigrationStatus struct {
+	Migration
+	Applied bool
+}
+
+// LoadMigrations reads the embedded migrations for a dialect, sorted by version.
+func LoadMigrations(dialect string) ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFS, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	// Dialect-specific files override generic ones regardless of read order.
+	specific := map[string]bool{}
+
+	for _, e := range entries {
+		version, name, fileDialect, direction, err := parseMigrationName(e.Name())
+		if err != nil {
+			return nil, err
+		}
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		} else if m.Name != name {
+			return nil, fmt.Errorf("migration %04d has conflicting names %q and %q", version, m.Name, name)
+		}
+
+		// Other dialects' files still register the version, so it is
+		// recorded as applied and versions stay aligned across backends.
+		if fileDialect != "" && fileDialect != dialect {
+			continue
+		}
+		key := strconv.Itoa(version) + "." + direction
+		if fileDialect == "" && specific[key] {
+			continue
+		}
+
+		body, err := migrationFS.ReadFile("migrations/" + e.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		if direction == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+		if fileDialect != "" {
+			specific[key] = true
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// parseMigrationName splits "0001_init.sqlite.up.sql" into its parts.
+func parseMigrationName(file string) (version int, name, dialect, direction string, err error) {
+	base := strings.TrimSuffix(file, ".sql")
+	parts := strings.Split(base, ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, "", "", "", fmt.Errorf("bad migration file name %q", file)
+	}
+
+	direction = parts[len(parts)-1]
+	if direction != "up" && direction != "down" {
+		return 0, "", "", "", fmt.Errorf("migration %q must end in .up.sql or .down.sql", file)
+	}
+	if len(parts) == 3 {
+		dialect = parts[1]
+		if dialect != Postgres && dialect != SQLite {
+			return 0, "", "", "", fmt.Errorf("migration %q has unknown dialect %q", file, dialect)
+		}
+	}
+
+	num, name, ok := strings.Cut(parts[0], "_")
+	if !ok {
+		return 0, "", "", "", fmt.Errorf("migration %q must be named NNNN_name", file)
+	}
+	version, err = strconv.Atoi(num)
+	if err != nil || version <= 0 {
+		return 0, "", "", "", fmt.Errorf("migration %q has invalid version", file)
+	}
+	return version, name, dialect, direction, nil
+}
+
+// Migrator applies and reverts embedded migrations, tracking progress
+// in the schema_migrations table. With DryRun set it only prints the
+// SQL it would run to Out.
+type Migrator struct {
+	db      *sqlx.DB
+	dialect string
+	DryRun  bool
+	Out     io.Writer
+}
+
+// NewMigrator creates a Migrator for the given connection.
+func NewMigrator(db *sqlx.DB) *Migrator {
+	return &Migrator{db: db, dialect: DialectOf(db), Out: io.Discard}
+}
+
+// Migrate brings the schema up to the latest version.
+func Migrate(db *sqlx.DB) error {
+	_, err := NewMigrator(db).Up(0)
+	return err
+}
+
+func (m *Migrator) ensureTable() error {
+	_, err := m.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		name       TEXT NOT NULL,
+		applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	return err
+}
+
+// applied returns the set of recorded versions. A dry run never creates
+// schema_migrations; a missing table simply means nothing is applied.
+func (m *Migrator) applied() (map[int]bool, error) {
+	if !m.DryRun {
+		if err := m.ensureTable(); err != nil {
+			return nil, fmt.Errorf("create schema_migrations: %w", err)
+		}
+	}
+	var versions []int
+	if err := m.db.Select(&versions, "SELECT version FROM schema_migrations"); err != nil {
+		if m.DryRun {
+			return map[int]bool{}, nil
+		}
+		return nil, err
+	}
+	done := make(map[int]bool, len(versions))
+	for _, v := range versions {
+		done[v] = true
+	}
+	return done, nil
+}
+
+// Status lists every known migration and whether it has been applied.
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	migrations, err := LoadMigrations(m.dialect)
+	if err != nil {
+		return nil, err
+	}
+	done, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+	status := make([]MigrationStatus, len(migrations))
+	for i, mg := range migrations {
+		status[i] = MigrationStatus{Migration: mg, Applied: done[mg.Version]}
+	}
+	return status, nil
+}
+
+// Up applies pending migrations in order, stopping after version target.
+// A target of 0 means "latest". Returns the migrations that were (or, in
+// dry-run mode, would be) applied.
+func (m *Migrator) Up(target int) ([]Migration, error) {
+	migrations, err := LoadMigrations(m.dialect)
+	if err != nil {
+		return nil, err
+	}
+	done, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	var ran []Migration
+	for _, mg := range migrations {
+		if target > 0 && mg.Version > target {
+			break
+		}
+		if done[mg.Version] {
+			continue
+		}
+		if err := m.run(mg, mg.Up, "up"); err != nil {
+			return ran, err
+		}
+		ran = append(ran, mg)
+	}
+	return ran, nil
+}
+
+// Down reverts the most recently applied migrations, newest first,
+// until only versions <= target remain. A target of 0 reverts everything.
+func (m *Migrator) Down(target int) ([]Migration, error) {
+	migrations, err := LoadMigrations(m.dialect)
+	if err != nil {
+		return nil, err
+	}
+	done, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	var ran []Migration
+	for i := len(migrations) - 1; i >= 0; i-- {
+		mg := migrations[i]
+		if mg.Version <= target {
+			break
+		}
+		if !done[mg.Version] {
+			continue
+		}
+		if err := m.run(mg, mg.Down, "down"); err != nil {
+			return ran, err
+		}
+		ran = append(ran, mg)
+	}
+	return ran, nil
+}
+
+// run executes one migration step and records it, all in one transaction.
+func (m *Migrator) run(mg Migration, body, direction string) error {
+	label := fmt.Sprintf("%04d_%s.%s", mg.Version, mg.Name, direction)
+
+	if m.DryRun {
+		fmt.Fprintf(m.Out, "-- %s (dry run)\n%s\n", label, strings.TrimSpace(body))
+		return nil
+	}
+	fmt.Fprintf(m.Out, "-- %s\n", label)
+
+	tx, err := m.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if strings.TrimSpace(body) != "" {
+		if _, err := tx.Exec(body); err != nil {
+			return fmt.Errorf("migration %s: %w", label, err)
+		}
+	}
+
+	if direction == "up" {
+		_, err = tx.Exec("INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", mg.Version, mg.Name)
+	} else {
+		_, err = tx.Exec("DELETE FROM schema_migrations WHERE version = $1", mg.Version)
+	}
+	if err != nil {
+		return fmt.Errorf("record migration %s: %w", label, err)
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,126 @@
+package db_test
+
+import (
+	"bytes"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	_ "modernc.org/sqlite"
+
+	"perennial-wisdom/db"
+)
+
+// testDB opens a private in-memory SQLite database.
+// A single connection keeps every query on the same memory database.
+func testDB(t *testing.T) *sqlx.DB {
+	t.Helper()
+	conn, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open in-memory db: %v", err)
+	}
+	conn.SetMaxOpenConns(1)
+	conn.Exec("PRAGMA foreign_keys=ON")
+	t.Cleanup(func() { conn.Close() })
+	return sqlx.NewDb(conn, "sqlite")
+}
+
+func tableExists(t *testing.T, database *sqlx.DB, name string) bool {
+	t.Helper()
+	var n int
+	database.Get(&n, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = $1", name)
+	return n > 0
+}
+
+func TestLoadMigrations(t *testing.T) {
+	for _, dialect := range []string{db.Postgres, db.SQLite} {
+		migrations, err := db.LoadMigrations(dialect)
+		if err != nil {
+			t.Fatalf("%s: %v", dialect, err)
+		}
+		if len(migrations) == 0 {
+			t.Fatalf("%s: expected embedded migrations", dialect)
+		}
+		for i, m := range migrations {
+			if i > 0 && m.Version <= migrations[i-1].Version {
+				t.Errorf("%s: migrations out of order at %d", dialect, m.Version)
+			}
+			if strings.TrimSpace(m.Up) != "" && strings.TrimSpace(m.Down) == "" {
+				t.Errorf("%s: migration %04d_%s has no down", dialect, m.Version, m.Name)
+			}
+		}
+	}
+}
+
+func TestMigrateCreatesSchema(t *testing.T) {
+	database := testDB(t)
+
+	if err := db.Migrate(database); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	for _, table := range []string{"schema_migrations", "quotes", "philosophers", "traditions",
+		"themes", "evidence", "quote_themes", "quote_evidence", "theme_traditions", "revisions"} {
+		if !tableExists(t, database, table) {
+			t.Errorf("expected table %s after migrate", table)
+		}
+	}
+
+	// Running again is a no-op
+	ran, err := db.NewMigrator(database).Up(0)
+	if err != nil {
+		t.Fatalf("second migrate: %v", err)
+	}
+	if len(ran) != 0 {
+		t.Errorf("expected no pending migrations, got %d", len(ran))
+	}
+}
+
+func TestMigrateDownRevertsEverything(t *testing.T) {
+	database := testDB(t)
+	m := db.NewMigrator(database)
+
+	if _, err := m.Up(0); err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	if _, err := m.Down(0); err != nil {
+		t.Fatalf("down: %v", err)
+	}
+
+	if tableExists(t, database, "quotes") {
+		t.Error("expected quotes table dropped after down")
+	}
+	status, err := m.Status()
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	for _, s := range status {
+		if s.Applied {
+			t.Errorf("migration %04d still marked applied", s.Version)
+		}
+	}
+}
+
+func TestMigrateDryRun(t *testing.T) {
+	database := testDB(t)
+	var out bytes.Buffer
+
+	m := db.NewMigrator(database)
+	m.DryRun = true
+	m.Out = &out
+
+	ran, err := m.Up(0)
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if len(ran) == 0 {
+		t.Error("expected dry run to report pending migrations")
+	}
+	if !strings.Contains(out.String(), "CREATE TABLE quotes") {
+		t.Error("expected dry run to print migration SQL")
+	}
+	if tableExists(t, database, "quotes") || tableExists(t, database, "schema_migrations") {
+		t.Error("dry run must not touch the database")
+	}
+}
@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS quote_evidence;
+DROP TABLE IF EXISTS quote_themes;
+DROP TABLE IF EXISTS quotes;
+DROP TABLE IF EXISTS evidence_themes;
+DROP TABLE IF EXISTS evidence;
+DROP TABLE IF EXISTS theme_traditions;
+DROP TABLE IF EXISTS themes;
+DROP TABLE IF EXISTS philosophers;
+DROP TABLE IF EXISTS tradition_relations;
+DROP TABLE IF EXISTS traditions;
@@ -0,0 +1,104 @@
+-- Core corpus schema. Portable across PostgreSQL and SQLite:
+-- TEXT keys, JSONB columns stored as text on SQLite, CURRENT_TIMESTAMP defaults.
+
+CREATE TABLE traditions (
+    id              TEXT PRIMARY KEY,
+    name            TEXT NOT NULL,
+    origin          TEXT,
+    core_principles JSONB NOT NULL DEFAULT '[]',
+    created_at      TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at      TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE tradition_relations (
+    tradition_id TEXT NOT NULL REFERENCES traditions(id) ON DELETE CASCADE,
+    related_id   TEXT NOT NULL REFERENCES traditions(id) ON DELETE CASCADE,
+    PRIMARY KEY (tradition_id, related_id)
+);
+
+CREATE TABLE philosophers (
+    id            TEXT PRIMARY KEY,
+    name          TEXT NOT NULL,
+    tradition_id  TEXT REFERENCES traditions(id) ON DELETE SET NULL,
+    era           TEXT,
+    bio           TEXT,
+    key_teachings JSONB NOT NULL DEFAULT '[]',
+    created_at    TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at    TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_philosophers_tradition ON philosophers(tradition_id);
+
+CREATE TABLE themes (
+    id          TEXT PRIMARY KEY,
+    name        TEXT NOT NULL,
+    description TEXT,
+    created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE theme_traditions (
+    theme_id     TEXT NOT NULL REFERENCES themes(id) ON DELETE CASCADE,
+    tradition_id TEXT NOT NULL REFERENCES traditions(id) ON DELETE CASCADE,
+    PRIMARY KEY (theme_id, tradition_id)
+);
+
+CREATE TABLE evidence (
+    id                TEXT PRIMARY KEY,
+    title             TEXT NOT NULL,
+    finding           TEXT,
+    field             TEXT,
+    citation          TEXT,
+    evidence_strength TEXT,
+    created_at        TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at        TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_evidence_field ON evidence(field);
+
+CREATE TABLE evidence_themes (
+    evidence_id TEXT NOT NULL REFERENCES evidence(id) ON DELETE CASCADE,
+    theme_id    TEXT NOT NULL REFERENCES themes(id) ON DELETE CASCADE,
+    PRIMARY KEY (evidence_id, theme_id)
+);
+
+CREATE TABLE quotes (
+    id                      TEXT PRIMARY KEY,
+    title                   TEXT,
+    slug                    TEXT UNIQUE,
+    text                    TEXT NOT NULL,
+    text_scholarly          TEXT,
+    philosopher_id          TEXT REFERENCES philosophers(id) ON DELETE SET NULL,
+    tradition_id            TEXT REFERENCES traditions(id) ON DELETE SET NULL,
+    source_work             TEXT,
+    source_location         TEXT,
+    original_script         TEXT,
+    exposition_brief        TEXT,
+    exposition_standard     TEXT,
+    exposition_scholarly    TEXT,
+    reflection_prompt       TEXT,
+    modern_reinterpretation TEXT,
+    meta                    JSONB,
+    created_at              TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at              TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_quotes_philosopher ON quotes(philosopher_id);
+CREATE INDEX idx_quotes_tradition ON quotes(tradition_id);
+CREATE INDEX idx_quotes_created_at ON quotes(created_at);
+
+CREATE TABLE quote_themes (
+    quote_id TEXT NOT NULL REFERENCES quotes(id) ON DELETE CASCADE,
+    theme_id TEXT NOT NULL REFERENCES themes(id) ON DELETE CASCADE,
+    PRIMARY KEY (quote_id, theme_id)
+);
+
+CREATE INDEX idx_quote_themes_theme ON quote_themes(theme_id);
+
+CREATE TABLE quote_evidence (
+    quote_id    TEXT NOT NULL REFERENCES quotes(id) ON DELETE CASCADE,
+    evidence_id TEXT NOT NULL REFERENCES evidence(id) ON DELETE CASCADE,
+    PRIMARY KEY (quote_id, evidence_id)
+);
+
+CREATE INDEX idx_quote_evidence_evidence ON quote_evidence(evidence_id);
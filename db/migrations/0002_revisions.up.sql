@@ -0,0 +1,16 @@
+-- Revision history: one immutable row per write to a corpus entity.
+-- No foreign keys — history outlives the entities it describes.
+
+CREATE TABLE revisions (
+    kind       TEXT NOT NULL,
+    entity_id  TEXT NOT NULL,
+    number     INTEGER NOT NULL,
+    action     TEXT NOT NULL,
+    author     TEXT NOT NULL DEFAULT '',
+    note       TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    data       JSONB,
+    PRIMARY KEY (kind, entity_id, number)
+);
+
+CREATE INDEX idx_revisions_created_at ON revisions(created_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_quotes_status;
+ALTER TABLE quotes DROP COLUMN publish_at;
+ALTER TABLE quotes DROP COLUMN status;
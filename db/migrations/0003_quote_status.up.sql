@@ -0,0 +1,8 @@
+-- Editorial workflow for quotes: draft → in_review → published → retired.
+-- Existing quotes were public, so they start out published. A published
+-- quote with a future publish_at (UTC) stays hidden until then.
+
+ALTER TABLE quotes ADD COLUMN status TEXT NOT NULL DEFAULT 'published';
+ALTER TABLE quotes ADD COLUMN publish_at TIMESTAMP;
+
+CREATE INDEX idx_quotes_status ON quotes(status);
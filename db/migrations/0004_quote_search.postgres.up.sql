@@ -0,0 +1,12 @@
+-- Ranked full-text search over quotes (PostgreSQL only; SQLite matches
+-- with LIKE and ranks in Go). Weights follow what a reader sees first:
+-- A for the title and text, B for the brief exposition, C for the
+-- standard exposition and the modern reinterpretation.
+
+ALTER TABLE quotes ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', COALESCE(title, '') || ' ' || COALESCE(text, '')), 'A') ||
+    setweight(to_tsvector('english', COALESCE(exposition_brief, '')), 'B') ||
+    setweight(to_tsvector('english', COALESCE(exposition_standard, '') || ' ' || COALESCE(modern_reinterpretation, '')), 'C')
+) STORED;
+
+CREATE INDEX idx_quotes_search ON quotes USING GIN (search_vector);
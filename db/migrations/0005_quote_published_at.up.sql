@@ -0,0 +1,5 @@
+-- When each quote last went public, for feeds. Set whenever a quote
+-- moves to published; quotes that were already published count from
+-- when they were created, so it stays NULL for them.
+
+ALTER TABLE quotes ADD COLUMN published_at TIMESTAMP;
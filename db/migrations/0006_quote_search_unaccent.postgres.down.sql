@@ -0,0 +1,15 @@
+-- Back to 0004's vector. The unaccent extension stays: it may have been
+-- installed before this migration.
+
+DROP INDEX IF EXISTS idx_quotes_search;
+ALTER TABLE quotes DROP COLUMN search_vector;
+
+ALTER TABLE quotes ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', COALESCE(title, '') || ' ' || COALESCE(text, '')), 'A') ||
+    setweight(to_tsvector('english', COALESCE(exposition_brief, '')), 'B') ||
+    setweight(to_tsvector('english', COALESCE(exposition_standard, '') || ' ' || COALESCE(modern_reinterpretation, '')), 'C')
+) STORED;
+
+CREATE INDEX idx_quotes_search ON quotes USING GIN (search_vector);
+
+DROP FUNCTION IF EXISTS search_unaccent(text);
@@ -0,0 +1,21 @@
+-- Fold diacritics out of the quote search vector, as the query is folded,
+-- so "Śaṅkara" and "Sankara" find the same quotes. unaccent() itself is
+-- only STABLE, so a generated column needs it wrapped as IMMUTABLE, with
+-- its dictionary named outright.
+
+CREATE EXTENSION IF NOT EXISTS unaccent SCHEMA public;
+
+CREATE FUNCTION search_unaccent(text) RETURNS text
+    LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT
+    AS $$ SELECT public.unaccent('public.unaccent'::regdictionary, $1) $$;
+
+DROP INDEX IF EXISTS idx_quotes_search;
+ALTER TABLE quotes DROP COLUMN search_vector;
+
+ALTER TABLE quotes ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', search_unaccent(COALESCE(title, '') || ' ' || COALESCE(text, ''))), 'A') ||
+    setweight(to_tsvector('english', search_unaccent(COALESCE(exposition_brief, ''))), 'B') ||
+    setweight(to_tsvector('english', search_unaccent(COALESCE(exposition_standard, '') || ' ' || COALESCE(modern_reinterpretation, ''))), 'C')
+) STORED;
+
+CREATE INDEX idx_quotes_search ON quotes USING GIN (search_vector);
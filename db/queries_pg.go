@@ -3,43 +3,69 @@ package db
 import (
 	"database/sql"
 	"encoding/json"
+	"maps"
+	"strings"
+	"time"
 
 	"github.com/jmoiron/sqlx"
+
+	"perennial-wisdom/store"
 )
 
 // Queries provides all database queries for the application.
 // Explicit SQL — no ORM, no reflection, fully greppable.
+// Portable SQL runs on both PostgreSQL and SQLite; the few
+// Postgres-only features branch on dialect.
 type Queries struct {
-	db *sqlx.DB
+	db      querier
+	conn    *sqlx.DB
+	tx      *sqlx.Tx // set when bound to a transaction by in
+	dialect string
+}
+
+// querier runs reads: the connection pool, or a transaction when a write
+// must see its own uncommitted rows.
+type querier interface {
+	Get(dest any, query string, args ...any) error
+	Select(dest any, query string, args ...any) error
 }
 
 // NewQueries creates a Queries instance with explicit DB dependency.
 func NewQueries(db *sqlx.DB) *Queries {
-	return &Queries{db: db}
+	return &Queries{db: db, conn: db, dialect: DialectOf(db)}
+}
+
+// in returns Queries that read, and write, inside tx.
+func (q *Queries) in(tx *sqlx.Tx) *Queries {
+	return &Queries{db: tx, conn: q.conn, tx: tx, dialect: q.dialect}
 }
 
 // --- Row types (what the DB returns) ---
 
 // QuoteRow is a single quote row from the database.
 type QuoteRow struct {
-	ID                    string         `db:"id" json:"id"`
-	Title                 sql.NullString `db:"title" json:"title,omitempty"`
-	Slug                  sql.NullString `db:"slug" json:"slug,omitempty"`
-	Text                  string         `db:"text" json:"text"`
-	TextScholarly         sql.NullString `db:"text_scholarly" json:"text_scholarly,omitempty"`
-	PhilosopherID         sql.NullString `db:"philosopher_id" json:"philosopher_id"`
-	TraditionID           sql.NullString `db:"tradition_id" json:"tradition_id"`
-	SourceWork            sql.NullString `db:"source_work" json:"source_work,omitempty"`
-	SourceLocation        sql.NullString `db:"source_location" json:"source_location,omitempty"`
-	OriginalScript        sql.NullString `db:"original_script" json:"original_script,omitempty"`
-	ExpositionBrief       sql.NullString `db:"exposition_brief" json:"exposition_brief,omitempty"`
-	ExpositionStandard    sql.NullString `db:"exposition_standard" json:"exposition_standard,omitempty"`
-	ExpositionScholarly   sql.NullString `db:"exposition_scholarly" json:"exposition_scholarly,omitempty"`
-	ReflectionPrompt      sql.NullString `db:"reflection_prompt" json:"reflection_prompt,omitempty"`
+	ID                     string         `db:"id" json:"id"`
+	Title                  sql.NullString `db:"title" json:"title,omitempty"`
+	Slug                   sql.NullString `db:"slug" json:"slug,omitempty"`
+	Text                   string         `db:"text" json:"text"`
+	TextScholarly          sql.NullString `db:"text_scholarly" json:"text_scholarly,omitempty"`
+	PhilosopherID          sql.NullString `db:"philosopher_id" json:"philosopher_id"`
+	TraditionID            sql.NullString `db:"tradition_id" json:"tradition_id"`
+	SourceWork             sql.NullString `db:"source_work" json:"source_work,omitempty"`
+	SourceLocation         sql.NullString `db:"source_location" json:"source_location,omitempty"`
+	OriginalScript         sql.NullString `db:"original_script" json:"original_script,omitempty"`
+	ExpositionBrief        sql.NullString `db:"exposition_brief" json:"exposition_brief,omitempty"`
+	ExpositionStandard     sql.NullString `db:"exposition_standard" json:"exposition_standard,omitempty"`
+	ExpositionScholarly    sql.NullString `db:"exposition_scholarly" json:"exposition_scholarly,omitempty"`
+	ReflectionPrompt       sql.NullString `db:"reflection_prompt" json:"reflection_prompt,omitempty"`
 	ModernReinterpretation sql.NullString `db:"modern_reinterpretation" json:"modern_reinterpretation,omitempty"`
-	Meta                  []byte         `db:"meta" json:"-"`
-	PhilosopherName       sql.NullString `db:"philosopher_name" json:"philosopher_name,omitempty"`
-	TraditionName         sql.NullString `db:"tradition_name" json:"tradition_name,omitempty"`
+	Meta                   []byte         `db:"meta" json:"-"`
+	Status                 string         `db:"status" json:"status"`
+	PublishAt              sql.NullTime   `db:"publish_at" json:"publish_at,omitempty"`
+	PhilosopherName        sql.NullString `db:"philosopher_name" json:"philosopher_name,omitempty"`
+	TraditionName          sql.NullString `db:"tradition_name" json:"tradition_name,omitempty"`
+	ThemeIDs               sql.NullString `db:"theme_ids" json:"-"`
+	EvidenceIDs            sql.NullString `db:"evidence_ids" json:"-"`
 }
 
 // GetTitle returns the title or a default.
@@ -84,6 +110,7 @@ type TraditionRow struct {
 	Name           string         `db:"name" json:"name"`
 	Origin         sql.NullString `db:"origin" json:"origin"`
 	CorePrinciples []byte         `db:"core_principles" json:"-"`
+	RelatedIDs     sql.NullString `db:"related_ids" json:"-"`
 }
 
 // Principles returns the parsed core_principles JSONB array.
@@ -95,9 +122,10 @@ func (t TraditionRow) Principles() []string {
 
 // ThemeRow is a single theme row.
 type ThemeRow struct {
-	ID          string         `db:"id" json:"id"`
-	Name        string         `db:"name" json:"name"`
-	Description sql.NullString `db:"description" json:"description"`
+	ID           string         `db:"id" json:"id"`
+	Name         string         `db:"name" json:"name"`
+	Description  sql.NullString `db:"description" json:"description"`
+	TraditionIDs sql.NullString `db:"tradition_ids" json:"-"`
 }
 
 // EvidenceRow is a single evidence row.
@@ -108,80 +136,126 @@ type EvidenceRow struct {
 	Field            sql.NullString `db:"field" json:"field"`
 	Citation         sql.NullString `db:"citation" json:"citation"`
 	EvidenceStrength sql.NullString `db:"evidence_strength" json:"evidence_strength"`
+	ThemeIDs         sql.NullString `db:"theme_ids" json:"-"`
 }
 
-// --- Queries ---
+// QuoteStampRow is when a quote was created, last published and last
+// updated, and when it is scheduled for.
+type QuoteStampRow struct {
+	ID          string       `db:"id"`
+	CreatedAt   time.Time    `db:"created_at"`
+	UpdatedAt   time.Time    `db:"updated_at"`
+	PublishedAt sql.NullTime `db:"published_at"`
+	PublishAt   sql.NullTime `db:"publish_at"`
+	Status      string       `db:"status"`
+}
+
+// --- Shared SELECT fragments ---
 
-// ListQuotes returns quotes with optional filters.
-func (q *Queries) ListQuotes(philosopher, tradition, theme string) ([]QuoteRow, error) {
-	query := `SELECT q.id, q.title, q.slug, q.text, q.text_scholarly,
+// quoteSelect is the SELECT ... FROM shared by every quote query: all
+// columns, joined display names, and comma-joined theme/evidence ids.
+func (q *Queries) quoteSelect() string {
+	return `SELECT q.id, q.title, q.slug, q.text, q.text_scholarly,
 		q.philosopher_id, q.tradition_id, q.source_work, q.source_location,
-		q.exposition_brief, q.meta,
-		ph.name AS philosopher_name, t.name AS tradition_name
+		q.original_script, q.exposition_brief, q.exposition_standard, q.exposition_scholarly,
+		q.reflection_prompt, q.modern_reinterpretation, q.meta, q.status, q.publish_at,
+		ph.name AS philosopher_name, t.name AS tradition_name,
+		(SELECT ` + groupConcat(q.dialect, "theme_id") + ` FROM quote_themes WHERE quote_id = q.id) AS theme_ids,
+		(SELECT ` + groupConcat(q.dialect, "evidence_id") + ` FROM quote_evidence WHERE quote_id = q.id) AS evidence_ids
 		FROM quotes q
 		LEFT JOIN philosophers ph ON q.philosopher_id = ph.id
-		LEFT JOIN traditions t ON q.tradition_id = t.id
-		WHERE 1=1`
-	args := []any{}
-	argNum := 1
-
-	if philosopher != "" {
-		query += " AND q.philosopher_id = $" + itoa(argNum)
-		args = append(args, philosopher)
-		argNum++
-	}
-	if tradition != "" {
-		query += " AND q.tradition_id = $" + itoa(argNum)
-		args = append(args, tradition)
-		argNum++
-	}
-	if theme != "" {
-		query += " AND q.id IN (SELECT quote_id FROM quote_themes WHERE theme_id = $" + itoa(argNum) + ")"
-		args = append(args, theme)
-		argNum++
-	}
+		LEFT JOIN traditions t ON q.tradition_id = t.id`
+}
+
+// philosopherSelect is the SELECT ... FROM shared by philosopher queries.
+const philosopherSelect = `SELECT p.id, p.name, p.tradition_id, p.era, p.bio, p.key_teachings,
+		t.name AS tradition_name
+		FROM philosophers p
+		LEFT JOIN traditions t ON p.tradition_id = t.id`
+
+func (q *Queries) traditionSelect() string {
+	return `SELECT tr.id, tr.name, tr.origin, tr.core_principles,
+		(SELECT ` + groupConcat(q.dialect, "related_id") + ` FROM tradition_relations WHERE tradition_id = tr.id) AS related_ids
+		FROM traditions tr`
+}
 
-	query += " ORDER BY q.created_at DESC"
+func (q *Queries) themeSelect() string {
+	return `SELECT th.id, th.name, th.description,
+		(SELECT ` + groupConcat(q.dialect, "tradition_id") + ` FROM theme_traditions WHERE theme_id = th.id) AS tradition_ids
+		FROM themes th`
+}
+
+func (q *Queries) evidenceSelect() string {
+	return `SELECT e.id, e.title, e.finding, e.field, e.citation, e.evidence_strength,
+		(SELECT ` + groupConcat(q.dialect, "theme_id") + ` FROM evidence_themes WHERE evidence_id = e.id) AS theme_ids
+		FROM evidence e`
+}
+
+// --- Queries ---
+
+// ListQuotes returns a window of quotes matching f (dimensions:
+// philosopher, philosophy/tradition, theme, evidence, status), ordered by
+// ID. See paginate for the window's shape. Without a status condition
+// only live quotes match.
+func (q *Queries) ListQuotes(f store.Filter, p store.Page) ([]QuoteRow, error) {
+	f = store.WithStatus(f)
+	var b builder
+	dims := maps.Clone(quoteDimensions)
+	dims["status"] = dimension{column: statusExpr(&b)}
+	applyFilter(&b, f, dims)
+	tail := paginate(&b, p, "q.id")
 
 	var rows []QuoteRow
-	err := q.db.Select(&rows, query, args...)
-	return rows, err
+	err := q.db.Select(&rows, q.quoteSelect()+b.sql()+tail, b.args...)
+	return ascending(rows, p), err
 }
 
-// GetQuote returns a single quote by ID or slug.
+// GetQuote returns a single quote by ID or slug. An ID wins over
+// another quote's slug, as in the in-memory store.
 func (q *Queries) GetQuote(idOrSlug string) (QuoteRow, error) {
 	var row QuoteRow
-	err := q.db.Get(&row, `SELECT q.id, q.title, q.slug, q.text, q.text_scholarly,
-		q.philosopher_id, q.tradition_id, q.source_work, q.source_location,
-		q.original_script, q.exposition_brief, q.exposition_standard, q.exposition_scholarly,
-		q.reflection_prompt, q.modern_reinterpretation, q.meta,
-		ph.name AS philosopher_name, t.name AS tradition_name
-		FROM quotes q
-		LEFT JOIN philosophers ph ON q.philosopher_id = ph.id
-		LEFT JOIN traditions t ON q.tradition_id = t.id
-		WHERE q.id = $1 OR q.slug = $1`, idOrSlug)
+	err := q.db.Get(&row, q.quoteSelect()+" WHERE q.id = $1 OR q.slug = $1 ORDER BY (q.id = $1) DESC LIMIT 1", idOrSlug)
 	return row, err
 }
 
-// RandomQuote returns a random quote.
+// RandomQuote returns a random live quote.
+// RANDOM() is spelled the same in PostgreSQL and SQLite.
 func (q *Queries) RandomQuote() (QuoteRow, error) {
+	var b builder
+	live(&b)
 	var row QuoteRow
-	err := q.db.Get(&row, `SELECT q.id, q.title, q.slug, q.text, q.text_scholarly,
-		q.philosopher_id, q.tradition_id, q.source_work, q.source_location,
-		q.exposition_brief, q.meta,
-		ph.name AS philosopher_name, t.name AS tradition_name
-		FROM quotes q
-		LEFT JOIN philosophers ph ON q.philosopher_id = ph.id
-		LEFT JOIN traditions t ON q.tradition_id = t.id
-		ORDER BY RANDOM() LIMIT 1`)
+	err := q.db.Get(&row, q.quoteSelect()+b.sql()+" ORDER BY RANDOM() LIMIT 1", b.args...)
 	return row, err
 }
 
+// QuoteStamps returns the dates of every quote, of any status.
+func (q *Queries) QuoteStamps() ([]QuoteStampRow, error) {
+	var rows []QuoteStampRow
+	err := q.db.Select(&rows, `SELECT id, created_at, updated_at, published_at, publish_at, status
+		FROM quotes ORDER BY id`)
+	return rows, err
+}
+
+// statusExpr is a quote's effective status (see store.EffectiveStatus):
+// a published quote whose publish_at is still ahead reads as scheduled.
+// The current time is bound as an argument, so both dialects compare
+// timestamps the same way.
+func statusExpr(b *builder) string {
+	now := b.arg(time.Now().UTC().Truncate(time.Second))
+	return "(CASE WHEN q.status = 'published' AND q.publish_at > " + now +
+		" THEN 'scheduled' ELSE q.status END)"
+}
+
+// live restricts a quote query to quotes the public may see.
+func live(b *builder) {
+	b.and(statusExpr(b) + " = 'published'")
+}
+
 // QuoteThemes returns theme names for a quote.
 func (q *Queries) QuoteThemes(quoteID string) ([]ThemeRow, error) {
 	var rows []ThemeRow
-	err := q.db.Select(&rows, `SELECT t.id, t.name, t.description FROM themes t
-		JOIN quote_themes qt ON t.id = qt.theme_id
+	err := q.db.Select(&rows, q.themeSelect()+`
+		JOIN quote_themes qt ON th.id = qt.theme_id
 		WHERE qt.quote_id = $1`, quoteID)
 	return rows, err
 }
@@ -189,164 +263,217 @@ func (q *Queries) QuoteThemes(quoteID string) ([]ThemeRow, error) {
 // QuoteEvidence returns evidence for a quote.
 func (q *Queries) QuoteEvidence(quoteID string) ([]EvidenceRow, error) {
 	var rows []EvidenceRow
-	err := q.db.Select(&rows, `SELECT e.id, e.title, e.finding, e.field, e.citation, e.evidence_strength 
-		FROM evidence e
+	err := q.db.Select(&rows, q.evidenceSelect()+`
 		JOIN quote_evidence qe ON e.id = qe.evidence_id
 		WHERE qe.quote_id = $1`, quoteID)
 	return rows, err
 }
 
-// ListPhilosophers returns philosophers, optionally filtered by tradition.
-func (q *Queries) ListPhilosophers(tradition string) ([]PhilosopherRow, error) {
-	query := `SELECT p.id, p.name, p.tradition_id, p.era, p.bio, p.key_teachings,
-		t.name AS tradition_name
-		FROM philosophers p
-		LEFT JOIN traditions t ON p.tradition_id = t.id
-		WHERE 1=1`
-	args := []any{}
-
-	if tradition != "" {
-		query += " AND p.tradition_id = $1"
-		args = append(args, tradition)
-	}
+// ListPhilosophers returns a window of philosophers matching f
+// (dimension: philosophy/tradition), ordered by ID.
+func (q *Queries) ListPhilosophers(f store.Filter, p store.Page) ([]PhilosopherRow, error) {
+	var b builder
+	applyFilter(&b, f, philosopherDimensions)
+	tail := paginate(&b, p, "p.id")
 
 	var rows []PhilosopherRow
-	err := q.db.Select(&rows, query, args...)
-	return rows, err
+	err := q.db.Select(&rows, philosopherSelect+b.sql()+tail, b.args...)
+	return ascending(rows, p), err
 }
 
 // GetPhilosopher returns a single philosopher by ID.
 func (q *Queries) GetPhilosopher(id string) (PhilosopherRow, error) {
 	var row PhilosopherRow
-	err := q.db.Get(&row, `SELECT p.id, p.name, p.tradition_id, p.era, p.bio, p.key_teachings,
-		t.name AS tradition_name
-		FROM philosophers p
-		LEFT JOIN traditions t ON p.tradition_id = t.id
-		WHERE p.id = $1`, id)
+	err := q.db.Get(&row, philosopherSelect+" WHERE p.id = $1", id)
 	return row, err
 }
 
-// PhilosopherQuotes returns all quotes by a philosopher.
+// PhilosopherQuotes returns all live quotes by a philosopher.
 func (q *Queries) PhilosopherQuotes(philosopherID string) ([]QuoteRow, error) {
-	var rows []QuoteRow
-	err := q.db.Select(&rows, `SELECT q.id, q.title, q.slug, q.text, q.text_scholarly,
-		q.philosopher_id, q.tradition_id, q.source_work, q.source_location,
-		q.exposition_brief, q.meta,
-		ph.name AS philosopher_name, t.name AS tradition_name
-		FROM quotes q
-		LEFT JOIN philosophers ph ON q.philosopher_id = ph.id
-		LEFT JOIN traditions t ON q.tradition_id = t.id
-		WHERE q.philosopher_id = $1`, philosopherID)
-	return rows, err
+	return q.ListQuotes(store.Where("philosopher", philosopherID), store.Page{})
 }
 
-// ListTraditions returns all tradition schools.
-func (q *Queries) ListTraditions() ([]TraditionRow, error) {
+// ListTraditions returns a window of tradition schools, ordered by name
+// and then ID.
+func (q *Queries) ListTraditions(p store.Page) ([]TraditionRow, error) {
+	var b builder
+	tail := paginate(&b, p, "tr.name", "tr.id")
+
 	var rows []TraditionRow
-	err := q.db.Select(&rows, "SELECT id, name, origin, core_principles FROM traditions ORDER BY name")
-	return rows, err
+	err := q.db.Select(&rows, q.traditionSelect()+b.sql()+tail, b.args...)
+	return ascending(rows, p), err
 }
 
 // GetTradition returns a single tradition by ID.
 func (q *Queries) GetTradition(id string) (TraditionRow, error) {
 	var row TraditionRow
-	err := q.db.Get(&row, "SELECT id, name, origin, core_principles FROM traditions WHERE id = $1", id)
+	err := q.db.Get(&row, q.traditionSelect()+" WHERE tr.id = $1", id)
 	return row, err
 }
 
 // TraditionPhilosophers returns philosophers of a school.
 func (q *Queries) TraditionPhilosophers(traditionID string) ([]PhilosopherRow, error) {
 	var rows []PhilosopherRow
-	err := q.db.Select(&rows, `SELECT p.id, p.name, p.tradition_id, p.era, p.bio, p.key_teachings,
-		t.name AS tradition_name
-		FROM philosophers p
-		LEFT JOIN traditions t ON p.tradition_id = t.id
-		WHERE p.tradition_id = $1`, traditionID)
+	err := q.db.Select(&rows, philosopherSelect+" WHERE p.tradition_id = $1", traditionID)
 	return rows, err
 }
 
-// TraditionQuotes returns quotes from a tradition.
+// TraditionQuotes returns live quotes from a tradition.
 func (q *Queries) TraditionQuotes(traditionID string) ([]QuoteRow, error) {
-	var rows []QuoteRow
-	err := q.db.Select(&rows, `SELECT q.id, q.title, q.slug, q.text, q.text_scholarly,
-		q.philosopher_id, q.tradition_id, q.source_work, q.source_location,
-		q.exposition_brief, q.meta,
-		ph.name AS philosopher_name, t.name AS tradition_name
-		FROM quotes q
-		LEFT JOIN philosophers ph ON q.philosopher_id = ph.id
-		LEFT JOIN traditions t ON q.tradition_id = t.id
-		WHERE q.tradition_id = $1`, traditionID)
-	return rows, err
+	return q.ListQuotes(store.Where("philosophy", traditionID), store.Page{})
 }
 
-// ListThemes returns all themes.
-func (q *Queries) ListThemes() ([]ThemeRow, error) {
+// ListThemes returns a window of themes, ordered by name and then ID.
+func (q *Queries) ListThemes(p store.Page) ([]ThemeRow, error) {
+	var b builder
+	tail := paginate(&b, p, "th.name", "th.id")
+
 	var rows []ThemeRow
-	err := q.db.Select(&rows, "SELECT id, name, description FROM themes ORDER BY name")
-	return rows, err
+	err := q.db.Select(&rows, q.themeSelect()+b.sql()+tail, b.args...)
+	return ascending(rows, p), err
 }
 
 // GetTheme returns a single theme by ID.
 func (q *Queries) GetTheme(id string) (ThemeRow, error) {
 	var row ThemeRow
-	err := q.db.Get(&row, "SELECT id, name, description FROM themes WHERE id = $1", id)
+	err := q.db.Get(&row, q.themeSelect()+" WHERE th.id = $1", id)
 	return row, err
 }
 
-// ThemeQuotes returns quotes that reference a theme.
+// ThemeQuotes returns live quotes that reference a theme.
 func (q *Queries) ThemeQuotes(themeID string) ([]QuoteRow, error) {
-	var rows []QuoteRow
-	err := q.db.Select(&rows, `SELECT q.id, q.title, q.slug, q.text, q.text_scholarly,
-		q.philosopher_id, q.tradition_id, q.source_work, q.source_location,
-		q.exposition_brief, q.meta,
-		ph.name AS philosopher_name, t.name AS tradition_name
-		FROM quotes q
-		LEFT JOIN philosophers ph ON q.philosopher_id = ph.id
-		LEFT JOIN traditions t ON q.tradition_id = t.id
-		JOIN quote_themes qt ON q.id = qt.quote_id
-		WHERE qt.theme_id = $1`, themeID)
-	return rows, err
+	return q.ListQuotes(store.Where("theme", themeID), store.Page{})
 }
 
-// ListEvidence returns all evidence, optionally filtered by field.
-func (q *Queries) ListEvidence(field string) ([]EvidenceRow, error) {
-	query := "SELECT id, title, finding, field, citation, evidence_strength FROM evidence WHERE 1=1"
-	args := []any{}
+// EvidenceQuotes returns live quotes that cite an evidence entry.
+func (q *Queries) EvidenceQuotes(evidenceID string) ([]QuoteRow, error) {
+	return q.ListQuotes(store.Where("evidence", evidenceID), store.Page{})
+}
 
-	if field != "" {
-		query += " AND field = $1"
-		args = append(args, field)
-	}
+// ListEvidence returns a window of evidence matching f (dimensions:
+// field, theme), ordered by ID.
+func (q *Queries) ListEvidence(f store.Filter, p store.Page) ([]EvidenceRow, error) {
+	var b builder
+	applyFilter(&b, f, evidenceDimensions)
+	tail := paginate(&b, p, "e.id")
 
 	var rows []EvidenceRow
-	err := q.db.Select(&rows, query, args...)
-	return rows, err
+	err := q.db.Select(&rows, q.evidenceSelect()+b.sql()+tail, b.args...)
+	return ascending(rows, p), err
 }
 
 // GetEvidence returns a single evidence entry by ID.
 func (q *Queries) GetEvidence(id string) (EvidenceRow, error) {
 	var row EvidenceRow
-	err := q.db.Get(&row, "SELECT id, title, finding, field, citation, evidence_strength FROM evidence WHERE id = $1", id)
+	err := q.db.Get(&row, q.evidenceSelect()+" WHERE e.id = $1", id)
 	return row, err
 }
 
-// SearchQuotes performs full-text search on quotes.
-func (q *Queries) SearchQuotes(query string) ([]QuoteRow, error) {
-	var rows []QuoteRow
-	err := q.db.Select(&rows, `SELECT q.id, q.title, q.slug, q.text, q.text_scholarly,
-		q.philosopher_id, q.tradition_id, q.source_work, q.source_location,
-		q.exposition_brief, q.meta,
-		ph.name AS philosopher_name, t.name AS tradition_name
-		FROM quotes q
-		LEFT JOIN philosophers ph ON q.philosopher_id = ph.id
-		LEFT JOIN traditions t ON q.tradition_id = t.id
-		WHERE to_tsvector('english', COALESCE(q.text, '') || ' ' || COALESCE(q.title, '') || ' ' || COALESCE(q.exposition_brief, ''))
-		@@ plainto_tsquery('english', $1)
-		LIMIT 50`, query)
+// SearchRow is a quote matched by a search, with its ts_rank and
+// ts_headline (PostgreSQL only; SQLite leaves both empty).
+type SearchRow struct {
+	QuoteRow
+	Rank    float64        `db:"rank"`
+	Snippet sql.NullString `db:"snippet"`
+}
+
+// FacetRow counts search matches carrying one tradition or theme. The
+// row with dimension "total" counts every match.
+type FacetRow struct {
+	Dimension string `db:"dimension"`
+	ID        string `db:"id"`
+	Name      string `db:"name"`
+	Count     int    `db:"n"`
+}
+
+// headlineOptions make ts_headline delimit matches with the store's
+// markers, so the snippet can be escaped before it becomes HTML.
+const headlineOptions = "StartSel=" + store.MarkStart + ", StopSel=" + store.MarkEnd +
+	`, MaxFragments=2, MinWords=8, MaxWords=30, FragmentDelimiter=" … "`
+
+// SearchQuotes runs a full-text search over live quotes' title, text,
+// expositions and modern reinterpretation.
+//
+// PostgreSQL matches the weighted search_vector with
+// websearch_to_tsquery and returns one page, best ts_rank first, with
+// a ts_headline snippet. SQLite has no ranking: it returns every quote
+// containing each search term as a case-insensitive substring. (The
+// Repository searches SQLite through a store.Index instead.)
+func (q *Queries) SearchQuotes(s store.SearchQuery) ([]SearchRow, error) {
+	if len(store.SearchTerms(s.Text)) == 0 {
+		return nil, nil
+	}
+	var b builder
+	match := q.searchMatch(&b, s)
+
+	var rows []SearchRow
+	if q.dialect == SQLite {
+		err := q.db.Select(&rows, q.quoteSelect()+b.sql()+" ORDER BY q.id", b.args...)
+		return rows, err
+	}
+
+	// The snippet is of the text as written, accents and all, so it
+	// highlights words matching the query either as typed or folded.
+	highlight := match + " || websearch_to_tsquery('english', " + b.arg(s.Text) + ")"
+	limit, offset := s.Window()
+	query := "SELECT ts_rank(q.search_vector, " + match + ") AS rank, " +
+		"ts_headline('english', concat_ws(' … ', q.text, q.exposition_brief, q.exposition_standard, q.modern_reinterpretation), " +
+		highlight + ", " + b.arg(headlineOptions) + ") AS snippet, " +
+		strings.TrimPrefix(q.quoteSelect(), "SELECT ") + b.sql() +
+		" ORDER BY rank DESC, q.id LIMIT " + b.arg(limit) + " OFFSET " + b.arg(offset)
+	err := q.db.Select(&rows, query, b.args...)
 	return rows, err
 }
 
-// Helper to convert int to string for query building
-func itoa(n int) string {
-	return string(rune('0' + n))
+// SearchFacets counts every quote SearchQuotes would match, by
+// tradition and by theme, plus a "total" row.
+func (q *Queries) SearchFacets(s store.SearchQuery) ([]FacetRow, error) {
+	if len(store.SearchTerms(s.Text)) == 0 {
+		return nil, nil
+	}
+	var b builder
+	q.searchMatch(&b, s)
+	where := b.sql()
+
+	var rows []FacetRow
+	err := q.db.Select(&rows, `SELECT 'total' AS dimension, '' AS id, '' AS name, COUNT(*) AS n
+		FROM quotes q`+where+`
+		UNION ALL
+		SELECT 'tradition', t.id, t.name, COUNT(*)
+		FROM quotes q JOIN traditions t ON t.id = q.tradition_id`+where+`
+		GROUP BY t.id, t.name
+		UNION ALL
+		SELECT 'theme', th.id, th.name, COUNT(*)
+		FROM quotes q JOIN quote_themes qt ON qt.quote_id = q.id JOIN themes th ON th.id = qt.theme_id`+where+`
+		GROUP BY th.id, th.name`, b.args...)
+	return rows, err
+}
+
+// searchMatch adds the conditions every search query shares: the text
+// match, live quotes only, and the filter. On PostgreSQL it returns the
+// tsquery expression, for ranking and highlighting.
+func (q *Queries) searchMatch(b *builder, s store.SearchQuery) string {
+	var match string
+	if q.dialect == SQLite {
+		for _, term := range store.SearchTerms(s.Text) {
+			b.and(`(COALESCE(q.title, '') || ' ' || COALESCE(q.text, '') || ' ' || COALESCE(q.exposition_brief, '') || ' ' ||
+				COALESCE(q.exposition_standard, '') || ' ' || COALESCE(q.modern_reinterpretation, ''))
+				LIKE ` + b.arg("%"+escapeLike(term)+"%") + ` ESCAPE '\'`)
+		}
+	} else {
+		// The search vector has its diacritics folded out (migration 0006),
+		// and so does the query, by the same function, so "Śaṅkara" and
+		// "Sankara" find the same quotes.
+		match = "websearch_to_tsquery('english', search_unaccent(" + b.arg(s.Text) + "))"
+		b.and("q.search_vector @@ " + match)
+	}
+	live(b)
+	applyFilter(b, s.Filter, quoteDimensions)
+	return match
+}
+
+// escapeLike escapes LIKE wildcards so user input matches literally.
+func escapeLike(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+	return r.Replace(s)
 }
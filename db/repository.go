@@ -0,0 +1,429 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"perennial-wisdom/models"
+	"perennial-wisdom/store"
+)
+
+// Repository is the SQL implementation of store.Repository.
+// It runs the explicit queries in Queries and maps rows onto the
+// shared models, so the API and pages see one corpus shape.
+type Repository struct {
+	q      *Queries
+	search *searchIndex // SQLite only: PostgreSQL searches in SQL
+	names  *nameCache
+	// pending collects the writes of a Batch, for the search index.
+	pending *[]written
+}
+
+var _ store.Repository = (*Repository)(nil)
+
+// NewRepository creates a Repository over a Queries instance.
+func NewRepository(q *Queries) *Repository {
+	r := &Repository{q: q, names: &nameCache{}}
+	if q.dialect == SQLite {
+		r.search = &searchIndex{}
+	}
+	return r
+}
+
+// ListQuotes returns one page of quotes matching f.
+func (r *Repository) ListQuotes(f store.Filter, p store.Page) ([]models.Quote, store.Cursors, error) {
+	rows, err := r.q.ListQuotes(f, p)
+	if err != nil {
+		return nil, store.Cursors{}, err
+	}
+	rows, c := store.Trim(p, rows, func(row QuoteRow) string { return row.ID })
+	quotes := make([]models.Quote, len(rows))
+	for i, row := range rows {
+		quotes[i] = quoteModel(row)
+	}
+	return quotes, c, nil
+}
+
+// GetQuote returns a quote by ID or slug.
+func (r *Repository) GetQuote(idOrSlug string) (models.Quote, error) {
+	row, err := r.q.GetQuote(idOrSlug)
+	if err != nil {
+		return models.Quote{}, notFound(err)
+	}
+	return quoteModel(row), nil
+}
+
+// RandomQuote returns a random quote.
+func (r *Repository) RandomQuote() (models.Quote, error) {
+	row, err := r.q.RandomQuote()
+	if err != nil {
+		return models.Quote{}, notFound(err)
+	}
+	return quoteModel(row), nil
+}
+
+// QuoteStamps says when each quote went public and last changed. Quotes
+// published before publication was stamped count from their creation.
+func (r *Repository) QuoteStamps() (map[string]store.Stamp, error) {
+	rows, err := r.q.QuoteStamps()
+	if err != nil {
+		return nil, err
+	}
+	stamps := make(map[string]store.Stamp, len(rows))
+	for _, row := range rows {
+		st := store.Stamp{Updated: row.UpdatedAt.UTC()}
+		switch {
+		case row.PublishedAt.Valid:
+			st.Published = row.PublishedAt.Time.UTC()
+		case row.Status == store.StatusPublished:
+			st.Published = row.CreatedAt.UTC()
+		}
+		if row.PublishAt.Valid && row.PublishAt.Time.After(st.Published) {
+			st.Published = row.PublishAt.Time.UTC()
+		}
+		stamps[row.ID] = st
+	}
+	return stamps, nil
+}
+
+// ListPhilosophers returns one page of philosophers matching f.
+func (r *Repository) ListPhilosophers(f store.Filter, p store.Page) ([]models.Philosopher, store.Cursors, error) {
+	rows, err := r.q.ListPhilosophers(f, p)
+	if err != nil {
+		return nil, store.Cursors{}, err
+	}
+	rows, c := store.Trim(p, rows, func(row PhilosopherRow) string { return row.ID })
+	philosophers := make([]models.Philosopher, len(rows))
+	for i, row := range rows {
+		philosophers[i] = philosopherModel(row)
+	}
+	return philosophers, c, nil
+}
+
+// GetPhilosopher returns a philosopher by ID.
+func (r *Repository) GetPhilosopher(id string) (models.Philosopher, error) {
+	row, err := r.q.GetPhilosopher(id)
+	if err != nil {
+		return models.Philosopher{}, notFound(err)
+	}
+	return philosopherModel(row), nil
+}
+
+// ListPhilosophies returns one page of schools, ordered by name.
+func (r *Repository) ListPhilosophies(p store.Page) ([]models.Philosophy, store.Cursors, error) {
+	rows, err := r.q.ListTraditions(p)
+	if err != nil {
+		return nil, store.Cursors{}, err
+	}
+	rows, c := store.Trim(p, rows, func(row TraditionRow) string { return store.NameKey(row.Name, row.ID) })
+	philosophies := make([]models.Philosophy, len(rows))
+	for i, row := range rows {
+		philosophies[i] = philosophyModel(row)
+	}
+	return philosophies, c, nil
+}
+
+// GetPhilosophy returns a school by ID.
+func (r *Repository) GetPhilosophy(id string) (models.Philosophy, error) {
+	row, err := r.q.GetTradition(id)
+	if err != nil {
+		return models.Philosophy{}, notFound(err)
+	}
+	return philosophyModel(row), nil
+}
+
+// ListThemes returns one page of themes, ordered by name.
+func (r *Repository) ListThemes(p store.Page) ([]models.Theme, store.Cursors, error) {
+	rows, err := r.q.ListThemes(p)
+	if err != nil {
+		return nil, store.Cursors{}, err
+	}
+	rows, c := store.Trim(p, rows, func(row ThemeRow) string { return store.NameKey(row.Name, row.ID) })
+	themes := make([]models.Theme, len(rows))
+	for i, row := range rows {
+		themes[i] = themeModel(row)
+	}
+	return themes, c, nil
+}
+
+// GetTheme returns a theme by ID.
+func (r *Repository) GetTheme(id string) (models.Theme, error) {
+	row, err := r.q.GetTheme(id)
+	if err != nil {
+		return models.Theme{}, notFound(err)
+	}
+	return themeModel(row), nil
+}
+
+// ListEvidence returns one page of evidence matching f.
+func (r *Repository) ListEvidence(f store.Filter, p store.Page) ([]models.Evidence, store.Cursors, error) {
+	rows, err := r.q.ListEvidence(f, p)
+	if err != nil {
+		return nil, store.Cursors{}, err
+	}
+	rows, c := store.Trim(p, rows, func(row EvidenceRow) string { return row.ID })
+	evidence := make([]models.Evidence, len(rows))
+	for i, row := range rows {
+		evidence[i] = evidenceModel(row)
+	}
+	return evidence, c, nil
+}
+
+// GetEvidence returns an evidence entry by ID.
+func (r *Repository) GetEvidence(id string) (models.Evidence, error) {
+	row, err := r.q.GetEvidence(id)
+	if err != nil {
+		return models.Evidence{}, notFound(err)
+	}
+	return evidenceModel(row), nil
+}
+
+// SearchQuotes returns one page of ranked matches and the facet counts
+// over all of them, plus the names the text matches and, failing any
+// match, a suggestion. PostgreSQL ranks in SQL; SQLite answers from an
+// in-process store.Index.
+//
+// Both ignore diacritics. Only the index forgives typos in quote text,
+// though: PostgreSQL matches whole stems, and a misspelt search there
+// finds nothing but the names it roughly matches and a suggestion.
+func (r *Repository) SearchQuotes(s store.SearchQuery) (store.SearchResults, error) {
+	names, err := r.searchNames()
+	if err != nil {
+		return store.SearchResults{}, err
+	}
+	byKind := map[string]map[string]string{}
+	for _, n := range names {
+		if byKind[n.Kind] == nil {
+			byKind[n.Kind] = map[string]string{}
+		}
+		byKind[n.Kind][n.ID] = n.Name
+	}
+
+	var res store.SearchResults
+	if r.search != nil {
+		res, err = r.searchIndexed(s, func(kind, id string) string { return byKind[kind][id] })
+	} else {
+		res, err = r.searchRanked(s)
+	}
+	if err != nil {
+		return res, err
+	}
+	err = store.Fuzzy(&res, s.Text, names, r.vocabulary)
+	return res, err
+}
+
+// nameCache keeps the names searchNames lists between writes: every
+// search reads them, and they change far less often.
+type nameCache struct {
+	mu    sync.Mutex
+	names []store.NameMatch
+	built bool
+}
+
+// forget drops the cached names, to be listed again on the next search.
+func (c *nameCache) forget() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.names, c.built = nil, false
+}
+
+// forgetNames drops the cached names after a committed write that may
+// have renamed something. Quotes have no names to match.
+func (r *Repository) forgetNames(kind string) {
+	if kind != "quote" {
+		r.names.forget()
+	}
+}
+
+// searchNames lists the philosophers, traditions and themes a search
+// can match by name, from the cache if no write has dropped it.
+func (r *Repository) searchNames() ([]store.NameMatch, error) {
+	r.names.mu.Lock()
+	defer r.names.mu.Unlock()
+	if !r.names.built {
+		names, err := r.listNames()
+		if err != nil {
+			return nil, err
+		}
+		r.names.names, r.names.built = names, true
+	}
+	return r.names.names, nil
+}
+
+// listNames reads the names searchNames caches.
+func (r *Repository) listNames() ([]store.NameMatch, error) {
+	var names []store.NameMatch
+	philosophers, _, err := r.ListPhilosophers(nil, store.Page{})
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range philosophers {
+		names = append(names, store.NameMatch{Kind: "philosopher", ID: p.ID, Name: p.Name})
+	}
+	traditions, _, err := r.ListPhilosophies(store.Page{})
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range traditions {
+		names = append(names, store.NameMatch{Kind: "philosophy", ID: p.ID, Name: p.Name})
+	}
+	themes, _, err := r.ListThemes(store.Page{})
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range themes {
+		names = append(names, store.NameMatch{Kind: "theme", ID: t.ID, Name: t.Name})
+	}
+	return names, nil
+}
+
+// vocabulary counts the words of live quotes, for suggestions. Only
+// searches that match nothing need it, so PostgreSQL reads it then.
+func (r *Repository) vocabulary() (map[string]int, error) {
+	if r.search != nil {
+		x, err := r.searchIndex()
+		if err != nil {
+			return nil, err
+		}
+		return x.Vocabulary(), nil
+	}
+	quotes, _, err := r.ListQuotes(nil, store.Page{})
+	if err != nil {
+		return nil, err
+	}
+	return store.Vocabulary(quotes), nil
+}
+
+// searchRanked runs a search in SQL, on PostgreSQL.
+func (r *Repository) searchRanked(s store.SearchQuery) (store.SearchResults, error) {
+	res := store.SearchResults{Hits: []store.SearchHit{}, Facets: map[string][]store.Facet{"tradition": {}, "theme": {}}}
+	rows, err := r.q.SearchQuotes(s)
+	if err != nil {
+		return res, err
+	}
+	facets, err := r.q.SearchFacets(s)
+	if err != nil {
+		return res, err
+	}
+	for _, f := range facets {
+		if f.Dimension == "total" {
+			res.Total = f.Count
+			continue
+		}
+		res.Facets[f.Dimension] = append(res.Facets[f.Dimension], store.Facet{ID: f.ID, Name: f.Name, Count: f.Count})
+	}
+	for _, list := range res.Facets {
+		store.SortFacets(list)
+	}
+
+	for _, row := range rows {
+		res.Hits = append(res.Hits, store.SearchHit{
+			Quote:   quoteModel(row.QuoteRow),
+			Rank:    row.Rank,
+			Snippet: store.SnippetHTML(row.Snippet.String),
+		})
+	}
+	return res, nil
+}
+
+// --- Row → model mapping ---
+
+func quoteModel(r QuoteRow) models.Quote {
+	return models.Quote{
+		ID:                     r.ID,
+		Title:                  r.Title.String,
+		Slug:                   r.Slug.String,
+		Text:                   r.Text,
+		TextScholarly:          r.TextScholarly.String,
+		PhilosopherID:          r.PhilosopherID.String,
+		PhilosophyID:           r.TraditionID.String,
+		Source:                 r.SourceWork.String,
+		SourceLocation:         r.SourceLocation.String,
+		OriginalScript:         r.OriginalScript.String,
+		ExpositionBrief:        r.ExpositionBrief.String,
+		ExpositionStandard:     r.ExpositionStandard.String,
+		ExpositionScholarly:    r.ExpositionScholarly.String,
+		ReflectionPrompt:       r.ReflectionPrompt.String,
+		ModernReinterpretation: r.ModernReinterpretation.String,
+		Meta:                   r.GetMeta(),
+		Status:                 r.Status,
+		PublishAt:              nullTime(r.PublishAt),
+		ThemeIDs:               splitIDs(r.ThemeIDs),
+		EvidenceIDs:            splitIDs(r.EvidenceIDs),
+	}
+}
+
+func nullTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	utc := t.Time.UTC()
+	return &utc
+}
+
+func philosopherModel(r PhilosopherRow) models.Philosopher {
+	return models.Philosopher{
+		ID:           r.ID,
+		Name:         r.Name,
+		PhilosophyID: r.TraditionID.String,
+		Era:          r.Era.String,
+		Bio:          r.Bio.String,
+		KeyTeachings: r.Teachings(),
+	}
+}
+
+func philosophyModel(r TraditionRow) models.Philosophy {
+	return models.Philosophy{
+		ID:             r.ID,
+		Name:           r.Name,
+		Origin:         r.Origin.String,
+		CorePrinciples: r.Principles(),
+		RelatedIDs:     splitIDs(r.RelatedIDs),
+	}
+}
+
+func themeModel(r ThemeRow) models.Theme {
+	return models.Theme{
+		ID:            r.ID,
+		Name:          r.Name,
+		Description:   r.Description.String,
+		PhilosophyIDs: splitIDs(r.TraditionIDs),
+	}
+}
+
+func evidenceModel(r EvidenceRow) models.Evidence {
+	return models.Evidence{
+		ID:       r.ID,
+		Title:    r.Title,
+		Finding:  r.Finding.String,
+		Field:    r.Field.String,
+		Source:   r.Citation.String,
+		Strength: r.EvidenceStrength.String,
+		ThemeIDs: splitIDs(r.ThemeIDs),
+	}
+}
+
+// splitIDs turns an aggregated "a,b,c" column into a sorted slice.
+func splitIDs(s sql.NullString) []string {
+	if !s.Valid || s.String == "" {
+		return nil
+	}
+	ids := strings.Split(s.String, ",")
+	sort.Strings(ids)
+	return ids
+}
+
+// notFound maps sql.ErrNoRows onto the repository-wide store.ErrNotFound.
+func notFound(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return store.ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,190 @@
+package db_test
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"sort"
+	"testing"
+
+	"perennial-wisdom/db"
+	"perennial-wisdom/models"
+	"perennial-wisdom/store"
+)
+
+// The SQL and in-memory repositories must serve the same corpus.
+func TestRepositoryMatchesMemoryStore(t *testing.T) {
+	sqlRepo := db.NewRepository(db.NewQueries(seededDB(t)))
+	memRepo := store.New()
+
+	for _, f := range []store.Filter{
+		nil,
+		store.Where("philosopher", "epictetus"),
+		{"philosophy": {Values: []string{"buddhist"}}, "theme": {Values: []string{"impermanence"}}},
+		store.Where("evidence", "cognitive-reappraisal"),
+		store.Where("theme", "control", "death", "impermanence", "detachment", "self-inquiry", "virtue", "simplicity", "suffering", "present-moment", "ego-dissolution"),
+		{"theme": {Values: []string{"control", "suffering"}, All: true}},
+		{"tradition": {Values: []string{"stoic", "buddhist"}}, "theme": {Exclude: []string{"death"}}},
+		{"philosophy": {Exclude: []string{"stoic"}}, "evidence": {Values: []string{"hedonic-treadmill"}}},
+		{"philosopher": {Values: []string{"epictetus", "seneca"}, All: true}},
+	} {
+		got, _, err := sqlRepo.ListQuotes(f, store.Page{})
+		if err != nil {
+			t.Fatalf("sql ListQuotes(%v): %v", f, err)
+		}
+		want, _, _ := memRepo.ListQuotes(f, store.Page{})
+		if g, w := quoteIDs(got), quoteIDs(want); !reflect.DeepEqual(g, w) {
+			t.Errorf("ListQuotes(%v):\nsql    %v\nmemory %v", f, g, w)
+		}
+	}
+
+	for _, f := range []store.Filter{
+		store.Where("philosophy", "stoic"),
+		{"tradition": {Values: []string{"stoic", "buddhist"}}},
+		{"philosophy": {Exclude: []string{"stoic"}}},
+	} {
+		got, _, err := sqlRepo.ListPhilosophers(f, store.Page{})
+		if err != nil {
+			t.Fatalf("sql ListPhilosophers(%v): %v", f, err)
+		}
+		want, _, _ := memRepo.ListPhilosophers(f, store.Page{})
+		if len(got) != len(want) {
+			t.Errorf("ListPhilosophers(%v): sql %d, memory %d", f, len(got), len(want))
+		}
+	}
+
+	for _, f := range []store.Filter{
+		nil,
+		store.Where("field", "neuroscience", "psychology"),
+		{"theme": {Values: []string{"control"}}, "field": {Exclude: []string{"psychology"}}},
+	} {
+		got, _, err := sqlRepo.ListEvidence(f, store.Page{})
+		if err != nil {
+			t.Fatalf("sql ListEvidence(%v): %v", f, err)
+		}
+		want, _, _ := memRepo.ListEvidence(f, store.Page{})
+		if len(got) != len(want) {
+			t.Errorf("ListEvidence(%v): sql %d, memory %d", f, len(got), len(want))
+		}
+	}
+
+	got, err := sqlRepo.GetPhilosophy("stoic")
+	if err != nil {
+		t.Fatalf("GetPhilosophy: %v", err)
+	}
+	want, _ := memRepo.GetPhilosophy("stoic")
+	if got.Name != want.Name || !reflect.DeepEqual(got.CorePrinciples, want.CorePrinciples) || len(got.RelatedIDs) != len(want.RelatedIDs) {
+		t.Errorf("GetPhilosophy(stoic) differs:\nsql    %+v\nmemory %+v", got, want)
+	}
+
+	theme, _ := sqlRepo.GetTheme("control")
+	wantTheme, _ := memRepo.GetTheme("control")
+	if len(theme.PhilosophyIDs) != len(wantTheme.PhilosophyIDs) {
+		t.Errorf("GetTheme(control) philosophy ids: sql %v, memory %v", theme.PhilosophyIDs, wantTheme.PhilosophyIDs)
+	}
+
+	e, _ := sqlRepo.GetEvidence("death-awareness")
+	wantE, _ := memRepo.GetEvidence("death-awareness")
+	if e.Source != wantE.Source || len(e.ThemeIDs) != len(wantE.ThemeIDs) {
+		t.Errorf("GetEvidence differs:\nsql    %+v\nmemory %+v", e, wantE)
+	}
+}
+
+// Paging through the SQL repository must visit the same quotes, in the
+// same order, as paging through the in-memory store.
+func TestRepositoryPaginationMatchesMemoryStore(t *testing.T) {
+	sqlRepo := db.NewRepository(db.NewQueries(seededDB(t)))
+	memRepo := store.New()
+
+	walk := func(repo store.Repository) (ids []string, pages int) {
+		p := store.Page{Limit: 7}
+		for {
+			quotes, cur, err := repo.ListQuotes(store.Where("philosophy", "stoic", "buddhist"), p)
+			if err != nil {
+				t.Fatalf("ListQuotes: %v", err)
+			}
+			for _, q := range quotes {
+				ids = append(ids, q.ID)
+			}
+			pages++
+			if cur.Next == "" {
+				return ids, pages
+			}
+			p, err = store.ParsePage(url.Values{"cursor": {cur.Next}, "limit": {"7"}})
+			if err != nil {
+				t.Fatalf("ParsePage: %v", err)
+			}
+		}
+	}
+
+	got, gotPages := walk(sqlRepo)
+	want, wantPages := walk(memRepo)
+	if !reflect.DeepEqual(got, want) || gotPages != wantPages {
+		t.Errorf("sql walk (%d pages) %v\nmemory walk (%d pages) %v", gotPages, got, wantPages, want)
+	}
+	if gotPages < 2 {
+		t.Errorf("expected several pages, got %d", gotPages)
+	}
+
+	// Themes list by name, the same in both.
+	sqlThemes, _, _ := sqlRepo.ListThemes(store.Page{})
+	memThemes, _, _ := memRepo.ListThemes(store.Page{})
+	themeIDs := func(themes []models.Theme) (ids []string) {
+		for _, th := range themes {
+			ids = append(ids, th.ID)
+		}
+		return ids
+	}
+	if g, w := themeIDs(sqlThemes), themeIDs(memThemes); len(g) == 0 || !reflect.DeepEqual(g, w) {
+		t.Errorf("theme order differs:\nsql    %v\nmemory %v", g, w)
+	}
+
+	// A backward page from the end returns the same tail in both.
+	p := store.Page{Limit: 3, Before: "zzz"}
+	sqlTail, sqlCur, _ := sqlRepo.ListThemes(p)
+	memTail, memCur, _ := memRepo.ListThemes(p)
+	if len(sqlTail) != 3 || sqlTail[0].ID != memTail[0].ID || sqlTail[2].ID != memTail[2].ID || sqlCur != memCur {
+		t.Errorf("backward page differs:\nsql    %v %+v\nmemory %v %+v", sqlTail, sqlCur, memTail, memCur)
+	}
+}
+
+func TestRepositoryNotFound(t *testing.T) {
+	repo := db.NewRepository(db.NewQueries(seededDB(t)))
+
+	if _, err := repo.GetQuote("nonexistent"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("GetQuote: expected ErrNotFound, got %v", err)
+	}
+	if _, err := repo.GetTheme("nonexistent"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("GetTheme: expected ErrNotFound, got %v", err)
+	}
+}
+
+// A quote's slug may be another quote's ID; the ID wins, in both stores.
+func TestGetQuotePrefersIDOverSlug(t *testing.T) {
+	ch := store.Change{Author: "ana"}
+	for name, repo := range map[string]store.ReadWriter{
+		"sql":    db.NewRepository(db.NewQueries(seededDB(t))),
+		"memory": store.New(),
+	} {
+		for _, q := range []models.Quote{
+			{ID: "clash-1", Slug: "clash-2", Text: "Named like the next.", PhilosopherID: "epictetus", PhilosophyID: "stoic"},
+			{ID: "clash-2", Text: "Named first by another.", PhilosopherID: "epictetus", PhilosophyID: "stoic"},
+		} {
+			if err := repo.CreateQuote(q, ch); err != nil {
+				t.Fatalf("%s CreateQuote(%s): %v", name, q.ID, err)
+			}
+		}
+		if q, err := repo.GetQuote("clash-2"); err != nil || q.ID != "clash-2" {
+			t.Errorf("%s GetQuote(clash-2): got %q, %v", name, q.ID, err)
+		}
+	}
+}
+
+func quoteIDs(quotes []models.Quote) []string {
+	ids := make([]string, len(quotes))
+	for i, q := range quotes {
+		ids[i] = q.ID
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,164 @@
+package db
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"perennial-wisdom/models"
+	"perennial-wisdom/store"
+)
+
+// RevisionRow is a single row of the revisions table.
+type RevisionRow struct {
+	Kind      string    `db:"kind"`
+	EntityID  string    `db:"entity_id"`
+	Number    int       `db:"number"`
+	Action    string    `db:"action"`
+	Author    string    `db:"author"`
+	Note      string    `db:"note"`
+	CreatedAt time.Time `db:"created_at"`
+	Data      []byte    `db:"data"`
+}
+
+const revisionSelect = `SELECT kind, entity_id, number, action, author, note, created_at, data FROM revisions`
+
+// ListRevisions returns an entity's revisions, newest first.
+func (q *Queries) ListRevisions(kind, id string) ([]RevisionRow, error) {
+	var rows []RevisionRow
+	err := q.db.Select(&rows, revisionSelect+" WHERE kind = $1 AND entity_id = $2 ORDER BY number DESC", kind, id)
+	return rows, err
+}
+
+// GetRevision returns one revision of an entity.
+func (q *Queries) GetRevision(kind, id string, number int) (RevisionRow, error) {
+	var row RevisionRow
+	err := q.db.Get(&row, revisionSelect+" WHERE kind = $1 AND entity_id = $2 AND number = $3", kind, id, number)
+	return row, err
+}
+
+// ListRevisions returns an entity's revisions, newest first.
+func (r *Repository) ListRevisions(kind, id string) ([]models.Revision, error) {
+	rows, err := r.q.ListRevisions(kind, id)
+	if err != nil {
+		return nil, err
+	}
+	revs := make([]models.Revision, len(rows))
+	for i, row := range rows {
+		revs[i] = revisionModel(row)
+	}
+	return revs, nil
+}
+
+// GetRevision returns one revision.
+func (r *Repository) GetRevision(kind, id string, number int) (models.Revision, error) {
+	row, err := r.q.GetRevision(kind, id, number)
+	if err != nil {
+		return models.Revision{}, notFound(err)
+	}
+	return revisionModel(row), nil
+}
+
+func revisionModel(row RevisionRow) models.Revision {
+	return models.Revision{
+		Kind: row.Kind, EntityID: row.EntityID, Number: row.Number, Action: row.Action,
+		Author: row.Author, Note: row.Note, CreatedAt: row.CreatedAt.UTC(), Data: json.RawMessage(row.Data),
+	}
+}
+
+// revised runs write in a transaction and records its revision in the
+// same transaction, so an entity and its history never disagree. An
+// entity without history first gets a baseline revision of its prior
+// state.
+func (r *Repository) revised(kind, id, action string, ch store.Change, write func(tx *sqlx.Tx) error) error {
+	err := r.q.inTx(func(tx *sqlx.Tx) error {
+		in := &Repository{q: r.q.in(tx)}
+		var base any
+		if action != "create" {
+			var n int
+			if err := tx.Get(&n, "SELECT COUNT(*) FROM revisions WHERE kind = $1 AND entity_id = $2", kind, id); err != nil {
+				return err
+			}
+			if n == 0 {
+				// Seeded, or written before history was kept.
+				var err error
+				if base, err = in.snapshot(kind, id); err != nil {
+					return err
+				}
+			}
+		}
+		if err := write(tx); err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		if base != nil {
+			if err := record(tx, kind, id, "baseline", store.Baseline, base, now); err != nil {
+				return err
+			}
+		}
+		after, err := in.snapshot(kind, id)
+		if err != nil {
+			return err
+		}
+		return record(tx, kind, id, action, ch, after, now)
+	})
+	if err != nil {
+		return err
+	}
+	r.forgetNames(kind)
+	r.reindex(kind, id, action)
+	return nil
+}
+
+// snapshot reads an entity as the API would return it, or nil if it
+// doesn't exist.
+func (r *Repository) snapshot(kind, id string) (any, error) {
+	var v any
+	var got string
+	var err error
+	switch kind {
+	case "quote":
+		var q models.Quote
+		q, err = r.GetQuote(id)
+		v, got = q, q.ID // GetQuote also matches slugs
+	case "philosopher":
+		v, err = r.GetPhilosopher(id)
+		got = id
+	case "philosophy":
+		v, err = r.GetPhilosophy(id)
+		got = id
+	case "theme":
+		v, err = r.GetTheme(id)
+		got = id
+	case "evidence":
+		v, err = r.GetEvidence(id)
+		got = id
+	}
+	if errors.Is(err, store.ErrNotFound) || got != id {
+		return nil, nil
+	}
+	return v, err
+}
+
+// record appends the next revision of kind/id.
+func record(tx *sqlx.Tx, kind, id, action string, ch store.Change, v any, at time.Time) error {
+	var data any
+	if v != nil {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		data = string(b)
+	}
+	var n int
+	if err := tx.Get(&n, "SELECT COALESCE(MAX(number), 0) FROM revisions WHERE kind = $1 AND entity_id = $2", kind, id); err != nil {
+		return err
+	}
+	_, err := tx.Exec(`INSERT INTO revisions (kind, entity_id, number, action, author, note, created_at, data)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		kind, id, n+1, action, ch.Author, ch.Note, at, data)
+	return conflict(err, kind+" revision", id)
+}
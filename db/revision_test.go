@@ -0,0 +1,140 @@
+package db_test
+
+import (
+	"testing"
+
+	"perennial-wisdom/db"
+	"perennial-wisdom/models"
+	"perennial-wisdom/store"
+)
+
+// editHistory makes the same edits through any ReadWriter and returns
+// the resulting history of quote e1.
+func editHistory(t *testing.T, rw store.ReadWriter) []models.Revision {
+	t.Helper()
+	q, err := rw.GetQuote("e1")
+	if err != nil {
+		t.Fatalf("GetQuote: %v", err)
+	}
+	q.Source = "Enchiridion 5"
+	if err := rw.UpdateQuote(q, store.Change{Author: "ana", Note: "fix source"}); err != nil {
+		t.Fatalf("UpdateQuote: %v", err)
+	}
+	q.ThemeIDs = []string{"control"}
+	if err := rw.UpdateQuote(q, store.Change{Author: "ben"}); err != nil {
+		t.Fatalf("UpdateQuote: %v", err)
+	}
+	if err := rw.DeleteQuote("e1", store.Change{Author: "ana", Note: "duplicate"}); err != nil {
+		t.Fatalf("DeleteQuote: %v", err)
+	}
+	if err := store.Rollback(rw, "quote", "e1", 2, store.Change{Author: "ben", Note: "not a duplicate"}); err != nil {
+		t.Fatalf("Rollback: %v", err)
+	}
+	revs, err := rw.ListRevisions("quote", "e1")
+	if err != nil {
+		t.Fatalf("ListRevisions: %v", err)
+	}
+	return revs
+}
+
+func TestRevisionHistoryMatchesMemoryStore(t *testing.T) {
+	repo := db.NewRepository(db.NewQueries(seededDB(t)))
+	got := editHistory(t, repo)
+	want := editHistory(t, store.New())
+
+	if len(got) != 5 || len(got) != len(want) {
+		t.Fatalf("expected 5 revisions on both, got %d (sql) and %d (memory)", len(got), len(want))
+	}
+	for i := range got {
+		g, w := got[i], want[i]
+		if g.Number != w.Number || g.Action != w.Action || g.Author != w.Author || g.Note != w.Note {
+			t.Errorf("revision %d: sql %+v, memory %+v", i, g, w)
+		}
+		if d := store.Diff(g.Data, w.Data); len(d) != 0 {
+			t.Errorf("revision %d snapshots differ: %+v", g.Number, d)
+		}
+		if g.CreatedAt.IsZero() {
+			t.Errorf("revision %d has no timestamp", g.Number)
+		}
+	}
+	if got[0].Action != "create" || got[0].Note != "roll back to revision 2: not a duplicate" {
+		t.Errorf("rollback of a deleted quote should recreate it: %+v", got[0])
+	}
+
+	restored, err := repo.GetQuote("e1")
+	if err != nil || restored.Source != "Enchiridion 5" || len(restored.ThemeIDs) < 2 {
+		t.Errorf("expected revision 2's state back, got %+v (%v)", restored, err)
+	}
+	if rev, err := repo.GetRevision("quote", "e1", 9); err == nil {
+		t.Errorf("expected ErrNotFound for a missing revision, got %+v", rev)
+	}
+}
+
+// deleteHistory deletes theme "death" through any ReadWriter, then
+// recreates it and rolls back each quote its delete edited, returning
+// those quotes' histories.
+func deleteHistory(t *testing.T, rw store.ReadWriter) map[string][]models.Revision {
+	t.Helper()
+	theme, err := rw.GetTheme("death")
+	if err != nil {
+		t.Fatalf("GetTheme: %v", err)
+	}
+	before, _, err := rw.ListQuotes(store.Where("theme", "death"), store.Page{})
+	if err != nil || len(before) == 0 {
+		t.Fatalf("expected quotes on death, got %d (%v)", len(before), err)
+	}
+	if err := rw.DeleteTheme("death", store.Change{Author: "ana", Note: "merged into impermanence"}); err != nil {
+		t.Fatalf("DeleteTheme: %v", err)
+	}
+	if err := store.Rollback(rw, "theme", "death", 1, store.Change{Author: "ben"}); err != nil {
+		t.Fatalf("Rollback theme: %v", err)
+	}
+	if _, err := rw.GetTheme(theme.ID); err != nil {
+		t.Fatalf("theme not recreated: %v", err)
+	}
+
+	histories := map[string][]models.Revision{}
+	for _, q := range before {
+		revs, err := rw.ListRevisions("quote", q.ID)
+		if err != nil || len(revs) != 2 {
+			t.Fatalf("%s: expected a baseline and the cascaded edit, got %d (%v)", q.ID, len(revs), err)
+		}
+		if err := store.Rollback(rw, "quote", q.ID, 1, store.Change{Author: "ben"}); err != nil {
+			t.Fatalf("Rollback %s: %v", q.ID, err)
+		}
+		histories[q.ID], _ = rw.ListRevisions("quote", q.ID)
+	}
+	after, _, _ := rw.ListQuotes(store.Where("theme", "death"), store.Page{})
+	if len(after) != len(before) {
+		t.Errorf("rolling the quotes back relinked %d of %d", len(after), len(before))
+	}
+	return histories
+}
+
+func TestDeleteRecordsCascadedEdits(t *testing.T) {
+	got := deleteHistory(t, db.NewRepository(db.NewQueries(seededDB(t))))
+	want := deleteHistory(t, store.New())
+
+	if len(got) != len(want) {
+		t.Fatalf("sql edited %d quotes, memory %d", len(got), len(want))
+	}
+	for id, revs := range want {
+		if len(got[id]) != len(revs) {
+			t.Errorf("%s: sql has %d revisions, memory %d", id, len(got[id]), len(revs))
+			continue
+		}
+		cascaded := revs[1]
+		if cascaded.Action != "update" || cascaded.Author != "ana" || cascaded.Note != `theme "death" deleted: merged into impermanence` {
+			t.Errorf("%s: cascaded revision %+v", id, cascaded)
+		}
+		for i, w := range revs {
+			g := got[id][i]
+			if g.Action != w.Action || g.Author != w.Author || g.Note != w.Note {
+				t.Errorf("%s revision %d: sql %+v, memory %+v", id, w.Number, g, w)
+			}
+			if d := store.Diff(g.Data, w.Data); len(d) != 0 {
+				t.Errorf("%s revision %d snapshots differ: %+v", id, w.Number, d)
+			}
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package db_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"perennial-wisdom/db"
+	"perennial-wisdom/models"
+	"perennial-wisdom/store"
+)
+
+// searching adds quotes that only match through their longer fields,
+// then runs the same searches through any ReadWriter.
+func searching(t *testing.T, rw store.ReadWriter) []store.SearchResults {
+	t.Helper()
+	for _, q := range []models.Quote{
+		{ID: "srch-1", Text: "Begin at once to live.", PhilosopherID: "seneca", PhilosophyID: "stoic",
+			ThemeIDs: []string{"death"}, ExpositionStandard: "Procrastination <wastes> the zephyrine hours.", Status: store.StatusPublished},
+		{ID: "srch-2", Text: "Zephyrine winds carry the zephyrine seed.", PhilosopherID: "seneca", PhilosophyID: "stoic",
+			ThemeIDs: []string{"death", "impermanence"}, Status: store.StatusPublished},
+		{ID: "srch-3", Text: "Not yet public.", ModernReinterpretation: "A zephyrine draft.", Status: store.StatusDraft},
+		{ID: "srch-4", Text: "Śaṅkara taught that the Self is Brahman.", Status: store.StatusPublished},
+	} {
+		if err := rw.CreateQuote(q, edit); err != nil {
+			t.Fatalf("CreateQuote %s: %v", q.ID, err)
+		}
+	}
+
+	var out []store.SearchResults
+	for _, s := range []store.SearchQuery{
+		{Text: "zephyrine"},
+		{Text: "zephyrine", Limit: 1, Offset: 1},
+		{Text: "ZEPHYRINE hours"},
+		{Text: "zephyrine", Filter: store.Where("theme", "impermanence")},
+		{Text: "the", Filter: store.Where("tradition", "stoic")},
+		{Text: "100%_literal"},
+		{Text: "Epictetos"},
+		{Text: "zefyrine"},
+		{Text: "Śaṅkara"},
+		{Text: "sankara"},
+	} {
+		res, err := rw.SearchQuotes(s)
+		if err != nil {
+			t.Fatalf("SearchQuotes(%+v): %v", s, err)
+		}
+		out = append(out, res)
+	}
+	return out
+}
+
+func TestSearchMatchesMemoryStore(t *testing.T) {
+	repo := db.NewRepository(db.NewQueries(seededDB(t)))
+	got := searching(t, repo)
+	want := searching(t, store.New())
+
+	for i := range want {
+		if got[i].Total != want[i].Total || !reflect.DeepEqual(got[i].Facets, want[i].Facets) {
+			t.Errorf("search %d: sql total %d facets %v, memory total %d facets %v",
+				i, got[i].Total, got[i].Facets, want[i].Total, want[i].Facets)
+		}
+		if !reflect.DeepEqual(got[i].Names, want[i].Names) || got[i].DidYouMean != want[i].DidYouMean {
+			t.Errorf("search %d: sql names %v, %q; memory names %v, %q",
+				i, got[i].Names, got[i].DidYouMean, want[i].Names, want[i].DidYouMean)
+		}
+		if len(got[i].Hits) != len(want[i].Hits) {
+			t.Errorf("search %d: sql %d hits, memory %d", i, len(got[i].Hits), len(want[i].Hits))
+			continue
+		}
+		for j, h := range want[i].Hits {
+			if g := got[i].Hits[j]; g.Quote.ID != h.Quote.ID || g.Rank != h.Rank || g.Snippet != h.Snippet {
+				t.Errorf("search %d hit %d: sql %s %v %q, memory %s %v %q",
+					i, j, g.Quote.ID, g.Rank, g.Snippet, h.Quote.ID, h.Rank, h.Snippet)
+			}
+		}
+	}
+	if got[0].Total != 2 || got[5].Total != 0 {
+		t.Errorf("expected 2 live zephyrine matches and no wildcard match, got %d and %d", got[0].Total, got[5].Total)
+	}
+	for _, res := range got[8:10] {
+		if res.Total != 1 || res.Hits[0].Quote.ID != "srch-4" {
+			t.Errorf("expected Śaṅkara found with or without diacritics, got %+v", res.Hits)
+		}
+	}
+}
+
+func TestSearchIndexFollowsWrites(t *testing.T) {
+	repo := db.NewRepository(db.NewQueries(seededDB(t)))
+	if err := repo.WarmSearch(); err != nil {
+		t.Fatalf("WarmSearch: %v", err)
+	}
+	total := func(query string) int {
+		t.Helper()
+		res, err := repo.SearchQuotes(store.SearchQuery{Text: query})
+		if err != nil {
+			t.Fatalf("SearchQuotes: %v", err)
+		}
+		return res.Total
+	}
+
+	q := models.Quote{ID: "idx-1", Text: "The quincunx of virtue.", PhilosopherID: "seneca", PhilosophyID: "stoic",
+		Status: store.StatusPublished}
+	if err := repo.CreateQuote(q, edit); err != nil {
+		t.Fatalf("CreateQuote: %v", err)
+	}
+	if total("quincunxes") != 1 {
+		t.Error("created quote not found")
+	}
+
+	// Only a batch that commits reaches the index.
+	q.Text = "The heptagon of virtue."
+	err := repo.Batch(func(rw store.ReadWriter) error {
+		if err := rw.UpdateQuote(q, edit); err != nil {
+			return err
+		}
+		return errors.New("abandon")
+	})
+	if err == nil || total("heptagon") != 0 || total("quincunx") != 1 {
+		t.Errorf("rolled-back update reached the index (err %v)", err)
+	}
+	if err := repo.Batch(func(rw store.ReadWriter) error { return rw.UpdateQuote(q, edit) }); err != nil {
+		t.Fatalf("Batch: %v", err)
+	}
+	if total("heptagon") != 1 || total("quincunx") != 0 {
+		t.Error("committed update missing from the index")
+	}
+
+	// Deleting a school clears it from the quotes' facets.
+	if err := repo.DeletePhilosophy("stoic", edit); err != nil {
+		t.Fatalf("DeletePhilosophy: %v", err)
+	}
+	res, _ := repo.SearchQuotes(store.SearchQuery{Text: "heptagon"})
+	if res.Total != 1 || len(res.Facets["tradition"]) != 0 {
+		t.Errorf("after deleting the school: total %d, traditions %+v", res.Total, res.Facets["tradition"])
+	}
+
+	if err := repo.DeleteQuote("idx-1", edit); err != nil {
+		t.Fatalf("DeleteQuote: %v", err)
+	}
+	if total("heptagon") != 0 {
+		t.Error("deleted quote still found")
+	}
+}
+
+func TestSearchNamesFollowWrites(t *testing.T) {
+	repo := db.NewRepository(db.NewQueries(seededDB(t)))
+	named := func(query string) bool {
+		t.Helper()
+		res, err := repo.SearchQuotes(store.SearchQuery{Text: query})
+		if err != nil {
+			t.Fatalf("SearchQuotes: %v", err)
+		}
+		for _, n := range res.Names {
+			if n.ID == "epictetus" {
+				return true
+			}
+		}
+		return false
+	}
+	if !named("Epictetus") {
+		t.Fatal("Epictetus not matched by name")
+	}
+
+	p, err := repo.GetPhilosopher("epictetus")
+	if err != nil {
+		t.Fatalf("GetPhilosopher: %v", err)
+	}
+	p.Name = "Quintilian"
+	if err := repo.UpdatePhilosopher(p, edit); err != nil {
+		t.Fatalf("UpdatePhilosopher: %v", err)
+	}
+	if !named("Quintilian") || named("Epictetus") {
+		t.Error("rename not seen by name matching")
+	}
+
+	p.Name = "Zenodotus"
+	if err := repo.Batch(func(rw store.ReadWriter) error { return rw.UpdatePhilosopher(p, edit) }); err != nil {
+		t.Fatalf("Batch: %v", err)
+	}
+	if !named("Zenodotus") || named("Quintilian") {
+		t.Error("rename in a batch not seen by name matching")
+	}
+}
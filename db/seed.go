@@ -0,0 +1,190 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"perennial-wisdom/store"
+)
+
+// Seed upserts the canonical seed corpus (store.Seed*) into the database.
+// Idempotent: rows are matched by ID and updated in place, and join rows
+// owned by seeded entities are reconciled to exactly what the seeds say.
+// Rows that exist only in the database are left alone.
+// Runs in a single transaction — the corpus is loaded fully or not at all.
+func Seed(db *sqlx.DB) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := seedTraditions(tx); err != nil {
+		return fmt.Errorf("seed traditions: %w", err)
+	}
+	if err := seedPhilosophers(tx); err != nil {
+		return fmt.Errorf("seed philosophers: %w", err)
+	}
+	if err := seedThemes(tx); err != nil {
+		return fmt.Errorf("seed themes: %w", err)
+	}
+	if err := seedEvidence(tx); err != nil {
+		return fmt.Errorf("seed evidence: %w", err)
+	}
+	if err := seedQuotes(tx); err != nil {
+		return fmt.Errorf("seed quotes: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// IsEmpty reports whether the corpus tables hold no quotes yet.
+func IsEmpty(db *sqlx.DB) (bool, error) {
+	var n int
+	err := db.Get(&n, "SELECT COUNT(*) FROM quotes")
+	return n == 0, err
+}
+
+func seedTraditions(tx *sqlx.Tx) error {
+	philosophies := store.SeedPhilosophies()
+
+	// Insert every school first so relation rows can reference any of them.
+	for _, p := range philosophies {
+		_, err := tx.Exec(`INSERT INTO traditions (id, name, origin, core_principles)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (id) DO UPDATE SET
+				name = excluded.name,
+				origin = excluded.origin,
+				core_principles = excluded.core_principles,
+				updated_at = CURRENT_TIMESTAMP`,
+			p.ID, p.Name, nullString(p.Origin), jsonArray(p.CorePrinciples))
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, p := range philosophies {
+		if err := replaceLinks(tx, "tradition_relations", "tradition_id", "related_id", p.ID, p.RelatedIDs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func seedPhilosophers(tx *sqlx.Tx) error {
+	for _, p := range store.SeedPhilosophers() {
+		_, err := tx.Exec(`INSERT INTO philosophers (id, name, tradition_id, era, bio, key_teachings)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (id) DO UPDATE SET
+				name = excluded.name,
+				tradition_id = excluded.tradition_id,
+				era = excluded.era,
+				bio = excluded.bio,
+				key_teachings = excluded.key_teachings,
+				updated_at = CURRENT_TIMESTAMP`,
+			p.ID, p.Name, nullString(p.PhilosophyID), nullString(p.Era), nullString(p.Bio), jsonArray(p.KeyTeachings))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func seedThemes(tx *sqlx.Tx) error {
+	for _, t := range store.SeedThemes() {
+		_, err := tx.Exec(`INSERT INTO themes (id, name, description)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (id) DO UPDATE SET
+				name = excluded.name,
+				description = excluded.description,
+				updated_at = CURRENT_TIMESTAMP`,
+			t.ID, t.Name, nullString(t.Description))
+		if err != nil {
+			return err
+		}
+		if err := replaceLinks(tx, "theme_traditions", "theme_id", "tradition_id", t.ID, t.PhilosophyIDs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func seedEvidence(tx *sqlx.Tx) error {
+	for _, e := range store.SeedEvidence() {
+		_, err := tx.Exec(`INSERT INTO evidence (id, title, finding, field, citation)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (id) DO UPDATE SET
+				title = excluded.title,
+				finding = excluded.finding,
+				field = excluded.field,
+				citation = excluded.citation,
+				updated_at = CURRENT_TIMESTAMP`,
+			e.ID, e.Title, nullString(e.Finding), nullString(e.Field), nullString(e.Source))
+		if err != nil {
+			return err
+		}
+		if err := replaceLinks(tx, "evidence_themes", "evidence_id", "theme_id", e.ID, e.ThemeIDs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func seedQuotes(tx *sqlx.Tx) error {
+	for _, q := range store.SeedQuotes() {
+		_, err := tx.Exec(`INSERT INTO quotes (id, text, philosopher_id, tradition_id, source_work)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (id) DO UPDATE SET
+				text = excluded.text,
+				philosopher_id = excluded.philosopher_id,
+				tradition_id = excluded.tradition_id,
+				source_work = excluded.source_work,
+				updated_at = CURRENT_TIMESTAMP`,
+			q.ID, q.Text, nullString(q.PhilosopherID), nullString(q.PhilosophyID), nullString(q.Source))
+		if err != nil {
+			return err
+		}
+		if err := replaceLinks(tx, "quote_themes", "quote_id", "theme_id", q.ID, q.ThemeIDs); err != nil {
+			return err
+		}
+		if err := replaceLinks(tx, "quote_evidence", "quote_id", "evidence_id", q.ID, q.EvidenceIDs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replaceLinks makes the join table rows for one owner match ids exactly.
+// Table and column names are compile-time constants, never user input.
+func replaceLinks(tx *sqlx.Tx, table, ownerCol, targetCol, ownerID string, ids []string) error {
+	if _, err := tx.Exec("DELETE FROM "+table+" WHERE "+ownerCol+" = $1", ownerID); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		_, err := tx.Exec("INSERT INTO "+table+" ("+ownerCol+", "+targetCol+") VALUES ($1, $2) ON CONFLICT DO NOTHING",
+			ownerID, id)
+		if err != nil {
+			return fmt.Errorf("%s %s -> %s: %w", table, ownerID, id, err)
+		}
+	}
+	return nil
+}
+
+// nullString maps "" to SQL NULL so optional columns stay NULL, not empty.
+func nullString(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// jsonArray encodes a string slice for a JSONB column, never as null.
+func jsonArray(items []string) string {
+	if items == nil {
+		items = []string{}
+	}
+	b, _ := json.Marshal(items)
+	return string(b)
+}
@@ -0,0 +1,103 @@
+package db_test
+
+import (
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+
+	"perennial-wisdom/db"
+	"perennial-wisdom/store"
+)
+
+func seededDB(t *testing.T) *sqlx.DB {
+	t.Helper()
+	database := testDB(t)
+	if err := db.Migrate(database); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if err := db.Seed(database); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	return database
+}
+
+func count(t *testing.T, database *sqlx.DB, query string) int {
+	t.Helper()
+	var n int
+	if err := database.Get(&n, query); err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	return n
+}
+
+func TestSeedLoadsCorpus(t *testing.T) {
+	database := seededDB(t)
+
+	var quoteThemes, quoteEvidence, themeTraditions int
+	for _, q := range store.SeedQuotes() {
+		quoteThemes += len(q.ThemeIDs)
+		quoteEvidence += len(q.EvidenceIDs)
+	}
+	for _, th := range store.SeedThemes() {
+		themeTraditions += len(th.PhilosophyIDs)
+	}
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"SELECT COUNT(*) FROM quotes", len(store.SeedQuotes())},
+		{"SELECT COUNT(*) FROM philosophers", len(store.SeedPhilosophers())},
+		{"SELECT COUNT(*) FROM traditions", len(store.SeedPhilosophies())},
+		{"SELECT COUNT(*) FROM themes", len(store.SeedThemes())},
+		{"SELECT COUNT(*) FROM evidence", len(store.SeedEvidence())},
+		{"SELECT COUNT(*) FROM quote_themes", quoteThemes},
+		{"SELECT COUNT(*) FROM quote_evidence", quoteEvidence},
+		{"SELECT COUNT(*) FROM theme_traditions", themeTraditions},
+	}
+	for _, tt := range tests {
+		if got := count(t, database, tt.query); got != tt.want {
+			t.Errorf("%s: expected %d, got %d", tt.query, tt.want, got)
+		}
+	}
+}
+
+func TestSeedIsIdempotent(t *testing.T) {
+	database := seededDB(t)
+	before := count(t, database, "SELECT COUNT(*) FROM quote_themes")
+
+	if err := db.Seed(database); err != nil {
+		t.Fatalf("second seed: %v", err)
+	}
+
+	if got := count(t, database, "SELECT COUNT(*) FROM quotes"); got != len(store.SeedQuotes()) {
+		t.Errorf("expected %d quotes after reseed, got %d", len(store.SeedQuotes()), got)
+	}
+	if got := count(t, database, "SELECT COUNT(*) FROM quote_themes"); got != before {
+		t.Errorf("expected %d quote_themes after reseed, got %d", before, got)
+	}
+}
+
+func TestSeedReconcilesDrift(t *testing.T) {
+	database := seededDB(t)
+
+	database.MustExec("UPDATE quotes SET text = 'tampered' WHERE id = 'e1'")
+	database.MustExec("DELETE FROM quote_themes WHERE quote_id = 'e1'")
+	database.MustExec("INSERT INTO quote_themes (quote_id, theme_id) VALUES ('e1', 'death')")
+
+	if err := db.Seed(database); err != nil {
+		t.Fatalf("reseed: %v", err)
+	}
+
+	var text string
+	database.Get(&text, "SELECT text FROM quotes WHERE id = 'e1'")
+	if text == "tampered" {
+		t.Error("expected seed to restore quote text")
+	}
+
+	var themes []string
+	database.Select(&themes, "SELECT theme_id FROM quote_themes WHERE quote_id = 'e1' ORDER BY theme_id")
+	if len(themes) != 2 || themes[0] != "control" || themes[1] != "suffering" {
+		t.Errorf("expected e1 themes [control suffering], got %v", themes)
+	}
+}
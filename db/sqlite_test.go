@@ -0,0 +1,86 @@
+package db_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"perennial-wisdom/db"
+	"perennial-wisdom/store"
+)
+
+func TestOpenSQLiteFile(t *testing.T) {
+	t.Setenv("DATABASE_URL", "")
+	t.Setenv("DB_HOST", "")
+
+	path := filepath.Join(t.TempDir(), "data", "wisdom.db")
+	database := db.Open(path)
+	defer database.Close()
+
+	if got := db.DialectOf(database); got != db.SQLite {
+		t.Fatalf("expected sqlite dialect, got %s", got)
+	}
+	if err := db.Migrate(database); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if err := db.Seed(database); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+
+	var fk int
+	database.Get(&fk, "PRAGMA foreign_keys")
+	if fk != 1 {
+		t.Error("expected foreign keys enabled on file database")
+	}
+}
+
+func TestQueriesOnSQLite(t *testing.T) {
+	q := db.NewQueries(seededDB(t))
+
+	quotes, err := q.ListQuotes(store.Filter{
+		"philosopher": {Values: []string{"epictetus"}},
+		"philosophy":  {Values: []string{"stoic"}},
+		"theme":       {Values: []string{"control"}},
+	}, store.Page{})
+	if err != nil {
+		t.Fatalf("ListQuotes: %v", err)
+	}
+	if len(quotes) == 0 {
+		t.Error("expected Epictetus quotes on control")
+	}
+
+	if _, err := q.RandomQuote(); err != nil {
+		t.Errorf("RandomQuote: %v", err)
+	}
+
+	p, err := q.GetPhilosopher("epictetus")
+	if err != nil {
+		t.Fatalf("GetPhilosopher: %v", err)
+	}
+	if len(p.Teachings()) == 0 {
+		t.Error("expected key_teachings to decode from SQLite JSON text")
+	}
+
+	tr, err := q.GetTradition("stoic")
+	if err != nil {
+		t.Fatalf("GetTradition: %v", err)
+	}
+	if len(tr.Principles()) == 0 {
+		t.Error("expected core_principles to decode from SQLite JSON text")
+	}
+
+	hits, err := q.SearchQuotes(store.SearchQuery{Text: "imagination reality"})
+	if err != nil {
+		t.Fatalf("SearchQuotes: %v", err)
+	}
+	if len(hits) != 1 || hits[0].ID != "s1" {
+		t.Errorf("expected Seneca s1 for 'imagination reality', got %d hits", len(hits))
+	}
+
+	none, err := q.SearchQuotes(store.SearchQuery{Text: "100%_literal"})
+	if err != nil {
+		t.Fatalf("SearchQuotes wildcard: %v", err)
+	}
+	if len(none) != 0 {
+		t.Errorf("expected LIKE wildcards to match literally, got %d hits", len(none))
+	}
+}
@@ -0,0 +1,39 @@
+package db_test
+
+import (
+	"slices"
+	"testing"
+
+	"perennial-wisdom/db"
+	"perennial-wisdom/models"
+	"perennial-wisdom/store"
+)
+
+func TestValidateSeededDatabase(t *testing.T) {
+	repo := db.NewRepository(db.NewQueries(seededDB(t)))
+
+	report, err := store.Validate(repo)
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if !report.OK() {
+		t.Errorf("seeded database has integrity errors: %+v", report.Issues)
+	}
+
+	// Drafts are part of the corpus too.
+	seeded := report.Counts["quote"]
+	draft := models.Quote{ID: "val-draft", Text: "It's not what happens to you, but how you react to it that matters.", PhilosopherID: "epictetus"}
+	if err := repo.CreateQuote(draft, edit); err != nil {
+		t.Fatalf("CreateQuote: %v", err)
+	}
+	report, err = store.Validate(repo)
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	dup := slices.ContainsFunc(report.Issues, func(is store.Issue) bool {
+		return is.Check == store.CheckDuplicateQuote && is.ID == "val-draft"
+	})
+	if report.Counts["quote"] != seeded+1 || report.Errors() != 1 || !dup {
+		t.Errorf("expected the draft to be checked as a duplicate: %+v", report)
+	}
+}
@@ -0,0 +1,71 @@
+package db_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"perennial-wisdom/db"
+	"perennial-wisdom/models"
+	"perennial-wisdom/store"
+)
+
+// statusListing moves three new quotes to different statuses through any
+// ReadWriter and returns the IDs each status filter lists.
+func statusListing(t *testing.T, rw store.ReadWriter) map[string][]string {
+	t.Helper()
+	ch := store.Change{Author: "ana"}
+	wf := store.Workflow{}
+	later := time.Now().Add(24 * time.Hour)
+	for _, id := range []string{"s-draft", "s-review", "s-later"} {
+		if err := rw.CreateQuote(models.Quote{ID: id, Text: "Status " + id}, ch); err != nil {
+			t.Fatalf("CreateQuote: %v", err)
+		}
+	}
+	if err := wf.Transition(rw, "s-review", store.StatusInReview, nil, ch); err != nil {
+		t.Fatalf("Transition: %v", err)
+	}
+	if err := wf.Transition(rw, "s-later", store.StatusPublished, &later, ch); err != nil {
+		t.Fatalf("Transition: %v", err)
+	}
+	if q, err := rw.GetQuote("s-later"); err != nil || q.PublishAt == nil || !q.PublishAt.Equal(later.UTC().Truncate(time.Second)) {
+		t.Errorf("publish_at not stored: %+v (%v)", q.PublishAt, err)
+	}
+
+	got := map[string][]string{}
+	for _, status := range append([]string{""}, store.Statuses...) {
+		qs, _, err := rw.ListQuotes(store.Where("status", status), store.Page{})
+		if err != nil {
+			t.Fatalf("ListQuotes(%q): %v", status, err)
+		}
+		for _, q := range qs {
+			if q.ID == "e1" || strings.HasPrefix(q.ID, "s-") {
+				got[status] = append(got[status], q.ID)
+			}
+		}
+	}
+	return got
+}
+
+func TestQuoteStatusMatchesMemoryStore(t *testing.T) {
+	repo := db.NewRepository(db.NewQueries(seededDB(t)))
+	got := statusListing(t, repo)
+	want := statusListing(t, store.New())
+
+	for status, ids := range want {
+		if !slices.Equal(got[status], ids) {
+			t.Errorf("status %q: sql lists %v, memory %v", status, got[status], ids)
+		}
+	}
+	if !slices.Equal(got[""], []string{"e1"}) || !slices.Equal(got[store.StatusScheduled], []string{"s-later"}) {
+		t.Errorf("only seeded quotes should be public: %v", got)
+	}
+
+	for range 20 {
+		q, err := repo.RandomQuote()
+		if err != nil || !store.Live(q) {
+			t.Fatalf("RandomQuote returned a hidden quote: %+v (%v)", q, err)
+		}
+	}
+}
@@ -0,0 +1,452 @@
+package db
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"perennial-wisdom/models"
+	"perennial-wisdom/store"
+)
+
+var _ store.ReadWriter = (*Repository)(nil)
+
+// inTx runs fn in a transaction, committing only if it succeeds. Queries
+// already bound to a transaction run fn in it, leaving the commit to
+// whoever began it.
+func (q *Queries) inTx(fn func(tx *sqlx.Tx) error) error {
+	if q.tx != nil {
+		return fn(q.tx)
+	}
+	tx, err := q.conn.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Batch runs fn in one transaction: every write fn makes through its
+// argument commits together, or not at all.
+func (r *Repository) Batch(fn func(store.ReadWriter) error) error {
+	var pending []written
+	err := r.q.inTx(func(tx *sqlx.Tx) error {
+		return fn(&Repository{q: r.q.in(tx), search: r.search, names: r.names, pending: &pending})
+	})
+	// A search in fn may have cached names the commit changed, or that
+	// the rollback undid.
+	r.names.forget()
+	if err != nil {
+		return err
+	}
+	for _, w := range pending {
+		r.reindex(w.kind, w.id, w.action)
+	}
+	return nil
+}
+
+// kindTables maps store.Exists kinds onto tables.
+var kindTables = map[string]string{
+	"quote":       "quotes",
+	"philosopher": "philosophers",
+	"philosophy":  "traditions",
+	"theme":       "themes",
+	"evidence":    "evidence",
+}
+
+// existsIn binds store.Exists to a transaction, so references are
+// checked against the same snapshot the write lands in.
+func existsIn(tx *sqlx.Tx) store.Exists {
+	return func(kind, id string) (bool, error) {
+		return rowExists(tx, kindTables[kind], id)
+	}
+}
+
+func rowExists(tx *sqlx.Tx, table, id string) (bool, error) {
+	var n int
+	err := tx.Get(&n, "SELECT COUNT(*) FROM "+table+" WHERE id = $1", id)
+	return n > 0, err
+}
+
+// create guards an insert: the ID must be free, then write runs.
+func create(tx *sqlx.Tx, kind, id string, write func() error) error {
+	taken, err := rowExists(tx, kindTables[kind], id)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return fmt.Errorf("%w: %s %q already exists", store.ErrConflict, kind, id)
+	}
+	return conflict(write(), kind, id)
+}
+
+// update guards a replace: the row must exist, then write runs.
+func update(tx *sqlx.Tx, kind, id string, write func() error) error {
+	found, err := rowExists(tx, kindTables[kind], id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return store.ErrNotFound
+	}
+	return conflict(write(), kind, id)
+}
+
+// remove deletes one row; the schema's ON DELETE rules clear references.
+// Every entity they edit gets a revision in the same transaction: each
+// one's revision wraps the delete, so a baseline it needs is taken from
+// before it.
+func (r *Repository) remove(kind, id string, ch store.Change) error {
+	err := r.q.inTx(func(tx *sqlx.Tx) error {
+		in := &Repository{q: r.q.in(tx)}
+		write := func(tx *sqlx.Tx) error {
+			res, err := tx.Exec("DELETE FROM "+kindTables[kind]+" WHERE id = $1", id)
+			if err != nil {
+				return err
+			}
+			if n, _ := res.RowsAffected(); n == 0 {
+				return store.ErrNotFound
+			}
+			return nil
+		}
+		cascaded := store.Cascaded(ch, kind, id)
+		for _, ref := range referrers[kind] {
+			var ids []string
+			if err := tx.Select(&ids, ref.query, id); err != nil {
+				return err
+			}
+			for _, rid := range ids {
+				inner := write
+				write = func(tx *sqlx.Tx) error {
+					return in.revised(ref.kind, rid, "update", cascaded, inner)
+				}
+			}
+		}
+		return in.revised(kind, id, "delete", ch, write)
+	})
+	if err != nil {
+		return err
+	}
+	r.forgetNames(kind)
+	r.reindex(kind, id, "delete")
+	return nil
+}
+
+// referrers finds, for each kind, the entities whose state deleting one
+// changes through the schema's ON DELETE rules.
+var referrers = map[string][]struct{ kind, query string }{
+	"philosopher": {
+		{"quote", "SELECT id FROM quotes WHERE philosopher_id = $1 ORDER BY id"},
+	},
+	"philosophy": {
+		{"philosophy", "SELECT tradition_id FROM tradition_relations WHERE related_id = $1 ORDER BY tradition_id"},
+		{"philosopher", "SELECT id FROM philosophers WHERE tradition_id = $1 ORDER BY id"},
+		{"theme", "SELECT theme_id FROM theme_traditions WHERE tradition_id = $1 ORDER BY theme_id"},
+		{"quote", "SELECT id FROM quotes WHERE tradition_id = $1 ORDER BY id"},
+	},
+	"theme": {
+		{"quote", "SELECT quote_id FROM quote_themes WHERE theme_id = $1 ORDER BY quote_id"},
+		{"evidence", "SELECT evidence_id FROM evidence_themes WHERE theme_id = $1 ORDER BY evidence_id"},
+	},
+	"evidence": {
+		{"quote", "SELECT quote_id FROM quote_evidence WHERE evidence_id = $1 ORDER BY quote_id"},
+	},
+}
+
+// conflict maps unique-key violations that slipped past the pre-checks
+// (a concurrent writer on PostgreSQL) onto store.ErrConflict.
+func conflict(err error, kind, id string) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" ||
+		err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed") {
+		return fmt.Errorf("%w: %s %q: %v", store.ErrConflict, kind, id, err)
+	}
+	return err
+}
+
+// --- Quotes ---
+
+const quoteColumns = `title = $2, slug = $3, text = $4, text_scholarly = $5,
+	philosopher_id = $6, tradition_id = $7, source_work = $8, source_location = $9,
+	original_script = $10, exposition_brief = $11, exposition_standard = $12,
+	exposition_scholarly = $13, reflection_prompt = $14, modern_reinterpretation = $15,
+	meta = $16, status = $17, publish_at = $18`
+
+func quoteArgs(q models.Quote) []any {
+	return []any{q.ID, nullString(q.Title), nullString(q.Slug), q.Text, nullString(q.TextScholarly),
+		nullString(q.PhilosopherID), nullString(q.PhilosophyID), nullString(q.Source), nullString(q.SourceLocation),
+		nullString(q.OriginalScript), nullString(q.ExpositionBrief), nullString(q.ExpositionStandard),
+		nullString(q.ExpositionScholarly), nullString(q.ReflectionPrompt), nullString(q.ModernReinterpretation),
+		jsonObject(q.Meta), cmp.Or(q.Status, store.StatusPublished), publishAt(q.PublishAt)}
+}
+
+// publishAt binds a schedule as UTC, or NULL.
+func publishAt(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
+
+// CreateQuote inserts a quote with its theme and evidence links.
+// Quotes without a status start as drafts.
+func (r *Repository) CreateQuote(qt models.Quote, ch store.Change) error {
+	if qt.Status == "" {
+		qt.Status = store.StatusDraft
+	}
+	return r.revised("quote", qt.ID, "create", ch, func(tx *sqlx.Tx) error {
+		if err := checkQuote(tx, qt); err != nil {
+			return err
+		}
+		return create(tx, "quote", qt.ID, func() error {
+			_, err := tx.Exec(`INSERT INTO quotes (id, title, slug, text, text_scholarly,
+				philosopher_id, tradition_id, source_work, source_location, original_script,
+				exposition_brief, exposition_standard, exposition_scholarly, reflection_prompt,
+				modern_reinterpretation, meta, status, publish_at, published_at)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18,
+					CASE WHEN $17 = 'published' THEN CURRENT_TIMESTAMP END)`,
+				quoteArgs(qt)...)
+			if err != nil {
+				return err
+			}
+			return quoteLinks(tx, qt)
+		})
+	})
+}
+
+// UpdateQuote replaces a quote and its links. A quote moving to
+// published is stamped as published now.
+func (r *Repository) UpdateQuote(qt models.Quote, ch store.Change) error {
+	return r.revised("quote", qt.ID, "update", ch, func(tx *sqlx.Tx) error {
+		if err := checkQuote(tx, qt); err != nil {
+			return err
+		}
+		return update(tx, "quote", qt.ID, func() error {
+			_, err := tx.Exec(`UPDATE quotes SET `+quoteColumns+`, updated_at = CURRENT_TIMESTAMP,
+				published_at = CASE WHEN $17 = 'published' AND status <> 'published'
+					THEN CURRENT_TIMESTAMP ELSE published_at END
+				WHERE id = $1`, quoteArgs(qt)...)
+			if err != nil {
+				return err
+			}
+			return quoteLinks(tx, qt)
+		})
+	})
+}
+
+// DeleteQuote removes a quote; its link rows cascade.
+func (r *Repository) DeleteQuote(id string, ch store.Change) error {
+	return r.remove("quote", id, ch)
+}
+
+// checkQuote validates references and that the slug is free.
+func checkQuote(tx *sqlx.Tx, qt models.Quote) error {
+	if err := store.CheckQuote(qt, existsIn(tx)); err != nil {
+		return err
+	}
+	if qt.Slug == "" {
+		return nil
+	}
+	var owner []string
+	if err := tx.Select(&owner, "SELECT id FROM quotes WHERE slug = $1 AND id <> $2", qt.Slug, qt.ID); err != nil {
+		return err
+	}
+	if len(owner) > 0 {
+		return fmt.Errorf("%w: slug %q is taken by quote %q", store.ErrConflict, qt.Slug, owner[0])
+	}
+	return nil
+}
+
+func quoteLinks(tx *sqlx.Tx, qt models.Quote) error {
+	if err := replaceLinks(tx, "quote_themes", "quote_id", "theme_id", qt.ID, qt.ThemeIDs); err != nil {
+		return err
+	}
+	return replaceLinks(tx, "quote_evidence", "quote_id", "evidence_id", qt.ID, qt.EvidenceIDs)
+}
+
+// --- Philosophers ---
+
+// CreatePhilosopher inserts a philosopher.
+func (r *Repository) CreatePhilosopher(p models.Philosopher, ch store.Change) error {
+	return r.revised("philosopher", p.ID, "create", ch, func(tx *sqlx.Tx) error {
+		if err := store.CheckPhilosopher(p, existsIn(tx)); err != nil {
+			return err
+		}
+		return create(tx, "philosopher", p.ID, func() error {
+			_, err := tx.Exec(`INSERT INTO philosophers (id, name, tradition_id, era, bio, key_teachings)
+				VALUES ($1, $2, $3, $4, $5, $6)`,
+				p.ID, p.Name, nullString(p.PhilosophyID), nullString(p.Era), nullString(p.Bio), jsonArray(p.KeyTeachings))
+			return err
+		})
+	})
+}
+
+// UpdatePhilosopher replaces a philosopher.
+func (r *Repository) UpdatePhilosopher(p models.Philosopher, ch store.Change) error {
+	return r.revised("philosopher", p.ID, "update", ch, func(tx *sqlx.Tx) error {
+		if err := store.CheckPhilosopher(p, existsIn(tx)); err != nil {
+			return err
+		}
+		return update(tx, "philosopher", p.ID, func() error {
+			_, err := tx.Exec(`UPDATE philosophers SET name = $2, tradition_id = $3, era = $4, bio = $5,
+				key_teachings = $6, updated_at = CURRENT_TIMESTAMP
+				WHERE id = $1`,
+				p.ID, p.Name, nullString(p.PhilosophyID), nullString(p.Era), nullString(p.Bio), jsonArray(p.KeyTeachings))
+			return err
+		})
+	})
+}
+
+// DeletePhilosopher removes a philosopher; their quotes become unattributed.
+func (r *Repository) DeletePhilosopher(id string, ch store.Change) error {
+	return r.remove("philosopher", id, ch)
+}
+
+// --- Philosophies (traditions) ---
+
+// CreatePhilosophy inserts a school with its relations.
+func (r *Repository) CreatePhilosophy(p models.Philosophy, ch store.Change) error {
+	return r.revised("philosophy", p.ID, "create", ch, func(tx *sqlx.Tx) error {
+		if err := store.CheckPhilosophy(p, existsIn(tx)); err != nil {
+			return err
+		}
+		return create(tx, "philosophy", p.ID, func() error {
+			_, err := tx.Exec(`INSERT INTO traditions (id, name, origin, core_principles)
+				VALUES ($1, $2, $3, $4)`,
+				p.ID, p.Name, nullString(p.Origin), jsonArray(p.CorePrinciples))
+			if err != nil {
+				return err
+			}
+			return replaceLinks(tx, "tradition_relations", "tradition_id", "related_id", p.ID, p.RelatedIDs)
+		})
+	})
+}
+
+// UpdatePhilosophy replaces a school and its relations.
+func (r *Repository) UpdatePhilosophy(p models.Philosophy, ch store.Change) error {
+	return r.revised("philosophy", p.ID, "update", ch, func(tx *sqlx.Tx) error {
+		if err := store.CheckPhilosophy(p, existsIn(tx)); err != nil {
+			return err
+		}
+		return update(tx, "philosophy", p.ID, func() error {
+			_, err := tx.Exec(`UPDATE traditions SET name = $2, origin = $3, core_principles = $4,
+				updated_at = CURRENT_TIMESTAMP
+				WHERE id = $1`,
+				p.ID, p.Name, nullString(p.Origin), jsonArray(p.CorePrinciples))
+			if err != nil {
+				return err
+			}
+			return replaceLinks(tx, "tradition_relations", "tradition_id", "related_id", p.ID, p.RelatedIDs)
+		})
+	})
+}
+
+// DeletePhilosophy removes a school; references to it are cleared.
+func (r *Repository) DeletePhilosophy(id string, ch store.Change) error {
+	return r.remove("philosophy", id, ch)
+}
+
+// --- Themes ---
+
+// CreateTheme inserts a theme with its schools.
+func (r *Repository) CreateTheme(t models.Theme, ch store.Change) error {
+	return r.revised("theme", t.ID, "create", ch, func(tx *sqlx.Tx) error {
+		if err := store.CheckTheme(t, existsIn(tx)); err != nil {
+			return err
+		}
+		return create(tx, "theme", t.ID, func() error {
+			_, err := tx.Exec(`INSERT INTO themes (id, name, description) VALUES ($1, $2, $3)`,
+				t.ID, t.Name, nullString(t.Description))
+			if err != nil {
+				return err
+			}
+			return replaceLinks(tx, "theme_traditions", "theme_id", "tradition_id", t.ID, t.PhilosophyIDs)
+		})
+	})
+}
+
+// UpdateTheme replaces a theme and its schools.
+func (r *Repository) UpdateTheme(t models.Theme, ch store.Change) error {
+	return r.revised("theme", t.ID, "update", ch, func(tx *sqlx.Tx) error {
+		if err := store.CheckTheme(t, existsIn(tx)); err != nil {
+			return err
+		}
+		return update(tx, "theme", t.ID, func() error {
+			_, err := tx.Exec(`UPDATE themes SET name = $2, description = $3, updated_at = CURRENT_TIMESTAMP
+				WHERE id = $1`,
+				t.ID, t.Name, nullString(t.Description))
+			if err != nil {
+				return err
+			}
+			return replaceLinks(tx, "theme_traditions", "theme_id", "tradition_id", t.ID, t.PhilosophyIDs)
+		})
+	})
+}
+
+// DeleteTheme removes a theme; quote and evidence links cascade.
+func (r *Repository) DeleteTheme(id string, ch store.Change) error {
+	return r.remove("theme", id, ch)
+}
+
+// --- Evidence ---
+
+// CreateEvidence inserts an evidence entry with its themes.
+func (r *Repository) CreateEvidence(e models.Evidence, ch store.Change) error {
+	return r.revised("evidence", e.ID, "create", ch, func(tx *sqlx.Tx) error {
+		if err := store.CheckEvidence(e, existsIn(tx)); err != nil {
+			return err
+		}
+		return create(tx, "evidence", e.ID, func() error {
+			_, err := tx.Exec(`INSERT INTO evidence (id, title, finding, field, citation, evidence_strength)
+				VALUES ($1, $2, $3, $4, $5, $6)`,
+				e.ID, e.Title, nullString(e.Finding), nullString(e.Field), nullString(e.Source), nullString(e.Strength))
+			if err != nil {
+				return err
+			}
+			return replaceLinks(tx, "evidence_themes", "evidence_id", "theme_id", e.ID, e.ThemeIDs)
+		})
+	})
+}
+
+// UpdateEvidence replaces an evidence entry and its themes.
+func (r *Repository) UpdateEvidence(e models.Evidence, ch store.Change) error {
+	return r.revised("evidence", e.ID, "update", ch, func(tx *sqlx.Tx) error {
+		if err := store.CheckEvidence(e, existsIn(tx)); err != nil {
+			return err
+		}
+		return update(tx, "evidence", e.ID, func() error {
+			_, err := tx.Exec(`UPDATE evidence SET title = $2, finding = $3, field = $4, citation = $5,
+				evidence_strength = $6, updated_at = CURRENT_TIMESTAMP
+				WHERE id = $1`,
+				e.ID, e.Title, nullString(e.Finding), nullString(e.Field), nullString(e.Source), nullString(e.Strength))
+			if err != nil {
+				return err
+			}
+			return replaceLinks(tx, "evidence_themes", "evidence_id", "theme_id", e.ID, e.ThemeIDs)
+		})
+	})
+}
+
+// DeleteEvidence removes an evidence entry; quote links cascade.
+func (r *Repository) DeleteEvidence(id string, ch store.Change) error {
+	return r.remove("evidence", id, ch)
+}
+
+// jsonObject encodes a map for a JSONB column; empty maps stay NULL.
+func jsonObject(m map[string]any) any {
+	if len(m) == 0 {
+		return nil
+	}
+	b, _ := json.Marshal(m)
+	return string(b)
+}
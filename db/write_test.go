@@ -0,0 +1,117 @@
+package db_test
+
+import (
+	"errors"
+	"testing"
+
+	"perennial-wisdom/db"
+	"perennial-wisdom/models"
+	"perennial-wisdom/store"
+)
+
+// edit describes the writes tests make.
+var edit = store.Change{Author: "tester", Note: "test edit"}
+
+func TestRepositoryWrites(t *testing.T) {
+	database := seededDB(t)
+	repo := db.NewRepository(db.NewQueries(database))
+
+	q := models.Quote{
+		ID: "new-quote", Slug: "new-quote", Title: "New", Text: "Know thyself.",
+		PhilosopherID: "epictetus", PhilosophyID: "stoic",
+		ThemeIDs: []string{"control", "virtue"}, EvidenceIDs: []string{"cognitive-reappraisal"},
+		Meta: map[string]any{"curator": "test"},
+	}
+	if err := repo.CreateQuote(q, edit); err != nil {
+		t.Fatalf("CreateQuote: %v", err)
+	}
+	got, err := repo.GetQuote("new-quote")
+	if err != nil {
+		t.Fatalf("GetQuote: %v", err)
+	}
+	if got.Title != "New" || len(got.ThemeIDs) != 2 || len(got.EvidenceIDs) != 1 || got.Meta["curator"] != "test" {
+		t.Errorf("stored quote differs: %+v", got)
+	}
+
+	if err := repo.CreateQuote(q, edit); !errors.Is(err, store.ErrConflict) {
+		t.Errorf("duplicate id: expected ErrConflict, got %v", err)
+	}
+	if err := repo.CreateQuote(models.Quote{ID: "other", Slug: "new-quote", Text: "x"}, edit); !errors.Is(err, store.ErrConflict) {
+		t.Errorf("duplicate slug: expected ErrConflict, got %v", err)
+	}
+
+	// A rejected update changes nothing.
+	bad := q
+	bad.ThemeIDs = []string{"control", "no-such-theme"}
+	if err := repo.UpdateQuote(bad, edit); !errors.Is(err, store.ErrInvalid) {
+		t.Errorf("dangling theme: expected ErrInvalid, got %v", err)
+	}
+	if got, _ := repo.GetQuote("new-quote"); len(got.ThemeIDs) != 2 {
+		t.Errorf("rejected update leaked: %v", got.ThemeIDs)
+	}
+
+	q.Text = "Know thyself, and be still."
+	q.ThemeIDs = []string{"virtue"}
+	if err := repo.UpdateQuote(q, edit); err != nil {
+		t.Fatalf("UpdateQuote: %v", err)
+	}
+	if got, _ := repo.GetQuote("new-quote"); got.Text != q.Text || len(got.ThemeIDs) != 1 {
+		t.Errorf("update not applied: %+v", got)
+	}
+	if err := repo.UpdateQuote(models.Quote{ID: "missing", Text: "x"}, edit); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("update missing: expected ErrNotFound, got %v", err)
+	}
+
+	// Deletes follow the foreign keys: links cascade, attributions clear.
+	if err := repo.DeleteTheme("virtue", edit); err != nil {
+		t.Fatalf("DeleteTheme: %v", err)
+	}
+	if err := repo.DeletePhilosopher("epictetus", edit); err != nil {
+		t.Fatalf("DeletePhilosopher: %v", err)
+	}
+	got, _ = repo.GetQuote("new-quote")
+	if len(got.ThemeIDs) != 0 || got.PhilosopherID != "" {
+		t.Errorf("references not cleared: %+v", got)
+	}
+	if err := repo.DeleteQuote("new-quote", edit); err != nil {
+		t.Errorf("DeleteQuote: %v", err)
+	}
+	if err := repo.DeleteQuote("new-quote", edit); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("second delete: expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestRepositoryWritesOtherEntities(t *testing.T) {
+	repo := db.NewRepository(db.NewQueries(seededDB(t)))
+
+	if err := repo.CreatePhilosophy(models.Philosophy{ID: "cynic-2", Name: "Neo-Cynicism", RelatedIDs: []string{"stoic"}}, edit); err != nil {
+		t.Fatalf("CreatePhilosophy: %v", err)
+	}
+	if err := repo.CreatePhilosopher(models.Philosopher{ID: "crates", Name: "Crates", PhilosophyID: "cynic-2", KeyTeachings: []string{"Poverty as freedom"}}, edit); err != nil {
+		t.Fatalf("CreatePhilosopher: %v", err)
+	}
+	if err := repo.CreateTheme(models.Theme{ID: "freedom", Name: "Freedom", PhilosophyIDs: []string{"cynic-2", "stoic"}}, edit); err != nil {
+		t.Fatalf("CreateTheme: %v", err)
+	}
+	if err := repo.CreateEvidence(models.Evidence{ID: "autonomy", Title: "Autonomy and wellbeing", Field: "psychology", ThemeIDs: []string{"freedom"}}, edit); err != nil {
+		t.Fatalf("CreateEvidence: %v", err)
+	}
+	if err := repo.CreatePhilosophy(models.Philosophy{ID: "loop", Name: "Loop", RelatedIDs: []string{"loop"}}, edit); !errors.Is(err, store.ErrInvalid) {
+		t.Errorf("self-related school: expected ErrInvalid, got %v", err)
+	}
+
+	if err := repo.DeletePhilosophy("cynic-2", edit); err != nil {
+		t.Fatalf("DeletePhilosophy: %v", err)
+	}
+	p, _ := repo.GetPhilosopher("crates")
+	th, _ := repo.GetTheme("freedom")
+	if p.PhilosophyID != "" || len(th.PhilosophyIDs) != 1 {
+		t.Errorf("school references not cleared: %+v %+v", p, th)
+	}
+	if err := repo.UpdateEvidence(models.Evidence{ID: "autonomy", Title: "Autonomy", Strength: "strong"}, edit); err != nil {
+		t.Fatalf("UpdateEvidence: %v", err)
+	}
+	if e, _ := repo.GetEvidence("autonomy"); e.Strength != "strong" || len(e.ThemeIDs) != 0 {
+		t.Errorf("evidence not replaced: %+v", e)
+	}
+}
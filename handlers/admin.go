@@ -0,0 +1,789 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"perennial-wisdom/models"
+	"perennial-wisdom/store"
+)
+
+// Admin serves the editorial UI under /admin. It renders through the same
+// "base" template as the public pages and saves through store.Writer, so
+// the forms enforce exactly the checks the write API does.
+type Admin struct {
+	pages *Pages
+	repo  store.ReadWriter
+	wf    store.Workflow
+}
+
+// NewAdmin creates the admin UI with explicit dependencies.
+func NewAdmin(repo store.ReadWriter, tmpl *template.Template, wf store.Workflow) *Admin {
+	return &Admin{pages: NewPages(repo, tmpl, store.Daily{}, nil), repo: repo, wf: wf}
+}
+
+// formField is one input on an admin form. Kind picks the widget:
+// "text", "textarea", "lines" (a textarea holding one item per line),
+// "select" (one of Options) or "picker" (any of Options, as checkboxes).
+type formField struct {
+	Name     string
+	Label    string
+	Kind     string
+	Value    string
+	Options  []option
+	Rows     int
+	Readonly bool
+	Error    string
+}
+
+type option struct {
+	Value    string
+	Label    string
+	Selected bool
+}
+
+func textField(name, label, value string) formField {
+	return formField{Name: name, Label: label, Kind: "text", Value: value}
+}
+
+func areaField(name, label, value string, rows int) formField {
+	return formField{Name: name, Label: label, Kind: "textarea", Value: value, Rows: rows}
+}
+
+func linesField(name, label string, values []string) formField {
+	return formField{Name: name, Label: label, Kind: "lines", Value: strings.Join(values, "\n"), Rows: max(len(values)+1, 3)}
+}
+
+func selectField(name, label string, opts []option) formField {
+	return formField{Name: name, Label: label, Kind: "select", Options: opts}
+}
+
+func pickerField(name, label string, opts []option) formField {
+	return formField{Name: name, Label: label, Kind: "picker", Options: opts}
+}
+
+// options lists every entity of kind as choices, marking those selected.
+// A failed lookup only leaves the picker empty; saving will then report
+// any references it can't resolve.
+func (a *Admin) options(kind string, selected ...string) []option {
+	var opts []option
+	add := func(id, label string) {
+		opts = append(opts, option{Value: id, Label: label, Selected: contains(selected, id)})
+	}
+	var err error
+	switch kind {
+	case "philosopher":
+		var ps []models.Philosopher
+		ps, _, err = a.repo.ListPhilosophers(nil, store.Page{})
+		for _, p := range ps {
+			add(p.ID, p.Name)
+		}
+	case "philosophy":
+		var ps []models.Philosophy
+		ps, _, err = a.repo.ListPhilosophies(store.Page{})
+		for _, p := range ps {
+			add(p.ID, p.Name)
+		}
+	case "theme":
+		var ts []models.Theme
+		ts, _, err = a.repo.ListThemes(store.Page{})
+		for _, t := range ts {
+			add(t.ID, t.Name)
+		}
+	case "evidence":
+		var es []models.Evidence
+		es, _, err = a.repo.ListEvidence(nil, store.Page{})
+		for _, e := range es {
+			add(e.ID, e.Title)
+		}
+	}
+	if err != nil {
+		log.Printf("admin: %s options: %v", kind, err)
+	}
+	return opts
+}
+
+// formValue reads a trimmed form value with browser line endings
+// normalised.
+func formValue(form url.Values, name string) string {
+	return strings.TrimSpace(strings.ReplaceAll(form.Get(name), "\r\n", "\n"))
+}
+
+// formLines splits a "lines" field into its non-empty lines.
+func formLines(form url.Values, name string) []string {
+	var out []string
+	for _, line := range strings.Split(formValue(form, name), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+// AdminResource serves list, create, edit and delete screens for one kind
+// of entity. Like WriteHandler, the per-kind parts are method values and
+// two functions mapping the entity to and from form fields.
+//
+//	GET  /admin/<kind>             list
+//	GET  /admin/<kind>/new         empty form
+//	POST /admin/<kind>             create; 303 to the edit form
+//	GET  /admin/<kind>/:id         edit form
+//	POST /admin/<kind>/:id         save; 303 back to the edit form
+//	POST /admin/<kind>/:id/delete  delete; 303 to the list
+//
+//	GET  /admin/<kind>/:id/revisions              history
+//	GET  /admin/<kind>/:id/revisions/:n           what revision n changed
+//	POST /admin/<kind>/:id/revisions/:n/rollback  restore revision n
+//
+// Validation errors re-render the form with 422 and each message next to
+// its field.
+type AdminResource[T any] struct {
+	admin   *Admin
+	kind    string // revision history kind, e.g. "philosophy"
+	name    string // singular noun, e.g. "tradition"
+	title   string // list heading, e.g. "Quotes"
+	path    string // e.g. "/admin/quotes"
+	preview string // live preview endpoint, if any
+	id      func(*T) *string
+	label   func(T) string
+	list    func(store.Page) ([]T, store.Cursors, error)
+	get     func(id string) (T, error)
+	create  func(T, store.Change) error
+	update  func(T, store.Change) error
+	delete  func(id string, ch store.Change) error
+	fields  func(T) []formField
+	parse   func(url.Values, *T)
+
+	// Quotes only: the list by status, whatever it is — ?status= picks
+	// one — the status shown in lists, and the workflow panel on the
+	// edit form.
+	byStatus func(statuses []string, p store.Page) ([]T, store.Cursors, error)
+	badge    func(T) string
+	workflow func(T) *workflowPanel
+}
+
+// Quotes returns the admin screens for quotes.
+func (a *Admin) Quotes() *AdminResource[models.Quote] {
+	return &AdminResource[models.Quote]{
+		admin: a, kind: "quote", name: "quote", title: "Quotes", path: "/admin/quotes", preview: "/admin/quotes/preview",
+		id: func(q *models.Quote) *string { return &q.ID },
+		label: func(q models.Quote) string {
+			if q.Title != "" {
+				return q.Title
+			}
+			return q.Text
+		},
+		byStatus: func(statuses []string, p store.Page) ([]models.Quote, store.Cursors, error) {
+			return a.repo.ListQuotes(store.Where("status", statuses...), p)
+		},
+		get: a.repo.GetQuote, create: a.repo.CreateQuote, delete: a.repo.DeleteQuote,
+		update: func(q models.Quote, ch store.Change) error { return a.wf.Edit(a.repo, q, ch) },
+		fields: func(q models.Quote) []formField {
+			return []formField{
+				textField("id", "ID", q.ID),
+				textField("slug", "Slug", q.Slug),
+				textField("title", "Title", q.Title),
+				areaField("text", "Text", q.Text, 3),
+				areaField("text_scholarly", "Scholarly translation", q.TextScholarly, 3),
+				areaField("original_script", "Original script", q.OriginalScript, 2),
+				selectField("philosopher_id", "Philosopher", a.options("philosopher", q.PhilosopherID)),
+				selectField("philosophy_id", "School", a.options("philosophy", q.PhilosophyID)),
+				textField("source", "Source", q.Source),
+				textField("source_location", "Source location", q.SourceLocation),
+				areaField("exposition_brief", "Exposition — brief", q.ExpositionBrief, 2),
+				areaField("exposition_standard", "Exposition — standard", q.ExpositionStandard, 5),
+				areaField("exposition_scholarly", "Exposition — scholarly", q.ExpositionScholarly, 8),
+				areaField("reflection_prompt", "Reflection prompt", q.ReflectionPrompt, 2),
+				areaField("modern_reinterpretation", "Modern reinterpretation", q.ModernReinterpretation, 4),
+				pickerField("theme_ids", "Themes", a.options("theme", q.ThemeIDs...)),
+				pickerField("evidence_ids", "Evidence", a.options("evidence", q.EvidenceIDs...)),
+			}
+		},
+		parse:    parseQuoteForm,
+		badge:    func(q models.Quote) string { return store.EffectiveStatus(q, time.Now()) },
+		workflow: a.workflowPanel,
+	}
+}
+
+// workflowPanel is the status box on the quote form: where the quote
+// stands and the moves it can make from there.
+type workflowPanel struct {
+	Action    string // POST target
+	Status    string
+	PublishAt *time.Time
+	Moves     []string
+}
+
+func (a *Admin) workflowPanel(q models.Quote) *workflowPanel {
+	return &workflowPanel{
+		Action:    "/admin/quotes/" + url.PathEscape(q.ID) + "/status",
+		Status:    store.EffectiveStatus(q, time.Now()),
+		PublishAt: q.PublishAt,
+		Moves:     a.wf.Moves(q),
+	}
+}
+
+// QuoteStatus moves a quote through the workflow from the buttons on its
+// form. publish_at comes from a datetime-local input, read in the
+// curator's time zone: tz, which the form fills in from the browser, or
+// UTC without it.
+func (a *Admin) QuoteStatus(c *gin.Context) {
+	id := c.Param("id")
+	quotes := a.Quotes()
+	var publishAt *time.Time
+	var err error
+	if raw := c.PostForm("publish_at"); raw != "" {
+		invalid := func(msg string) error {
+			return &store.ValidationError{Problems: []store.Problem{{Field: "publish_at", Message: msg}}}
+		}
+		if loc, tzErr := time.LoadLocation(c.PostForm("tz")); tzErr != nil {
+			err = invalid(fmt.Sprintf("unknown time zone %q", c.PostForm("tz")))
+		} else if t, parseErr := time.ParseInLocation("2006-01-02T15:04", raw, loc); parseErr != nil {
+			err = invalid("expected a date and time")
+		} else {
+			publishAt = &t
+		}
+	}
+	if err == nil {
+		err = a.wf.Transition(a.repo, id, c.PostForm("to"), publishAt, changeOf(c, c.PostForm("change_note")))
+	}
+	if err != nil {
+		q, getErr := a.repo.GetQuote(id)
+		if getErr != nil {
+			a.pages.notFound(c, "quote", getErr)
+			return
+		}
+		quotes.failed(c, q, false, err)
+		return
+	}
+	c.Redirect(http.StatusSeeOther, quotes.path+"/"+url.PathEscape(id)+"?saved=1")
+}
+
+// Review lists the quotes awaiting a decision: in review, or published
+// on a schedule that hasn't arrived yet.
+func (a *Admin) Review(c *gin.Context) {
+	page, ok := a.pages.page(c)
+	if !ok {
+		return
+	}
+	quotes := a.Quotes()
+	items, cur, err := a.repo.ListQuotes(store.Where("status", store.StatusInReview, store.StatusScheduled), page)
+	if err != nil {
+		log.Printf("admin review: %v", err)
+		c.String(http.StatusInternalServerError, "internal error")
+		return
+	}
+	rows := make([]adminItem, len(items))
+	for i, q := range items {
+		rows[i] = adminItem{ID: q.ID, Label: quotes.label(q), Badge: quotes.badge(q), PublishAt: q.PublishAt}
+	}
+	a.pages.render(c, http.StatusOK, gin.H{
+		"Page":  "admin-review",
+		"Title": "Review queue — Admin",
+		"Path":  quotes.path,
+		"Items": rows,
+		"Next":  pageURL(c, cur.Next),
+		"Prev":  pageURL(c, cur.Prev),
+	})
+}
+
+// parseQuoteForm applies quote form values to q. Meta has no form field
+// and is left as stored.
+func parseQuoteForm(form url.Values, q *models.Quote) {
+	q.ID = formValue(form, "id")
+	q.Slug = formValue(form, "slug")
+	q.Title = formValue(form, "title")
+	q.Text = formValue(form, "text")
+	q.TextScholarly = formValue(form, "text_scholarly")
+	q.OriginalScript = formValue(form, "original_script")
+	q.PhilosopherID = formValue(form, "philosopher_id")
+	q.PhilosophyID = formValue(form, "philosophy_id")
+	q.Source = formValue(form, "source")
+	q.SourceLocation = formValue(form, "source_location")
+	q.ExpositionBrief = formValue(form, "exposition_brief")
+	q.ExpositionStandard = formValue(form, "exposition_standard")
+	q.ExpositionScholarly = formValue(form, "exposition_scholarly")
+	q.ReflectionPrompt = formValue(form, "reflection_prompt")
+	q.ModernReinterpretation = formValue(form, "modern_reinterpretation")
+	q.ThemeIDs = form["theme_ids"]
+	q.EvidenceIDs = form["evidence_ids"]
+}
+
+// Philosophers returns the admin screens for philosophers.
+func (a *Admin) Philosophers() *AdminResource[models.Philosopher] {
+	return &AdminResource[models.Philosopher]{
+		admin: a, kind: "philosopher", name: "philosopher", title: "Philosophers", path: "/admin/philosophers",
+		id:    func(p *models.Philosopher) *string { return &p.ID },
+		label: func(p models.Philosopher) string { return p.Name },
+		list: func(p store.Page) ([]models.Philosopher, store.Cursors, error) {
+			return a.repo.ListPhilosophers(nil, p)
+		},
+		get: a.repo.GetPhilosopher, create: a.repo.CreatePhilosopher, update: a.repo.UpdatePhilosopher, delete: a.repo.DeletePhilosopher,
+		fields: func(p models.Philosopher) []formField {
+			return []formField{
+				textField("id", "ID", p.ID),
+				textField("name", "Name", p.Name),
+				selectField("philosophy_id", "School", a.options("philosophy", p.PhilosophyID)),
+				textField("era", "Era", p.Era),
+				areaField("bio", "Biography", p.Bio, 5),
+				linesField("key_teachings", "Key teachings (one per line)", p.KeyTeachings),
+			}
+		},
+		parse: func(form url.Values, p *models.Philosopher) {
+			p.ID = formValue(form, "id")
+			p.Name = formValue(form, "name")
+			p.PhilosophyID = formValue(form, "philosophy_id")
+			p.Era = formValue(form, "era")
+			p.Bio = formValue(form, "bio")
+			p.KeyTeachings = formLines(form, "key_teachings")
+		},
+	}
+}
+
+// Philosophies returns the admin screens for traditions (schools).
+func (a *Admin) Philosophies() *AdminResource[models.Philosophy] {
+	return &AdminResource[models.Philosophy]{
+		admin: a, kind: "philosophy", name: "tradition", title: "Traditions", path: "/admin/philosophies",
+		id:    func(p *models.Philosophy) *string { return &p.ID },
+		label: func(p models.Philosophy) string { return p.Name },
+		list:  a.repo.ListPhilosophies,
+		get:   a.repo.GetPhilosophy, create: a.repo.CreatePhilosophy, update: a.repo.UpdatePhilosophy, delete: a.repo.DeletePhilosophy,
+		fields: func(p models.Philosophy) []formField {
+			return []formField{
+				textField("id", "ID", p.ID),
+				textField("name", "Name", p.Name),
+				textField("origin", "Origin", p.Origin),
+				linesField("core_principles", "Core principles (one per line)", p.CorePrinciples),
+				pickerField("related_ids", "Related traditions", a.options("philosophy", p.RelatedIDs...)),
+			}
+		},
+		parse: func(form url.Values, p *models.Philosophy) {
+			p.ID = formValue(form, "id")
+			p.Name = formValue(form, "name")
+			p.Origin = formValue(form, "origin")
+			p.CorePrinciples = formLines(form, "core_principles")
+			p.RelatedIDs = form["related_ids"]
+		},
+	}
+}
+
+// Themes returns the admin screens for themes.
+func (a *Admin) Themes() *AdminResource[models.Theme] {
+	return &AdminResource[models.Theme]{
+		admin: a, kind: "theme", name: "theme", title: "Themes", path: "/admin/themes",
+		id:    func(t *models.Theme) *string { return &t.ID },
+		label: func(t models.Theme) string { return t.Name },
+		list:  a.repo.ListThemes,
+		get:   a.repo.GetTheme, create: a.repo.CreateTheme, update: a.repo.UpdateTheme, delete: a.repo.DeleteTheme,
+		fields: func(t models.Theme) []formField {
+			return []formField{
+				textField("id", "ID", t.ID),
+				textField("name", "Name", t.Name),
+				areaField("description", "Description", t.Description, 4),
+				pickerField("philosophy_ids", "Traditions", a.options("philosophy", t.PhilosophyIDs...)),
+			}
+		},
+		parse: func(form url.Values, t *models.Theme) {
+			t.ID = formValue(form, "id")
+			t.Name = formValue(form, "name")
+			t.Description = formValue(form, "description")
+			t.PhilosophyIDs = form["philosophy_ids"]
+		},
+	}
+}
+
+// Evidence returns the admin screens for evidence.
+func (a *Admin) Evidence() *AdminResource[models.Evidence] {
+	return &AdminResource[models.Evidence]{
+		admin: a, kind: "evidence", name: "evidence", title: "Evidence", path: "/admin/evidence",
+		id:    func(e *models.Evidence) *string { return &e.ID },
+		label: func(e models.Evidence) string { return e.Title },
+		list:  func(p store.Page) ([]models.Evidence, store.Cursors, error) { return a.repo.ListEvidence(nil, p) },
+		get:   a.repo.GetEvidence, create: a.repo.CreateEvidence, update: a.repo.UpdateEvidence, delete: a.repo.DeleteEvidence,
+		fields: func(e models.Evidence) []formField {
+			return []formField{
+				textField("id", "ID", e.ID),
+				textField("title", "Title", e.Title),
+				areaField("finding", "Finding", e.Finding, 4),
+				textField("field", "Field", e.Field),
+				textField("source", "Source", e.Source),
+				textField("evidence_strength", "Strength", e.Strength),
+				pickerField("theme_ids", "Themes", a.options("theme", e.ThemeIDs...)),
+			}
+		},
+		parse: func(form url.Values, e *models.Evidence) {
+			e.ID = formValue(form, "id")
+			e.Title = formValue(form, "title")
+			e.Finding = formValue(form, "finding")
+			e.Field = formValue(form, "field")
+			e.Source = formValue(form, "source")
+			e.Strength = formValue(form, "evidence_strength")
+			e.ThemeIDs = form["theme_ids"]
+		},
+	}
+}
+
+// adminSection is one entry on the dashboard.
+type adminSection struct {
+	Title string
+	Path  string
+}
+
+// Dashboard renders the admin landing page.
+func (a *Admin) Dashboard(c *gin.Context) {
+	queue, _, err := a.repo.ListQuotes(store.Where("status", store.StatusInReview, store.StatusScheduled), store.Page{})
+	if err != nil {
+		log.Printf("admin review queue: %v", err)
+	}
+	a.pages.render(c, http.StatusOK, gin.H{
+		"Page":   "admin",
+		"Title":  "Admin",
+		"Review": len(queue),
+		"Sections": []adminSection{
+			{"Quotes", "/admin/quotes"},
+			{"Philosophers", "/admin/philosophers"},
+			{"Traditions", "/admin/philosophies"},
+			{"Themes", "/admin/themes"},
+			{"Evidence", "/admin/evidence"},
+		},
+	})
+}
+
+// QuotePreview renders the public quote card for the form's current
+// values, so editors see the quote as readers will while they type.
+func (a *Admin) QuotePreview(c *gin.Context) {
+	var q models.Quote
+	c.Request.ParseForm()
+	parseQuoteForm(c.Request.PostForm, &q)
+	var buf bytes.Buffer
+	if err := a.pages.tmpl.ExecuteTemplate(&buf, "quote-card", a.pages.quoteView(q)); err != nil {
+		log.Printf("template error: %v", err)
+		c.String(http.StatusInternalServerError, "template error: %v", err)
+		return
+	}
+	c.Data(http.StatusOK, "text/html; charset=utf-8", buf.Bytes())
+}
+
+// adminItem is one row of an admin list.
+type adminItem struct {
+	ID        string
+	Label     string
+	Badge     string
+	PublishAt *time.Time
+}
+
+// List renders one page of entities with links to their edit forms.
+func (r *AdminResource[T]) List(c *gin.Context) {
+	page, ok := r.admin.pages.page(c)
+	if !ok {
+		return
+	}
+	var items []T
+	var cur store.Cursors
+	var err error
+	var offered []string // the statuses the list can be narrowed to
+	status := c.Query("status")
+	if r.byStatus != nil {
+		offered = store.Statuses
+		statuses := store.Statuses
+		if status != "" {
+			if !slices.Contains(store.Statuses, status) {
+				c.String(http.StatusBadRequest, "unknown status %q", status)
+				return
+			}
+			statuses = []string{status}
+		}
+		items, cur, err = r.byStatus(statuses, page)
+	} else {
+		items, cur, err = r.list(page)
+	}
+	if err != nil {
+		log.Printf("admin %s list: %v", r.name, err)
+		c.String(http.StatusInternalServerError, "internal error")
+		return
+	}
+	rows := make([]adminItem, len(items))
+	for i, v := range items {
+		rows[i] = adminItem{ID: *r.id(&v), Label: r.label(v)}
+		if r.badge != nil {
+			rows[i].Badge = r.badge(v)
+		}
+	}
+	r.admin.pages.render(c, http.StatusOK, gin.H{
+		"Page":     "admin-list",
+		"Title":    r.title + " — Admin",
+		"Kind":     r.title,
+		"Name":     r.name,
+		"Path":     r.path,
+		"Items":    rows,
+		"Statuses": offered,
+		"Status":   status,
+		"Next":     pageURL(c, cur.Next),
+		"Prev":     pageURL(c, cur.Prev),
+	})
+}
+
+// New renders an empty form.
+func (r *AdminResource[T]) New(c *gin.Context) {
+	var v T
+	r.form(c, http.StatusOK, v, true, nil)
+}
+
+// Edit renders the form for a stored entity.
+func (r *AdminResource[T]) Edit(c *gin.Context) {
+	v, err := r.get(c.Param("id"))
+	if err != nil {
+		r.admin.pages.notFound(c, r.name, err)
+		return
+	}
+	r.form(c, http.StatusOK, v, false, nil)
+}
+
+// Create saves a new entity from the form.
+func (r *AdminResource[T]) Create(c *gin.Context) {
+	var v T
+	c.Request.ParseForm()
+	r.parse(c.Request.PostForm, &v)
+	if err := r.create(v, changeOf(c, c.PostForm("change_note"))); err != nil {
+		r.failed(c, v, true, err)
+		return
+	}
+	c.Redirect(http.StatusSeeOther, r.path+"/"+url.PathEscape(*r.id(&v))+"?saved=1")
+}
+
+// Update saves the form over a stored entity. Fields the form doesn't
+// cover (a quote's meta) keep their stored values; the id never changes.
+func (r *AdminResource[T]) Update(c *gin.Context) {
+	id := c.Param("id")
+	v, err := r.get(id)
+	if err != nil {
+		r.admin.pages.notFound(c, r.name, err)
+		return
+	}
+	c.Request.ParseForm()
+	r.parse(c.Request.PostForm, &v)
+	*r.id(&v) = id
+	if err := r.update(v, changeOf(c, c.PostForm("change_note"))); err != nil {
+		r.failed(c, v, false, err)
+		return
+	}
+	c.Redirect(http.StatusSeeOther, r.path+"/"+url.PathEscape(id)+"?saved=1")
+}
+
+// Delete removes an entity and returns to the list.
+func (r *AdminResource[T]) Delete(c *gin.Context) {
+	err := r.delete(c.Param("id"), changeOf(c, c.PostForm("change_note")))
+	switch {
+	case err == nil:
+		c.Redirect(http.StatusSeeOther, r.path)
+	case errors.Is(err, store.ErrNotFound):
+		c.String(http.StatusNotFound, r.name+" not found")
+	default:
+		log.Printf("admin %s delete: %v", r.name, err)
+		c.String(http.StatusInternalServerError, "internal error")
+	}
+}
+
+// failed re-renders the form after a rejected save: 422 with inline
+// messages for validation errors, 409 for a taken id or slug.
+func (r *AdminResource[T]) failed(c *gin.Context, v T, isNew bool, err error) {
+	switch {
+	case errors.Is(err, store.ErrInvalid):
+		r.form(c, http.StatusUnprocessableEntity, v, isNew, err)
+	case errors.Is(err, store.ErrConflict):
+		r.form(c, http.StatusConflict, v, isNew, err)
+	case errors.Is(err, store.ErrNotFound):
+		r.admin.pages.notFound(c, r.name, err)
+	default:
+		log.Printf("admin %s save: %v", r.name, err)
+		c.String(http.StatusInternalServerError, "internal error")
+	}
+}
+
+// form renders the edit form for v. Validation problems are attached to
+// their fields; anything that doesn't belong to a field is shown above
+// the form.
+func (r *AdminResource[T]) form(c *gin.Context, status int, v T, isNew bool, err error) {
+	fields := r.fields(v)
+	var message string
+	if err != nil {
+		message = err.Error()
+		var verr *store.ValidationError
+		if errors.As(err, &verr) {
+			message = ""
+			problems := verr.Fields()
+			for i := range fields {
+				if msg, ok := problems[fields[i].Name]; ok {
+					fields[i].Error = msg
+					delete(problems, fields[i].Name)
+				}
+			}
+			for _, field := range slices.Sorted(maps.Keys(problems)) {
+				message += field + ": " + problems[field] + ". "
+			}
+		}
+	}
+	if !isNew {
+		fields[0].Readonly = true // the id
+	}
+
+	id := *r.id(&v)
+	action, title := r.path, "New "+r.name
+	var panel *workflowPanel
+	if !isNew {
+		action, title = r.path+"/"+url.PathEscape(id), "Edit "+r.name
+		if r.workflow != nil {
+			panel = r.workflow(v)
+		}
+	}
+	r.admin.pages.render(c, status, gin.H{
+		"Page":     "admin-form",
+		"Title":    title + " — Admin",
+		"Heading":  title,
+		"Kind":     r.title,
+		"Path":     r.path,
+		"Action":   action,
+		"IsNew":    isNew,
+		"Saved":    c.Query("saved") != "",
+		"Error":    strings.TrimSpace(message),
+		"Fields":   fields,
+		"Note":     c.PostForm("change_note"),
+		"Preview":  r.preview,
+		"Workflow": panel,
+	})
+}
+
+// Revisions lists an entity's revision history.
+func (r *AdminResource[T]) Revisions(c *gin.Context) {
+	id := c.Param("id")
+	revs, err := r.admin.repo.ListRevisions(r.kind, id)
+	if err != nil {
+		log.Printf("admin %s revisions: %v", r.name, err)
+		c.String(http.StatusInternalServerError, "internal error")
+		return
+	}
+	if len(revs) == 0 {
+		if _, err := r.get(id); err != nil {
+			r.admin.pages.notFound(c, r.name, err)
+			return
+		}
+	}
+	r.admin.pages.render(c, http.StatusOK, gin.H{
+		"Page":      "admin-revisions",
+		"Title":     "History — Admin",
+		"Kind":      r.title,
+		"Path":      r.path,
+		"ID":        id,
+		"Revisions": revs,
+	})
+}
+
+// Revision shows what one revision changed, with a form to restore it.
+func (r *AdminResource[T]) Revision(c *gin.Context) {
+	r.revision(c, http.StatusOK, "")
+}
+
+// Rollback restores the entity to a revision. Restoring a delete
+// revision deletes the entity and returns to the list.
+func (r *AdminResource[T]) Rollback(c *gin.Context) {
+	n, ok := revisionNumber(c)
+	if !ok {
+		c.String(http.StatusNotFound, "revision not found")
+		return
+	}
+	id := c.Param("id")
+	err := store.Rollback(r.admin.repo, r.kind, id, n, changeOf(c, c.PostForm("change_note")))
+	switch {
+	case err == nil:
+	case errors.Is(err, store.ErrInvalid):
+		r.revision(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	case errors.Is(err, store.ErrConflict):
+		r.revision(c, http.StatusConflict, err.Error())
+		return
+	case errors.Is(err, store.ErrNotFound):
+		c.String(http.StatusNotFound, "revision not found")
+		return
+	default:
+		log.Printf("admin %s rollback: %v", r.name, err)
+		c.String(http.StatusInternalServerError, "internal error")
+		return
+	}
+	if _, err := r.get(id); err != nil {
+		c.Redirect(http.StatusSeeOther, r.path)
+		return
+	}
+	c.Redirect(http.StatusSeeOther, r.path+"/"+url.PathEscape(id)+"?saved=1")
+}
+
+// fieldChange is a store.FieldChange formatted for display.
+type fieldChange struct {
+	Field  string
+	Before string
+	After  string
+}
+
+func (r *AdminResource[T]) revision(c *gin.Context, status int, message string) {
+	n, ok := revisionNumber(c)
+	if !ok {
+		c.String(http.StatusNotFound, "revision not found")
+		return
+	}
+	rev, err := r.admin.repo.GetRevision(r.kind, c.Param("id"), n)
+	if err != nil {
+		r.admin.pages.notFound(c, "revision", err)
+		return
+	}
+	diff, err := store.DiffRevision(r.admin.repo, rev)
+	if err != nil {
+		log.Printf("admin %s revision diff: %v", r.name, err)
+		c.String(http.StatusInternalServerError, "internal error")
+		return
+	}
+	changes := make([]fieldChange, len(diff))
+	for i, d := range diff {
+		changes[i] = fieldChange{Field: d.Field, Before: displayValue(d.Before), After: displayValue(d.After)}
+	}
+	r.admin.pages.render(c, status, gin.H{
+		"Page":     "admin-revision",
+		"Title":    "Revision " + strconv.Itoa(n) + " — Admin",
+		"Kind":     r.title,
+		"Path":     r.path,
+		"ID":       rev.EntityID,
+		"Revision": rev,
+		"Changes":  changes,
+		"Error":    message,
+	})
+}
+
+// displayValue renders a snapshot value: text as is, lists one item per
+// line, anything else as JSON.
+func displayValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []any:
+		lines := make([]string, len(v))
+		for i, item := range v {
+			lines[i] = displayValue(item)
+		}
+		return strings.Join(lines, "\n")
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
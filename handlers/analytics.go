@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"perennial-wisdom/store"
+)
+
+// AnalyticsHandler serves statistics about the corpus for editors.
+type AnalyticsHandler struct {
+	repo store.Repository
+}
+
+// NewAnalyticsHandler creates an AnalyticsHandler with explicit repository dependency.
+func NewAnalyticsHandler(repo store.Repository) *AnalyticsHandler {
+	return &AnalyticsHandler{repo: repo}
+}
+
+// Cooccurrence returns the theme × tradition, theme × theme and
+// tradition × tradition matrices of the live quotes, each cell a count
+// with its PMI and Jaccard scores.
+func (h *AnalyticsHandler) Cooccurrence(c *gin.Context) {
+	co, err := store.Cooccur(h.repo)
+	if err != nil {
+		serverError(c, "AnalyticsHandler.Cooccurrence", err)
+		return
+	}
+	c.JSON(http.StatusOK, co)
+}
+
+// heatmapView is a co-occurrence matrix as the heatmap page draws it.
+type heatmapView struct {
+	Title, Note string
+	Cols        []store.CoLabel
+	Rows        []heatmapRow
+}
+
+// heatmapRow is one row label and its cells.
+type heatmapRow struct {
+	store.CoLabel
+	Cells []heatmapCell
+}
+
+// heatmapCell is one cell: shaded by its Jaccard score, flagged when a
+// single item is all that links the pair.
+type heatmapCell struct {
+	store.CoCell
+	Heat   float64 // fill opacity, 0 for an empty cell
+	Single bool
+	Tip    string
+	Link   string // quotes that make up the cell, if there's a page of them
+}
+
+// Cooccurrence renders the co-occurrence matrices as heatmaps.
+func (p *Pages) Cooccurrence(c *gin.Context) {
+	co, err := store.Cooccur(p.repo)
+	if err != nil {
+		log.Printf("Cooccurrence: Cooccur error: %v", err)
+		c.String(http.StatusInternalServerError, "internal error")
+		return
+	}
+	quotesOf := func(theme, tradition string) string {
+		q := url.Values{"theme": {theme}}
+		if tradition != "" {
+			q.Set("tradition", tradition)
+		}
+		return "/pages/quotes?" + q.Encode()
+	}
+	p.render(c, http.StatusOK, gin.H{
+		"Page":   "cooccurrence",
+		"Title":  "Co-occurrence",
+		"Quotes": co.Quotes,
+		"Maps": []heatmapView{
+			heatmap("Themes × traditions", "Quotes on each theme from each tradition.", co.ThemeTradition,
+				func(r, c store.CoLabel) string { return quotesOf(r.ID, c.ID) }),
+			heatmap("Traditions × traditions", "Themes both traditions have quotes on.", co.TraditionTradition, nil),
+			heatmap("Themes × themes", "Quotes carrying both themes.", co.ThemeTheme, nil),
+		},
+	})
+}
+
+// heatmap lays a matrix out for the page; link, if not nil, gives the
+// page of a non-empty cell's quotes.
+func heatmap(title, note string, m store.CoMatrix, link func(row, col store.CoLabel) string) heatmapView {
+	v := heatmapView{Title: title, Note: note, Cols: m.Cols}
+	for i, r := range m.Rows {
+		row := heatmapRow{CoLabel: r}
+		for j, cell := range m.Cells[i] {
+			col := m.Cols[j]
+			hc := heatmapCell{CoCell: cell, Single: cell.Count == 1, Tip: fmt.Sprintf("%s × %s: none", r.Name, col.Name)}
+			if cell.Count > 0 {
+				hc.Heat = math.Round((0.15+0.85*cell.Jaccard)*100) / 100
+				items := m.Universe
+				if cell.Count == 1 {
+					items = strings.TrimSuffix(items, "s")
+				}
+				hc.Tip = fmt.Sprintf("%s × %s: %d %s, PMI %.2f, Jaccard %.2f", r.Name, col.Name, cell.Count, items, *cell.PMI, cell.Jaccard)
+				if link != nil {
+					hc.Link = link(r, col)
+				}
+			}
+			row.Cells = append(row.Cells, hc)
+		}
+		v.Rows = append(v.Rows, row)
+	}
+	return v
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"perennial-wisdom/store"
+)
+
+// Curators are the people who may write, each with their own token:
+// name → token. A request's author is the curator whose token it
+// carries, never a name the client supplies, so the revision history —
+// and the workflow's second-approver rule, which reads it — can trust
+// who made each change.
+type Curators map[string]string
+
+// ParseCurators reads curators written as "name:token", separated by
+// commas, e.g. "ana:s3cret, ben:0ther".
+func ParseCurators(s string) (Curators, error) {
+	cs := Curators{}
+	tokens := map[string]bool{}
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		name, token, ok := strings.Cut(item, ":")
+		name, token = strings.TrimSpace(name), strings.TrimSpace(token)
+		if !ok || name == "" || token == "" {
+			return nil, fmt.Errorf("curator %q: want name:token", item)
+		}
+		if _, dup := cs[name]; dup || tokens[token] {
+			return nil, fmt.Errorf("curator %q: each curator needs their own name and token", name)
+		}
+		cs[name] = token
+		tokens[token] = true
+	}
+	return cs, nil
+}
+
+// curator returns the name of the curator whose token got is. Every
+// token is compared, in constant time, so timing reveals none of them.
+func (cs Curators) curator(got string) (string, bool) {
+	var name string
+	for n, token := range cs {
+		if subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1 {
+			name = n
+		}
+	}
+	return name, name != ""
+}
+
+// RequireToken guards the write API with the curators' tokens, sent as
+// "Authorization: Bearer <token>". Browsers never send a bearer token on
+// their own, so other sites can't ride on a curator's admin login to
+// reach the API. Without curators it refuses every request.
+func RequireToken(curators Curators) gin.HandlerFunc {
+	return requireToken(curators, false)
+}
+
+// RequireLogin guards the admin UI like RequireToken, but also lets
+// browsers in: they get a Basic auth prompt and use their token as the
+// password; the username is ignored. Pair it with SameOrigin, as
+// browsers replay Basic credentials to any request for the site.
+func RequireLogin(curators Curators) gin.HandlerFunc {
+	return requireToken(curators, true)
+}
+
+func requireToken(curators Curators, basic bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if len(curators) == 0 {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "writes are disabled: no ADMIN_TOKENS are set"})
+			return
+		}
+		got, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok && basic {
+			_, got, ok = c.Request.BasicAuth()
+		}
+		author, known := curators.curator(got)
+		if !ok || !known {
+			c.Writer.Header().Add("WWW-Authenticate", `Bearer realm="perennial-wisdom"`)
+			if basic {
+				c.Writer.Header().Add("WWW-Authenticate", `Basic realm="perennial-wisdom admin"`)
+			}
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+		c.Set(authorKey, author)
+		c.Next()
+	}
+}
+
+// authorKey is where RequireToken leaves the request's author.
+const authorKey = "author"
+
+// changeOf describes a request's writes for the revision history.
+func changeOf(c *gin.Context, note string) store.Change {
+	author := c.GetString(authorKey)
+	if author == "" {
+		author = "anonymous"
+	}
+	return store.Change{Author: author, Note: strings.TrimSpace(note)}
+}
+
+// SameOrigin rejects state-changing requests sent from other sites.
+// Browsers replay Basic credentials automatically, so without it any page
+// could submit the admin forms, or call the write API, on a curator's
+// behalf.
+func SameOrigin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+		if c.GetHeader("Sec-Fetch-Site") == "cross-site" {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+		if origin := c.GetHeader("Origin"); origin != "" {
+			if u, err := url.Parse(origin); err != nil || u.Host != c.Request.Host {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+		}
+		c.Next()
+	}
+}
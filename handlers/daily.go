@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"perennial-wisdom/store"
+)
+
+// DailyHandler serves the quote of the day.
+type DailyHandler struct {
+	repo  store.Repository
+	daily store.Daily
+}
+
+// NewDailyHandler creates a DailyHandler with explicit dependencies.
+func NewDailyHandler(repo store.Repository, daily store.Daily) *DailyHandler {
+	return &DailyHandler{repo: repo, daily: daily}
+}
+
+// Today returns the quote of the day:
+//
+//	GET /api/quotes/today                        today in UTC
+//	GET /api/quotes/today?tz=America/Vancouver   today where the reader is
+//	GET /api/quotes/today?date=2026-12-25        any day, e.g. to check a pin
+//
+// Every replica gives the same answer for the same day. Today's answer
+// may be cached until "next", the reader's midnight.
+func (h *DailyHandler) Today(c *gin.Context) {
+	loc, err := time.LoadLocation(c.DefaultQuery("tz", "UTC"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "tz must be an IANA time zone, like Europe/Paris"})
+		return
+	}
+	day := time.Now().In(loc)
+	if date := c.Query("date"); date != "" {
+		if day, err = time.ParseInLocation(time.DateOnly, date, loc); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "date must be YYYY-MM-DD"})
+			return
+		}
+	}
+
+	pick, err := h.daily.Pick(h.repo, day)
+	if errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no quotes available"})
+		return
+	}
+	if err != nil {
+		serverError(c, "DailyHandler.Today", err)
+		return
+	}
+
+	next := midnightAfter(day)
+	if c.Query("date") == "" {
+		c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(time.Until(next).Seconds())))
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"date":        pick.Date,
+		"timezone":    loc.String(),
+		"next":        next,
+		"pinned":      pick.Pinned,
+		"window":      pick.Window,
+		"quote":       pick.Quote,
+		"philosopher": philosopherName(h.repo, pick.Quote.PhilosopherID),
+		"philosophy":  philosophyName(h.repo, pick.Quote.PhilosophyID),
+	})
+}
+
+// TodayPartial returns the home page's quote of the day block, for the
+// reader's ?tz= — which the page sends from the browser. An unknown
+// zone falls back to UTC rather than losing the block.
+func (p *Pages) TodayPartial(c *gin.Context) {
+	loc, err := time.LoadLocation(c.Query("tz"))
+	if err != nil {
+		loc = time.UTC
+	}
+	day := time.Now().In(loc)
+	pick, err := p.daily.Pick(p.repo, day)
+	if err != nil {
+		p.notFound(c, "quote of the day", err)
+		return
+	}
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	p.tmpl.ExecuteTemplate(c.Writer, "today-quote", gin.H{
+		"Quote":  p.quoteView(pick.Quote),
+		"Day":    day,
+		"Pinned": pick.Pinned,
+	})
+}
+
+// midnightAfter is the start of the day after t's, where t is.
+func midnightAfter(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, t.Location())
+}
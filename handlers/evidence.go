@@ -12,58 +12,64 @@ import (
 // EvidenceHandler serves neuroscience/neuropsychology evidence endpoints.
 // Bridges ancient contemplative insight with modern empirical findings.
 type EvidenceHandler struct {
-	store *store.Store
+	repo store.Repository
 }
 
-// NewEvidenceHandler creates an EvidenceHandler with explicit store dependency.
-func NewEvidenceHandler(s *store.Store) *EvidenceHandler {
-	return &EvidenceHandler{store: s}
+// NewEvidenceHandler creates an EvidenceHandler with explicit repository dependency.
+func NewEvidenceHandler(repo store.Repository) *EvidenceHandler {
+	return &EvidenceHandler{repo: repo}
 }
 
 // List returns all scientific evidence, optionally filtered by field:
 //   - ?field=neuroscience
 //   - ?field=neuropsychology
 //   - ?field=psychology
+//   - ?theme=control&theme_match=all, ?-field=psychology
+//
+// Paginated with ?limit= and ?cursor=.
 func (h *EvidenceHandler) List(c *gin.Context) {
-	field := c.Query("field")
-
-	var results []models.Evidence
-	for _, e := range h.store.Evidence {
-		if field != "" && e.Field != field {
-			continue
-		}
-		results = append(results, e)
+	page, ok := parsePage(c)
+	if !ok {
+		return
+	}
+	results, cur, err := h.repo.ListEvidence(store.ParseFilter(c.Request.URL.Query(), store.EvidenceDimensions...), page)
+	if err != nil {
+		serverError(c, "EvidenceHandler.List", err)
+		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"evidence": results, "count": len(results)})
+	c.JSON(http.StatusOK, listResponse(c, "evidence", results, len(results), cur))
 }
 
 // Get returns a single evidence entry by ID, with linked themes and quotes.
 func (h *EvidenceHandler) Get(c *gin.Context) {
 	id := c.Param("id")
-	e, ok := h.store.Evidence[id]
-	if !ok {
-		c.JSON(http.StatusNotFound, gin.H{"error": "evidence not found"})
+	e, err := h.repo.GetEvidence(id)
+	if err != nil {
+		lookupError(c, "evidence", err)
 		return
 	}
 
 	// Gather related themes
 	var themes []models.Theme
 	for _, tid := range e.ThemeIDs {
-		if t, ok := h.store.Themes[tid]; ok {
+		if t, err := h.repo.GetTheme(tid); err == nil {
 			themes = append(themes, t)
 		}
 	}
 
 	// Gather quotes that cite this evidence
+	citing, _, err := h.repo.ListQuotes(store.Where("evidence", id), store.Page{})
+	if err != nil {
+		serverError(c, "EvidenceHandler.Get", err)
+		return
+	}
 	var quotes []gin.H
-	for _, q := range h.store.Quotes {
-		if contains(q.EvidenceIDs, id) {
-			quotes = append(quotes, gin.H{
-				"quote":       q.Text,
-				"philosopher": h.store.Philosophers[q.PhilosopherID].Name,
-			})
-		}
+	for _, q := range citing {
+		quotes = append(quotes, gin.H{
+			"quote":       q.Text,
+			"philosopher": philosopherName(h.repo, q.PhilosopherID),
+		})
 	}
 
 	c.JSON(http.StatusOK, gin.H{
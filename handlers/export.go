@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"perennial-wisdom/store"
+)
+
+// ExportHandler streams the whole public corpus for downstream indexing.
+type ExportHandler struct {
+	repo store.Repository
+}
+
+// NewExportHandler creates an ExportHandler with explicit repository dependency.
+func NewExportHandler(repo store.Repository) *ExportHandler {
+	return &ExportHandler{repo: repo}
+}
+
+// exportTypes are the content types of the export formats.
+var exportTypes = map[string]string{
+	store.FormatNDJSON: "application/x-ndjson",
+	store.FormatJSONLD: "application/ld+json",
+}
+
+// Export streams every entity and relationship, a page at a time:
+//
+//	GET /api/export?format=ndjson   (default) one JSON object per line
+//	GET /api/export?format=jsonld   a schema.org JSON-LD document
+func (h *ExportHandler) Export(c *gin.Context) {
+	format := c.DefaultQuery("format", store.FormatNDJSON)
+	ctype, ok := exportTypes[format]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be ndjson or jsonld"})
+		return
+	}
+
+	c.Header("Content-Type", ctype+"; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := store.Export(h.repo, c.Writer, format, baseURL(c)); err != nil {
+		// The status line has gone out; all we can do is stop and log.
+		log.Printf("ExportHandler.Export: %v", err)
+	}
+}
+
+// baseURL is the scheme and host the request came in on, which JSON-LD
+// node IDs resolve against.
+func baseURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if p := c.GetHeader("X-Forwarded-Proto"); p != "" {
+		scheme = p
+	}
+	return scheme + "://" + c.Request.Host
+}
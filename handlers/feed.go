@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"perennial-wisdom/store"
+)
+
+// FeedHandler serves RSS and Atom feeds, for feed readers.
+type FeedHandler struct {
+	repo  store.Repository
+	daily store.Daily
+}
+
+// NewFeedHandler creates a FeedHandler with explicit dependencies.
+func NewFeedHandler(repo store.Repository, daily store.Daily) *FeedHandler {
+	return &FeedHandler{repo: repo, daily: daily}
+}
+
+// feedTypes are the content types of the feed formats, by file extension.
+var feedTypes = map[string]struct{ format, ctype string }{
+	".xml":  {store.FormatRSS, "application/rss+xml; charset=utf-8"},
+	".atom": {store.FormatAtom, "application/atom+xml; charset=utf-8"},
+}
+
+// Daily returns the quote of the day for the last few days, by the
+// calendar in UTC:
+//
+//	GET /feeds/daily.xml    RSS 2.0
+//	GET /feeds/daily.atom   Atom
+func (h *FeedHandler) Daily(c *gin.Context) {
+	base := baseURL(c)
+	entries, err := store.DailyFeed(h.repo, h.daily, time.Now(), base)
+	if err != nil {
+		serverError(c, "FeedHandler.Daily", err)
+		return
+	}
+	h.write(c, store.Feed{
+		Title:   "Perennial Wisdom: quote of the day",
+		About:   "A quote a day from the world's wisdom traditions, with its exposition.",
+		Link:    base + "/",
+		Entries: entries,
+	})
+}
+
+// NewQuotes returns the most recently published quotes:
+//
+//	GET /feeds/new-quotes.xml    RSS 2.0
+//	GET /feeds/new-quotes.atom   Atom
+func (h *FeedHandler) NewQuotes(c *gin.Context) {
+	base := baseURL(c)
+	entries, err := store.QuoteFeed(h.repo, nil, base)
+	if err != nil {
+		serverError(c, "FeedHandler.NewQuotes", err)
+		return
+	}
+	h.write(c, store.Feed{
+		Title:   "Perennial Wisdom: new quotes",
+		About:   "Quotes as they are added to Perennial Wisdom.",
+		Link:    base + "/pages/quotes",
+		Entries: entries,
+	})
+}
+
+// Theme returns the most recently published quotes on one theme:
+//
+//	GET /feeds/themes/death.xml    (or death.atom)
+func (h *FeedHandler) Theme(c *gin.Context) {
+	id := strings.TrimSuffix(c.Param("file"), path.Ext(c.Param("file")))
+	t, err := h.repo.GetTheme(id)
+	if err != nil {
+		lookupError(c, "theme", err)
+		return
+	}
+	base := baseURL(c)
+	entries, err := store.QuoteFeed(h.repo, store.Where("theme", t.ID), base)
+	if err != nil {
+		serverError(c, "FeedHandler.Theme", err)
+		return
+	}
+	h.write(c, store.Feed{
+		Title:   "Perennial Wisdom: " + t.Name,
+		About:   "New quotes on " + t.Name + ", from every tradition.",
+		Link:    base + "/pages/themes/" + t.ID,
+		Entries: entries,
+	})
+}
+
+// Tradition returns the most recently published quotes of one school:
+//
+//	GET /feeds/traditions/stoic.xml    (or stoic.atom)
+func (h *FeedHandler) Tradition(c *gin.Context) {
+	id := strings.TrimSuffix(c.Param("file"), path.Ext(c.Param("file")))
+	p, err := h.repo.GetPhilosophy(id)
+	if err != nil {
+		lookupError(c, "tradition", err)
+		return
+	}
+	base := baseURL(c)
+	entries, err := store.QuoteFeed(h.repo, store.Where("philosophy", p.ID), base)
+	if err != nil {
+		serverError(c, "FeedHandler.Tradition", err)
+		return
+	}
+	h.write(c, store.Feed{
+		Title:   "Perennial Wisdom: " + p.Name,
+		About:   "New quotes from " + p.Name + ".",
+		Link:    base + "/pages/philosophies/" + p.ID,
+		Entries: entries,
+	})
+}
+
+// write sends f in the format the request's extension names. A reader
+// that has the feed as of its last update gets 304 Not Modified.
+func (h *FeedHandler) write(c *gin.Context, f store.Feed) {
+	kind, ok := feedTypes[path.Ext(c.Request.URL.Path)]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "feeds end in .xml (RSS) or .atom (Atom)"})
+		return
+	}
+	f.Self = baseURL(c) + c.Request.URL.Path
+
+	updated := f.Updated().Truncate(time.Second)
+	if since, err := http.ParseTime(c.GetHeader("If-Modified-Since")); err == nil && !updated.After(since) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := store.WriteFeed(&buf, f, kind.format); err != nil {
+		serverError(c, "FeedHandler.write", err)
+		return
+	}
+	c.Header("Last-Modified", updated.UTC().Format(http.TimeFormat))
+	c.Header("Cache-Control", "public, max-age=900")
+	c.Data(http.StatusOK, kind.ctype, buf.Bytes())
+}
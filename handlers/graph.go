@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"perennial-wisdom/store"
+)
+
+// GraphHandler serves the corpus as a graph, for graph tools.
+type GraphHandler struct {
+	repo store.Repository
+}
+
+// NewGraphHandler creates a GraphHandler with explicit repository dependency.
+func NewGraphHandler(repo store.Repository) *GraphHandler {
+	return &GraphHandler{repo: repo}
+}
+
+// graphTypes are the content types of the graph formats.
+var graphTypes = map[string]string{
+	store.FormatGraphML:   "application/graphml+xml",
+	store.FormatDOT:       "text/vnd.graphviz",
+	store.FormatJSONGraph: "application/json",
+}
+
+// Graph returns the typed corpus graph, whole or around one node:
+//
+//	GET /api/graph?format=graphml        (or dot, or json — the default)
+//	GET /api/graph?root=stoic&depth=2    only what's within 2 links of Stoicism
+//
+// root is an entity ID, or kind:id where IDs clash; depth defaults to 1.
+func (h *GraphHandler) Graph(c *gin.Context) {
+	format := c.DefaultQuery("format", store.FormatJSONGraph)
+	ctype, ok := graphTypes[format]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be graphml, dot or json"})
+		return
+	}
+	depth, err := strconv.Atoi(c.DefaultQuery("depth", "1"))
+	if err != nil || depth < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "depth must be a non-negative integer"})
+		return
+	}
+
+	g, err := store.BuildGraph(h.repo)
+	if err != nil {
+		serverError(c, "GraphHandler.Graph", err)
+		return
+	}
+	if root := c.Query("root"); root != "" {
+		if g, err = g.Neighborhood(root, depth); err != nil {
+			lookupError(c, "root", err)
+			return
+		}
+	}
+
+	c.Header("Content-Type", ctype+"; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := store.WriteGraph(c.Writer, g, format); err != nil {
+		log.Printf("GraphHandler.Graph: %v", err)
+	}
+}
+
+// The graph explorer's canvas, in SVG user units, and how far a
+// neighborhood reaches at most.
+const (
+	graphWidth    = 960
+	graphHeight   = 640
+	maxGraphDepth = 4
+	graphLabel    = 28 // characters of a label drawn; the tooltip has it all
+)
+
+// explorerKinds are the kinds of node the explorer draws. Quotes would
+// outnumber everything else; each has its own page.
+var explorerKinds = []string{"philosophy", "philosopher", "theme", "evidence"}
+
+// graphColors tell the kinds of node apart, in the site's palette.
+var graphColors = map[string]string{
+	"philosophy":  "#f59e0b",
+	"philosopher": "#e7e5e4",
+	"theme":       "#a78bfa",
+	"evidence":    "#34d399",
+}
+
+// graphNodeView is a placed node with what the SVG needs to draw it.
+type graphNodeView struct {
+	store.PlacedNode
+	R       float64
+	Short   string  // the label, cut to fit
+	LabelY  float64 // baseline of the label, under the circle
+	Color   string
+	Page    string // the entity's own page
+	Explore string // the explorer, centred on this node
+	Partial string // the same, as an HTMX partial
+}
+
+// graphEdgeView is a placed edge; links between schools are dashed.
+type graphEdgeView struct {
+	store.PlacedEdge
+	Dashed bool
+}
+
+// Graph renders the graph explorer: the schools, philosophers, themes
+// and evidence drawn as SVG, laid out here rather than in the browser.
+// ?root= centres it on one node's neighborhood, ?depth= links deep.
+func (p *Pages) Graph(c *gin.Context) {
+	data, ok := p.graphData(c)
+	if !ok {
+		return
+	}
+	data["Page"] = "graph"
+	if data["Title"] == nil {
+		data["Title"] = "Graph"
+	}
+	p.render(c, http.StatusOK, data)
+}
+
+// GraphPartial returns just the explorer's drawing, for HTMX to swap in
+// when a node is clicked.
+func (p *Pages) GraphPartial(c *gin.Context) {
+	data, ok := p.graphData(c)
+	if !ok {
+		return
+	}
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	p.tmpl.ExecuteTemplate(c.Writer, "graph-view", data)
+}
+
+// graphData builds and lays out the graph the request asks for,
+// responding with an error itself if it can't.
+func (p *Pages) graphData(c *gin.Context) (gin.H, bool) {
+	depth, err := strconv.Atoi(c.DefaultQuery("depth", "1"))
+	if err != nil || depth < 1 {
+		c.String(http.StatusBadRequest, "depth must be a positive integer")
+		return nil, false
+	}
+	depth = min(depth, maxGraphDepth)
+
+	g, err := store.BuildGraph(p.repo)
+	if err != nil {
+		log.Printf("Graph: BuildGraph error: %v", err)
+		c.String(http.StatusInternalServerError, "internal error")
+		return nil, false
+	}
+	g = g.Only(explorerKinds...)
+	data := gin.H{"Depth": depth}
+	var root store.GraphNode
+	if id := c.Query("root"); id != "" {
+		if root, err = g.Resolve(id); err != nil {
+			p.notFound(c, "node", err)
+			return nil, false
+		}
+		if g, err = g.Neighborhood(root.ID, depth); err != nil {
+			p.notFound(c, "node", err)
+			return nil, false
+		}
+		data["Root"] = root
+		data["RootPage"] = entityPage(root)
+		data["Title"] = root.Label
+		var depths []gin.H
+		for d := 1; d <= maxGraphDepth; d++ {
+			depths = append(depths, gin.H{"Depth": d, "Explore": explorerURL("/pages/graph", root.ID, d),
+				"Partial": explorerURL("/partials/graph", root.ID, d)})
+		}
+		data["Depths"] = depths
+	}
+
+	layout := g.Layout(root.ID, graphWidth, graphHeight)
+	nodes := make([]graphNodeView, len(layout.Nodes))
+	for i, n := range layout.Nodes {
+		r := 5 + 2*math.Sqrt(float64(n.Degree))
+		if n.Root {
+			r += 4
+		}
+		nodes[i] = graphNodeView{
+			PlacedNode: n,
+			R:          math.Round(r*10) / 10,
+			Short:      shorten(n.Label, graphLabel),
+			LabelY:     math.Round((n.Y+r+12)*10) / 10,
+			Color:      graphColors[n.Kind],
+			Page:       entityPage(n.GraphNode),
+			Explore:    explorerURL("/pages/graph", n.ID, depth),
+			Partial:    explorerURL("/partials/graph", n.ID, depth),
+		}
+	}
+	edges := make([]graphEdgeView, len(layout.Edges))
+	for i, e := range layout.Edges {
+		edges[i] = graphEdgeView{PlacedEdge: e, Dashed: e.Rel == "related"}
+	}
+	data["Width"], data["Height"] = layout.Width, layout.Height
+	data["Nodes"], data["Edges"] = nodes, edges
+	data["Colors"] = graphColors
+	return data, true
+}
+
+// shorten cuts s to at most n characters, marking the cut with "…".
+func shorten(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return strings.TrimSpace(string(r[:n-1])) + "…"
+}
+
+// entityPage is the page of the entity a graph node stands for.
+func entityPage(n store.GraphNode) string {
+	return kindPages[n.Kind] + strings.TrimPrefix(n.ID, n.Kind+":")
+}
+
+// explorerURL is the explorer (or its partial) centred on a node.
+func explorerURL(path, root string, depth int) string {
+	return path + "?" + url.Values{"root": {root}, "depth": {fmt.Sprint(depth)}}.Encode()
+}
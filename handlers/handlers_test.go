@@ -110,7 +110,7 @@ func TestHealth(t *testing.T) {
 
 func TestQuoteList(t *testing.T) {
 	s := testStore()
-	qh := handlers.NewQuoteHandler(s)
+	qh := handlers.NewQuoteHandler(s, nil)
 
 	r := gin.New()
 	r.GET("/api/quotes", qh.List)
@@ -134,7 +134,7 @@ func TestQuoteList(t *testing.T) {
 
 func TestQuoteListFilterByPhilosopher(t *testing.T) {
 	s := testStore()
-	qh := handlers.NewQuoteHandler(s)
+	qh := handlers.NewQuoteHandler(s, nil)
 
 	r := gin.New()
 	r.GET("/api/quotes", qh.List)
@@ -154,7 +154,7 @@ func TestQuoteListFilterByPhilosopher(t *testing.T) {
 
 func TestQuoteListFilterByPhilosophy(t *testing.T) {
 	s := testStore()
-	qh := handlers.NewQuoteHandler(s)
+	qh := handlers.NewQuoteHandler(s, nil)
 
 	r := gin.New()
 	r.GET("/api/quotes", qh.List)
@@ -174,7 +174,7 @@ func TestQuoteListFilterByPhilosophy(t *testing.T) {
 
 func TestQuoteListFilterByTheme(t *testing.T) {
 	s := testStore()
-	qh := handlers.NewQuoteHandler(s)
+	qh := handlers.NewQuoteHandler(s, nil)
 
 	r := gin.New()
 	r.GET("/api/quotes", qh.List)
@@ -194,7 +194,7 @@ func TestQuoteListFilterByTheme(t *testing.T) {
 
 func TestQuoteListFilterNoMatch(t *testing.T) {
 	s := testStore()
-	qh := handlers.NewQuoteHandler(s)
+	qh := handlers.NewQuoteHandler(s, nil)
 
 	r := gin.New()
 	r.GET("/api/quotes", qh.List)
@@ -212,9 +212,73 @@ func TestQuoteListFilterNoMatch(t *testing.T) {
 	}
 }
 
+func TestQuoteListMultiValueFilters(t *testing.T) {
+	s := testStore()
+	qh := handlers.NewQuoteHandler(s, nil)
+
+	r := gin.New()
+	r.GET("/api/quotes", qh.List)
+
+	cases := map[string]int{
+		"/api/quotes?theme=control&theme=impermanence":             2,
+		"/api/quotes?theme=control,impermanence":                   2,
+		"/api/quotes?theme=control,impermanence&theme_match=all":   0,
+		"/api/quotes?-theme=control":                               1,
+		"/api/quotes?tradition=stoic,buddhist&theme=-impermanence": 1,
+	}
+	for url, want := range cases {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", url, nil)
+		r.ServeHTTP(w, req)
+
+		var body map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &body)
+
+		if count := int(body["count"].(float64)); count != want {
+			t.Errorf("%s: expected %d quotes, got %d", url, want, count)
+		}
+	}
+}
+
+func TestQuoteListPagination(t *testing.T) {
+	s := testStore()
+	qh := handlers.NewQuoteHandler(s, nil)
+
+	r := gin.New()
+	r.GET("/api/quotes", qh.List)
+
+	get := func(url string) (int, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", url, nil)
+		r.ServeHTTP(w, req)
+		var body map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &body)
+		return w.Code, body
+	}
+
+	_, first := get("/api/quotes?limit=1")
+	if first["count"].(float64) != 1 || first["prev"] != nil {
+		t.Fatalf("first page: %v", first)
+	}
+	next, ok := first["next"].(string)
+	if !ok {
+		t.Fatalf("expected next link, got %v", first["next"])
+	}
+
+	_, second := get(next)
+	q := second["quotes"].([]interface{})[0].(map[string]interface{})
+	if q["id"] != "q2" || second["next"] != nil || second["prev"] == nil {
+		t.Errorf("second page: %v", second)
+	}
+
+	if code, _ := get("/api/quotes?cursor=bogus"); code != http.StatusBadRequest {
+		t.Errorf("bad cursor: expected 400, got %d", code)
+	}
+}
+
 func TestQuoteGet(t *testing.T) {
 	s := testStore()
-	qh := handlers.NewQuoteHandler(s)
+	qh := handlers.NewQuoteHandler(s, nil)
 
 	r := gin.New()
 	r.GET("/api/quotes/:id", qh.Get)
@@ -240,7 +304,7 @@ func TestQuoteGet(t *testing.T) {
 
 func TestQuoteGetNotFound(t *testing.T) {
 	s := testStore()
-	qh := handlers.NewQuoteHandler(s)
+	qh := handlers.NewQuoteHandler(s, nil)
 
 	r := gin.New()
 	r.GET("/api/quotes/:id", qh.Get)
@@ -256,7 +320,7 @@ func TestQuoteGetNotFound(t *testing.T) {
 
 func TestQuoteRandom(t *testing.T) {
 	s := testStore()
-	qh := handlers.NewQuoteHandler(s)
+	qh := handlers.NewQuoteHandler(s, nil)
 
 	r := gin.New()
 	r.GET("/api/quotes/random", qh.Random)
@@ -285,7 +349,7 @@ func TestQuoteRandomEmpty(t *testing.T) {
 		Themes:       make(map[string]models.Theme),
 		Evidence:     make(map[string]models.Evidence),
 	}
-	qh := handlers.NewQuoteHandler(s)
+	qh := handlers.NewQuoteHandler(s, nil)
 
 	r := gin.New()
 	r.GET("/api/quotes/random", qh.Random)
@@ -624,3 +688,18 @@ func TestEvidenceGetNotFound(t *testing.T) {
 		t.Fatalf("expected 404, got %d", w.Code)
 	}
 }
+
+func TestParseCurators(t *testing.T) {
+	cs, err := handlers.ParseCurators("ana:s3cret, ben:0ther,")
+	if err != nil || len(cs) != 2 || cs["ana"] != "s3cret" || cs["ben"] != "0ther" {
+		t.Errorf("curators: %v, %v", cs, err)
+	}
+	for _, bad := range []string{"ana", "ana:", ":s3cret", "ana:a,ana:b", "ana:same,ben:same"} {
+		if _, err := handlers.ParseCurators(bad); err == nil {
+			t.Errorf("%q: expected an error", bad)
+		}
+	}
+	if cs, err := handlers.ParseCurators(""); err != nil || len(cs) != 0 {
+		t.Errorf("empty: %v, %v", cs, err)
+	}
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"perennial-wisdom/store"
+)
+
+// maxImportSize caps an uploaded spreadsheet.
+const maxImportSize = 10 << 20
+
+// ImportForm renders the bulk import upload form.
+func (a *Admin) ImportForm(c *gin.Context) {
+	a.importPage(c, http.StatusOK, nil, "")
+}
+
+// Import runs store.Import on an uploaded CSV, JSON or YAML file and
+// renders its report. Dry runs are the default: the form has to be sent
+// with commit=1 to write anything.
+//
+//	POST /admin/import  multipart: file, format (optional), commit, change_note
+func (a *Admin) Import(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+	fh, err := c.FormFile("file")
+	if err != nil {
+		a.importPage(c, http.StatusBadRequest, nil, "choose a file to import (up to 10 MB)")
+		return
+	}
+	f, err := fh.Open()
+	if err != nil {
+		a.importPage(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+	defer f.Close()
+
+	format := c.PostForm("format")
+	if format == "" {
+		format = store.FormatOf(fh.Filename)
+	}
+	note := c.PostForm("change_note")
+	if note == "" {
+		note = "import " + fh.Filename
+	}
+	report, err := store.Import(a.repo, f, store.ImportOptions{
+		Format: format,
+		DryRun: c.PostForm("commit") == "",
+		Change: changeOf(c, note),
+	})
+	switch {
+	case err == nil && report.OK():
+		a.importPage(c, http.StatusOK, report, "")
+	case err == nil:
+		a.importPage(c, http.StatusUnprocessableEntity, report, "")
+	case errors.Is(err, store.ErrInvalid), errors.Is(err, store.ErrConflict):
+		a.importPage(c, http.StatusUnprocessableEntity, nil, err.Error())
+	case errors.Is(err, store.ErrUnreadable):
+		a.importPage(c, http.StatusBadRequest, nil, err.Error())
+	default:
+		log.Printf("admin import: %v", err)
+		a.importPage(c, http.StatusInternalServerError, nil, "the import could not be saved; nothing was written")
+	}
+}
+
+func (a *Admin) importPage(c *gin.Context, status int, report *store.ImportReport, message string) {
+	a.pages.render(c, status, gin.H{
+		"Page":   "admin-import",
+		"Title":  "Import — Admin",
+		"Report": report,
+		"Error":  message,
+	})
+}
@@ -2,25 +2,44 @@ package handlers
 
 import (
 	"bytes"
+	"errors"
 	"html/template"
 	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 
-	"perennial-wisdom/db"
+	"perennial-wisdom/models"
+	"perennial-wisdom/store"
 )
 
 // Pages serves HTML pages using Go templates + HTMX.
-// All dependencies are explicit — queries for data, templates for rendering.
+// All dependencies are explicit — repository for data, templates for rendering.
 type Pages struct {
-	q    *db.Queries
-	tmpl *template.Template
+	repo    store.Repository
+	tmpl    *template.Template
+	daily   store.Daily
+	weights store.Weights
 }
 
-// NewPages creates a Pages handler with explicit dependencies.
-func NewPages(q *db.Queries, tmpl *template.Template) *Pages {
-	return &Pages{q: q, tmpl: tmpl}
+// NewPages creates a Pages handler with explicit dependencies; daily
+// picks the home page's quote of the day, and weights bias its random
+// quote.
+func NewPages(repo store.Repository, tmpl *template.Template, daily store.Daily, weights store.Weights) *Pages {
+	return &Pages{repo: repo, tmpl: tmpl, daily: daily, weights: weights}
+}
+
+// quoteView is a quote plus the display names templates need.
+type quoteView struct {
+	models.Quote
+	PhilosopherName string
+	PhilosophyName  string
+}
+
+// philosopherView is a philosopher plus their school's display name.
+type philosopherView struct {
+	models.Philosopher
+	PhilosophyName string
 }
 
 // render executes the "base" template with page-specific content.
@@ -37,11 +56,49 @@ func (p *Pages) render(c *gin.Context, status int, data gin.H) {
 	buf.WriteTo(c.Writer)
 }
 
-// Home renders the landing page with a random quote and tradition grid.
+// quoteViews resolves display names for a list of quotes.
+func (p *Pages) quoteViews(quotes []models.Quote) []quoteView {
+	views := make([]quoteView, len(quotes))
+	for i, q := range quotes {
+		views[i] = p.quoteView(q)
+	}
+	return views
+}
+
+func (p *Pages) quoteView(q models.Quote) quoteView {
+	return quoteView{
+		Quote:           q,
+		PhilosopherName: philosopherName(p.repo, q.PhilosopherID),
+		PhilosophyName:  philosophyName(p.repo, q.PhilosophyID),
+	}
+}
+
+// notFound renders a plain 404 for unknown IDs, 500 for other errors.
+func (p *Pages) notFound(c *gin.Context, what string, err error) {
+	if errors.Is(err, store.ErrNotFound) {
+		c.String(http.StatusNotFound, what+" not found")
+		return
+	}
+	log.Printf("%s lookup error: %v", what, err)
+	c.String(http.StatusInternalServerError, "internal error")
+}
+
+// page reads ?limit= and ?cursor=, responding 400 if either is bad.
+func (p *Pages) page(c *gin.Context) (store.Page, bool) {
+	page, err := store.ParsePage(c.Request.URL.Query())
+	if err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return store.Page{}, false
+	}
+	return page, true
+}
+
+// Home renders the landing page with the quote of the day, a random
+// quote and the tradition grid.
 func (p *Pages) Home(c *gin.Context) {
-	traditions, err := p.q.ListTraditions()
+	traditions, _, err := p.repo.ListPhilosophies(store.Page{})
 	if err != nil {
-		log.Printf("Home: ListTraditions error: %v", err)
+		log.Printf("Home: ListPhilosophies error: %v", err)
 	}
 	p.render(c, http.StatusOK, gin.H{
 		"Page":       "home",
@@ -50,31 +107,53 @@ func (p *Pages) Home(c *gin.Context) {
 	})
 }
 
-// RandomQuotePartial returns just the random quote HTML fragment (for HTMX).
+// RandomQuotePartial returns just the random quote HTML fragment (for
+// HTMX). It takes the same parameters as /api/quotes/random; its
+// "another" button steps on through the same seed, so a reader sees no
+// quote twice until they have seen every one.
 func (p *Pages) RandomQuotePartial(c *gin.Context) {
-	quote, err := p.q.RandomQuote()
+	shuffle, n, err := parseShuffle(c, p.weights)
 	if err != nil {
-		c.String(http.StatusInternalServerError, "no quotes available")
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	quote, err := shuffle.Nth(p.repo, n)
+	if err != nil {
+		p.notFound(c, "quote", err)
 		return
 	}
 	c.Header("Content-Type", "text/html; charset=utf-8")
-	p.tmpl.ExecuteTemplate(c.Writer, "random-quote", quote)
+	p.tmpl.ExecuteTemplate(c.Writer, "random-quote", randomView{
+		quoteView: p.quoteView(quote),
+		Another:   shuffleURL(c, shuffle.Seed, n+1),
+	})
+}
+
+// randomView is a random quote and where to get the next one.
+type randomView struct {
+	quoteView
+	Another string
 }
 
-// Quotes renders the quotes listing with filters.
+// Quotes renders the first page of the quotes listing with filters.
+// Further pages load in place via QuotesPartial as the reader scrolls.
 func (p *Pages) Quotes(c *gin.Context) {
 	tradition := c.Query("tradition")
 	theme := c.Query("theme")
 
-	quotes, err := p.q.ListQuotes("", tradition, theme)
+	page, ok := p.page(c)
+	if !ok {
+		return
+	}
+	quotes, cur, err := p.repo.ListQuotes(store.ParseFilter(c.Request.URL.Query(), "tradition", "theme"), page)
 	if err != nil {
 		log.Printf("Quotes: ListQuotes error: %v", err)
 	}
-	traditions, err := p.q.ListTraditions()
+	traditions, _, err := p.repo.ListPhilosophies(store.Page{})
 	if err != nil {
-		log.Printf("Quotes: ListTraditions error: %v", err)
+		log.Printf("Quotes: ListPhilosophies error: %v", err)
 	}
-	themes, err := p.q.ListThemes()
+	themes, _, err := p.repo.ListThemes(store.Page{})
 	if err != nil {
 		log.Printf("Quotes: ListThemes error: %v", err)
 	}
@@ -82,7 +161,8 @@ func (p *Pages) Quotes(c *gin.Context) {
 	p.render(c, http.StatusOK, gin.H{
 		"Page":       "quotes",
 		"Title":      "Quotes",
-		"Quotes":     quotes,
+		"Quotes":     p.quoteViews(quotes),
+		"Next":       linkTo("/partials/quotes", c.Request.URL.Query(), cur.Next),
 		"Traditions": traditions,
 		"Themes":     themes,
 		"Filter": gin.H{
@@ -92,84 +172,195 @@ func (p *Pages) Quotes(c *gin.Context) {
 	})
 }
 
-// Philosophers renders the philosophers listing.
+// relatedView is a resonating quote with the names of the themes it
+// shares with the quote on the page.
+type relatedView struct {
+	quoteView
+	SharedThemes   []models.Theme
+	CrossTradition bool
+}
+
+// QuoteDetail renders a single quote with its expositions, themes and
+// evidence, and the quotes from elsewhere that resonate with it.
+// Quotes that aren't live are not found.
+func (p *Pages) QuoteDetail(c *gin.Context) {
+	quote, err := p.repo.GetQuote(c.Param("id"))
+	if err == nil && !store.Live(quote) {
+		err = store.ErrNotFound
+	}
+	if err != nil {
+		p.notFound(c, "quote", err)
+		return
+	}
+
+	themes := map[string]models.Theme{}
+	for _, tid := range quote.ThemeIDs {
+		if t, err := p.repo.GetTheme(tid); err == nil {
+			themes[tid] = t
+		}
+	}
+	var evidence []models.Evidence
+	for _, eid := range quote.EvidenceIDs {
+		if e, err := p.repo.GetEvidence(eid); err == nil {
+			evidence = append(evidence, e)
+		}
+	}
+	related, err := store.Related(p.repo, quote.ID, store.RelatedLimit)
+	if err != nil {
+		log.Printf("QuoteDetail: Related error: %v", err)
+	}
+	views := make([]relatedView, len(related))
+	for i, r := range related {
+		views[i] = relatedView{quoteView: p.quoteView(r.Quote), CrossTradition: r.CrossTradition}
+		for _, tid := range r.SharedThemes {
+			if t, ok := themes[tid]; ok {
+				views[i].SharedThemes = append(views[i].SharedThemes, t)
+			}
+		}
+	}
+	var themeList []models.Theme
+	for _, tid := range quote.ThemeIDs {
+		if t, ok := themes[tid]; ok {
+			themeList = append(themeList, t)
+		}
+	}
+
+	title := quote.Title
+	if title == "" {
+		title = philosopherName(p.repo, quote.PhilosopherID)
+	}
+	p.render(c, http.StatusOK, gin.H{
+		"Page":     "quote-detail",
+		"Title":    title,
+		"Quote":    p.quoteView(quote),
+		"Themes":   themeList,
+		"Evidence": evidence,
+		"Related":  views,
+	})
+}
+
+// QuotesPartial returns the next page of quote cards plus a "load more"
+// trigger for the page after it (for HTMX infinite scroll).
+func (p *Pages) QuotesPartial(c *gin.Context) {
+	page, ok := p.page(c)
+	if !ok {
+		return
+	}
+	quotes, cur, err := p.repo.ListQuotes(store.ParseFilter(c.Request.URL.Query(), "tradition", "theme"), page)
+	if err != nil {
+		log.Printf("QuotesPartial: ListQuotes error: %v", err)
+		c.String(http.StatusInternalServerError, "internal error")
+		return
+	}
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	p.tmpl.ExecuteTemplate(c.Writer, "quote-page", gin.H{
+		"Quotes": p.quoteViews(quotes),
+		"Next":   pageURL(c, cur.Next),
+	})
+}
+
+// Philosophers renders one page of the philosophers listing.
 func (p *Pages) Philosophers(c *gin.Context) {
-	philosophers, err := p.q.ListPhilosophers("")
+	page, ok := p.page(c)
+	if !ok {
+		return
+	}
+	philosophers, cur, err := p.repo.ListPhilosophers(nil, page)
 	if err != nil {
 		log.Printf("Philosophers: ListPhilosophers error: %v", err)
 	}
+	views := make([]philosopherView, len(philosophers))
+	for i, ph := range philosophers {
+		views[i] = philosopherView{Philosopher: ph, PhilosophyName: philosophyName(p.repo, ph.PhilosophyID)}
+	}
 	p.render(c, http.StatusOK, gin.H{
 		"Page":         "philosophers",
 		"Title":        "Philosophers",
-		"Philosophers": philosophers,
+		"Philosophers": views,
+		"Next":         pageURL(c, cur.Next),
+		"Prev":         pageURL(c, cur.Prev),
 	})
 }
 
 // PhilosopherDetail renders a single philosopher page.
 func (p *Pages) PhilosopherDetail(c *gin.Context) {
 	id := c.Param("id")
-	philosopher, err := p.q.GetPhilosopher(id)
+	philosopher, err := p.repo.GetPhilosopher(id)
 	if err != nil {
-		c.String(http.StatusNotFound, "philosopher not found")
+		p.notFound(c, "philosopher", err)
 		return
 	}
-	quotes, err := p.q.PhilosopherQuotes(id)
+	quotes, _, err := p.repo.ListQuotes(store.Where("philosopher", id), store.Page{})
 	if err != nil {
-		log.Printf("PhilosopherDetail: PhilosopherQuotes error: %v", err)
+		log.Printf("PhilosopherDetail: ListQuotes error: %v", err)
 	}
 
 	p.render(c, http.StatusOK, gin.H{
-		"Page":        "philosopher-detail",
-		"Title":       philosopher.Name,
-		"Philosopher": philosopher,
-		"Teachings":   philosopher.Teachings(),
-		"Quotes":      quotes,
+		"Page":  "philosopher-detail",
+		"Title": philosopher.Name,
+		"Philosopher": philosopherView{
+			Philosopher:    philosopher,
+			PhilosophyName: philosophyName(p.repo, philosopher.PhilosophyID),
+		},
+		"Teachings": philosopher.KeyTeachings,
+		"Quotes":    p.quoteViews(quotes),
 	})
 }
 
-// Philosophies renders the traditions (schools) listing.
+// Philosophies renders one page of the traditions (schools) listing.
 func (p *Pages) Philosophies(c *gin.Context) {
-	traditions, err := p.q.ListTraditions()
+	page, ok := p.page(c)
+	if !ok {
+		return
+	}
+	traditions, cur, err := p.repo.ListPhilosophies(page)
 	if err != nil {
-		log.Printf("Philosophies: ListTraditions error: %v", err)
+		log.Printf("Philosophies: ListPhilosophies error: %v", err)
 	}
 	p.render(c, http.StatusOK, gin.H{
 		"Page":       "philosophies",
 		"Title":      "Schools of Wisdom",
 		"Traditions": traditions,
+		"Next":       pageURL(c, cur.Next),
+		"Prev":       pageURL(c, cur.Prev),
 	})
 }
 
 // PhilosophyDetail renders a single tradition page.
 func (p *Pages) PhilosophyDetail(c *gin.Context) {
 	id := c.Param("id")
-	tradition, err := p.q.GetTradition(id)
+	tradition, err := p.repo.GetPhilosophy(id)
 	if err != nil {
-		c.String(http.StatusNotFound, "tradition not found")
+		p.notFound(c, "tradition", err)
 		return
 	}
-	philosophers, err := p.q.TraditionPhilosophers(id)
+	philosophers, _, err := p.repo.ListPhilosophers(store.Where("philosophy", id), store.Page{})
 	if err != nil {
-		log.Printf("PhilosophyDetail: TraditionPhilosophers error: %v", err)
+		log.Printf("PhilosophyDetail: ListPhilosophers error: %v", err)
 	}
-	quotes, err := p.q.TraditionQuotes(id)
+	quotes, _, err := p.repo.ListQuotes(store.Where("philosophy", id), store.Page{})
 	if err != nil {
-		log.Printf("PhilosophyDetail: TraditionQuotes error: %v", err)
+		log.Printf("PhilosophyDetail: ListQuotes error: %v", err)
 	}
 
 	p.render(c, http.StatusOK, gin.H{
 		"Page":         "philosophy-detail",
 		"Title":        tradition.Name,
 		"Tradition":    tradition,
-		"Principles":   tradition.Principles(),
+		"Feed":         "/feeds/traditions/" + tradition.ID,
+		"Principles":   tradition.CorePrinciples,
 		"Philosophers": philosophers,
-		"Quotes":       quotes,
+		"Quotes":       p.quoteViews(quotes),
 	})
 }
 
-// Themes renders the themes listing.
+// Themes renders one page of the themes listing.
 func (p *Pages) Themes(c *gin.Context) {
-	themes, err := p.q.ListThemes()
+	page, ok := p.page(c)
+	if !ok {
+		return
+	}
+	themes, cur, err := p.repo.ListThemes(page)
 	if err != nil {
 		log.Printf("Themes: ListThemes error: %v", err)
 	}
@@ -177,34 +368,56 @@ func (p *Pages) Themes(c *gin.Context) {
 		"Page":   "themes",
 		"Title":  "Perennial Themes",
 		"Themes": themes,
+		"Next":   pageURL(c, cur.Next),
+		"Prev":   pageURL(c, cur.Prev),
 	})
 }
 
-// ThemeDetail renders a single theme page — the cross-correlation view.
+// parallelView is one tradition's column of a theme's parallels.
+type parallelView struct {
+	store.ParallelColumn
+	Quotes []quoteView
+}
+
+// ThemeDetail renders a single theme page — the cross-correlation view:
+// its quotes, then the same quotes side by side, a column per
+// tradition, gaps included.
 func (p *Pages) ThemeDetail(c *gin.Context) {
-	id := c.Param("id")
-	theme, err := p.q.GetTheme(id)
+	par, err := store.ThemeParallels(p.repo, c.Param("id"))
 	if err != nil {
-		c.String(http.StatusNotFound, "theme not found")
+		p.notFound(c, "theme", err)
 		return
 	}
-	quotes, err := p.q.ThemeQuotes(id)
+	quotes, _, err := p.repo.ListQuotes(store.Where("theme", par.Theme.ID), store.Page{})
 	if err != nil {
-		log.Printf("ThemeDetail: ThemeQuotes error: %v", err)
+		log.Printf("ThemeDetail: ListQuotes error: %v", err)
+	}
+	columns := make([]parallelView, len(par.Columns))
+	for i, col := range par.Columns {
+		columns[i] = parallelView{ParallelColumn: col, Quotes: p.quoteViews(col.Quotes)}
 	}
 
 	p.render(c, http.StatusOK, gin.H{
-		"Page":   "theme-detail",
-		"Title":  theme.Name,
-		"Theme":  theme,
-		"Quotes": quotes,
+		"Page":      "theme-detail",
+		"Title":     par.Theme.Name,
+		"Theme":     par.Theme,
+		"Feed":      "/feeds/themes/" + par.Theme.ID,
+		"Quotes":    p.quoteViews(quotes),
+		"Parallels": columns,
+		"Gaps":      len(par.Gaps),
+		"Covered":   par.Covered,
+		"Listed":    par.Covered + len(par.Gaps),
 	})
 }
 
-// Evidence renders the evidence listing.
+// Evidence renders one page of the evidence listing.
 func (p *Pages) Evidence(c *gin.Context) {
 	field := c.Query("field")
-	evidence, err := p.q.ListEvidence(field)
+	page, ok := p.page(c)
+	if !ok {
+		return
+	}
+	evidence, cur, err := p.repo.ListEvidence(store.Where("field", field), page)
 	if err != nil {
 		log.Printf("Evidence: ListEvidence error: %v", err)
 	}
@@ -213,21 +426,37 @@ func (p *Pages) Evidence(c *gin.Context) {
 		"Title":    "Scientific Evidence",
 		"Evidence": evidence,
 		"Filter":   field,
+		"Next":     pageURL(c, cur.Next),
+		"Prev":     pageURL(c, cur.Prev),
 	})
 }
 
-// EvidenceDetail renders a single evidence page.
+// EvidenceDetail renders a single evidence page with its themes and
+// the quotes it empirically echoes.
 func (p *Pages) EvidenceDetail(c *gin.Context) {
 	id := c.Param("id")
-	evidence, err := p.q.GetEvidence(id)
+	evidence, err := p.repo.GetEvidence(id)
 	if err != nil {
-		c.String(http.StatusNotFound, "evidence not found")
+		p.notFound(c, "evidence", err)
 		return
 	}
 
+	var themes []models.Theme
+	for _, tid := range evidence.ThemeIDs {
+		if t, err := p.repo.GetTheme(tid); err == nil {
+			themes = append(themes, t)
+		}
+	}
+	quotes, _, err := p.repo.ListQuotes(store.Where("evidence", id), store.Page{})
+	if err != nil {
+		log.Printf("EvidenceDetail: ListQuotes error: %v", err)
+	}
+
 	p.render(c, http.StatusOK, gin.H{
 		"Page":     "evidence-detail",
 		"Title":    evidence.Title,
 		"Evidence": evidence,
+		"Themes":   themes,
+		"Quotes":   p.quoteViews(quotes),
 	})
 }
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+
+	"perennial-wisdom/store"
+)
+
+// parsePage reads ?limit= and ?cursor=, responding 400 if either is bad.
+func parsePage(c *gin.Context) (store.Page, bool) {
+	p, err := store.ParsePage(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return store.Page{}, false
+	}
+	return p, true
+}
+
+// listResponse is the JSON envelope shared by list endpoints:
+// {"<key>": [...], "count": n, "next": url|null, "prev": url|null}.
+func listResponse(c *gin.Context, key string, items any, count int, cur store.Cursors) gin.H {
+	return gin.H{
+		key:     items,
+		"count": count,
+		"next":  pageURL(c, cur.Next),
+		"prev":  pageURL(c, cur.Prev),
+	}
+}
+
+// pageURL is the current request URL with its cursor swapped for cursor,
+// keeping filters and limit. nil (JSON null) when there's no such page.
+func pageURL(c *gin.Context, cursor string) any {
+	return linkTo(c.Request.URL.Path, c.Request.URL.Query(), cursor)
+}
+
+// linkTo builds path?query with the cursor set, or nil without a cursor.
+func linkTo(path string, q url.Values, cursor string) any {
+	if cursor == "" {
+		return nil
+	}
+	q.Set("cursor", cursor)
+	return path + "?" + q.Encode()
+}
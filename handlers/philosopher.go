@@ -5,55 +5,56 @@ import (
 
 	"github.com/gin-gonic/gin"
 
-	"perennial-wisdom/models"
 	"perennial-wisdom/store"
 )
 
 // PhilosopherHandler serves philosopher-related endpoints.
 type PhilosopherHandler struct {
-	store *store.Store
+	repo store.Repository
 }
 
-// NewPhilosopherHandler creates a PhilosopherHandler with explicit store dependency.
-func NewPhilosopherHandler(s *store.Store) *PhilosopherHandler {
-	return &PhilosopherHandler{store: s}
+// NewPhilosopherHandler creates a PhilosopherHandler with explicit repository dependency.
+func NewPhilosopherHandler(repo store.Repository) *PhilosopherHandler {
+	return &PhilosopherHandler{repo: repo}
 }
 
 // List returns all philosophers, optionally filtered by philosophy:
-//   - ?philosophy=stoic
+//   - ?philosophy=stoic,buddhist
+//   - ?-philosophy=stoic
+//
+// Paginated with ?limit= and ?cursor=, like every list endpoint.
 func (h *PhilosopherHandler) List(c *gin.Context) {
-	philosophy := c.Query("philosophy")
-
-	var results []models.Philosopher
-	for _, p := range h.store.Philosophers {
-		if philosophy != "" && p.PhilosophyID != philosophy {
-			continue
-		}
-		results = append(results, p)
+	page, ok := parsePage(c)
+	if !ok {
+		return
+	}
+	results, cur, err := h.repo.ListPhilosophers(store.ParseFilter(c.Request.URL.Query(), store.PhilosopherDimensions...), page)
+	if err != nil {
+		serverError(c, "PhilosopherHandler.List", err)
+		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"philosophers": results, "count": len(results)})
+	c.JSON(http.StatusOK, listResponse(c, "philosophers", results, len(results), cur))
 }
 
 // Get returns a single philosopher by ID, with their quotes.
 func (h *PhilosopherHandler) Get(c *gin.Context) {
 	id := c.Param("id")
-	p, ok := h.store.Philosophers[id]
-	if !ok {
-		c.JSON(http.StatusNotFound, gin.H{"error": "philosopher not found"})
+	p, err := h.repo.GetPhilosopher(id)
+	if err != nil {
+		lookupError(c, "philosopher", err)
 		return
 	}
 
-	var quotes []models.Quote
-	for _, q := range h.store.Quotes {
-		if q.PhilosopherID == id {
-			quotes = append(quotes, q)
-		}
+	quotes, _, err := h.repo.ListQuotes(store.Where("philosopher", id), store.Page{})
+	if err != nil {
+		serverError(c, "PhilosopherHandler.Get", err)
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"philosopher": p,
-		"philosophy":  h.store.Philosophies[p.PhilosophyID].Name,
+		"philosophy":  philosophyName(h.repo, p.PhilosophyID),
 		"quotes":      quotes,
 	})
 }
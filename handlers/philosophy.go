@@ -5,62 +5,65 @@ import (
 
 	"github.com/gin-gonic/gin"
 
-	"perennial-wisdom/models"
 	"perennial-wisdom/store"
 )
 
 // PhilosophyHandler serves philosophy/school-related endpoints.
 type PhilosophyHandler struct {
-	store *store.Store
+	repo store.Repository
 }
 
-// NewPhilosophyHandler creates a PhilosophyHandler with explicit store dependency.
-func NewPhilosophyHandler(s *store.Store) *PhilosophyHandler {
-	return &PhilosophyHandler{store: s}
+// NewPhilosophyHandler creates a PhilosophyHandler with explicit repository dependency.
+func NewPhilosophyHandler(repo store.Repository) *PhilosophyHandler {
+	return &PhilosophyHandler{repo: repo}
 }
 
-// List returns all philosophies/schools.
+// List returns philosophies/schools ordered by name, paginated with
+// ?limit= and ?cursor=.
 func (h *PhilosophyHandler) List(c *gin.Context) {
-	var results []models.Philosophy
-	for _, p := range h.store.Philosophies {
-		results = append(results, p)
+	page, ok := parsePage(c)
+	if !ok {
+		return
+	}
+	results, cur, err := h.repo.ListPhilosophies(page)
+	if err != nil {
+		serverError(c, "PhilosophyHandler.List", err)
+		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"philosophies": results, "count": len(results)})
+	c.JSON(http.StatusOK, listResponse(c, "philosophies", results, len(results), cur))
 }
 
 // Get returns a single philosophy by ID, with its philosophers,
 // related philosophies, and a sample of quotes.
 func (h *PhilosophyHandler) Get(c *gin.Context) {
 	id := c.Param("id")
-	p, ok := h.store.Philosophies[id]
-	if !ok {
-		c.JSON(http.StatusNotFound, gin.H{"error": "philosophy not found"})
+	p, err := h.repo.GetPhilosophy(id)
+	if err != nil {
+		lookupError(c, "philosophy", err)
 		return
 	}
 
 	// Gather related philosophy names
 	var related []string
 	for _, rid := range p.RelatedIDs {
-		if rp, ok := h.store.Philosophies[rid]; ok {
-			related = append(related, rp.Name)
+		if name := philosophyName(h.repo, rid); name != "" {
+			related = append(related, name)
 		}
 	}
 
 	// Gather philosophers of this school
-	var philosophers []models.Philosopher
-	for _, ph := range h.store.Philosophers {
-		if ph.PhilosophyID == id {
-			philosophers = append(philosophers, ph)
-		}
+	philosophers, _, err := h.repo.ListPhilosophers(store.Where("philosophy", id), store.Page{})
+	if err != nil {
+		serverError(c, "PhilosophyHandler.Get", err)
+		return
 	}
 
 	// Gather quotes from this school
-	var quotes []models.Quote
-	for _, q := range h.store.Quotes {
-		if q.PhilosophyID == id {
-			quotes = append(quotes, q)
-		}
+	quotes, _, err := h.repo.ListQuotes(store.Where("philosophy", id), store.Page{})
+	if err != nil {
+		serverError(c, "PhilosophyHandler.Get", err)
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -1,7 +1,12 @@
 package handlers
 
 import (
+	"errors"
+	"log"
+	"math/rand/v2"
 	"net/http"
+	"net/url"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 
@@ -12,71 +17,184 @@ import (
 // QuoteHandler serves quote-related endpoints.
 // Quotes are the center stage of the perennial wisdom API.
 type QuoteHandler struct {
-	store *store.Store
+	repo    store.Repository
+	weights store.Weights
 }
 
-// NewQuoteHandler creates a QuoteHandler with explicit store dependency.
-func NewQuoteHandler(s *store.Store) *QuoteHandler {
-	return &QuoteHandler{store: s}
+// NewQuoteHandler creates a QuoteHandler with explicit dependencies;
+// weights bias which quotes come up at random.
+func NewQuoteHandler(repo store.Repository, weights store.Weights) *QuoteHandler {
+	return &QuoteHandler{repo: repo, weights: weights}
 }
 
-// List returns all quotes, with optional filters:
+// List returns one page of quotes, with optional filters:
 //   - ?philosopher=epictetus
-//   - ?philosophy=stoic
-//   - ?theme=control
+//   - ?philosophy=stoic (or ?tradition=stoic)
+//   - ?theme=control&theme=death  (any of; add ?theme_match=all for every one)
+//   - ?evidence=cognitive-reappraisal
+//   - ?-theme=death  (exclude; also ?theme=-death)
+//
+// Values may repeat or be comma-separated; dimensions are ANDed.
+// Results are ordered by ID, store.DefaultLimit (20) to a page unless
+// ?limit= asks for another size, up to store.MaxLimit (100). ?cursor=
+// picks the page; follow the "next" and "prev" URLs in the response
+// rather than building cursors by hand.
 func (h *QuoteHandler) List(c *gin.Context) {
-	philosopher := c.Query("philosopher")
-	philosophy := c.Query("philosophy")
-	theme := c.Query("theme")
-
-	var results []models.Quote
-	for _, q := range h.store.Quotes {
-		if philosopher != "" && q.PhilosopherID != philosopher {
-			continue
-		}
-		if philosophy != "" && q.PhilosophyID != philosophy {
-			continue
-		}
-		if theme != "" && !contains(q.ThemeIDs, theme) {
-			continue
-		}
-		results = append(results, q)
+	page, ok := parsePage(c)
+	if !ok {
+		return
+	}
+	results, cur, err := h.repo.ListQuotes(store.ParseFilter(c.Request.URL.Query(), store.QuoteDimensions...), page)
+	if err != nil {
+		serverError(c, "QuoteHandler.List", err)
+		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"quotes": results, "count": len(results)})
+	c.JSON(http.StatusOK, listResponse(c, "quotes", results, len(results), cur))
 }
 
 // Get returns a single quote by ID, enriched with philosopher and theme names.
+// Quotes that aren't live yet (or any more) are not found.
 func (h *QuoteHandler) Get(c *gin.Context) {
-	id := c.Param("id")
-	q, ok := h.store.Quotes[id]
-	if !ok {
-		c.JSON(http.StatusNotFound, gin.H{"error": "quote not found"})
+	q, err := h.repo.GetQuote(c.Param("id"))
+	if err == nil && !store.Live(q) {
+		err = store.ErrNotFound
+	}
+	if err != nil {
+		lookupError(c, "quote", err)
 		return
 	}
 
-	philosopher := h.store.Philosophers[q.PhilosopherID]
+	c.JSON(http.StatusOK, h.enrich(q))
+}
 
-	c.JSON(http.StatusOK, gin.H{
+// Random returns a random quote, weighted, from among those matching
+// the same filters as List (?philosopher=, ?tradition=, ?theme=, ...),
+// with ?evidence_backed=true for only quotes that evidence supports.
+//
+// Each answer carries its seed and position; ?seed=S&n=N gives the
+// same quote again, and "next" is the one after it. Stepping through
+// a seed shows every matching quote once before any repeats.
+func (h *QuoteHandler) Random(c *gin.Context) {
+	shuffle, n, err := parseShuffle(c, h.weights)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	q, err := shuffle.Nth(h.repo, n)
+	if errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no quotes available"})
+		return
+	}
+	if err != nil {
+		serverError(c, "QuoteHandler.Random", err)
+		return
+	}
+
+	body := h.enrich(q)
+	body["seed"] = shuffle.Seed
+	body["n"] = n
+	body["next"] = shuffleURL(c, shuffle.Seed, n+1)
+	c.JSON(http.StatusOK, body)
+}
+
+// parseShuffle reads a random pick's filters, ?seed= and ?n= — a fresh
+// seed if there is none.
+func parseShuffle(c *gin.Context, weights store.Weights) (store.Shuffle, int, error) {
+	s := store.Shuffle{
+		Filter:  store.ParseFilter(c.Request.URL.Query(), store.QuoteDimensions...),
+		Weights: weights,
+		Seed:    c.Query("seed"),
+	}
+	if s.Seed == "" {
+		s.Seed = strconv.FormatUint(rand.Uint64(), 36)
+	}
+	if v := c.Query("evidence_backed"); v != "" {
+		backed, err := strconv.ParseBool(v)
+		if err != nil {
+			return s, 0, errors.New("evidence_backed must be true or false")
+		}
+		s.Backed = backed
+	}
+	n, err := strconv.Atoi(c.DefaultQuery("n", "0"))
+	if err != nil || n < 0 {
+		return s, 0, errors.New("n must be a non-negative integer")
+	}
+	return s, n, nil
+}
+
+// shuffleURL is the request's own URL, filters kept, at another point
+// of the seed's run.
+func shuffleURL(c *gin.Context, seed string, n int) string {
+	q := c.Request.URL.Query()
+	q.Set("seed", seed)
+	q.Set("n", strconv.Itoa(n))
+	return (&url.URL{Path: c.Request.URL.Path, RawQuery: q.Encode()}).String()
+}
+
+// Related returns the quotes that resonate with this one — similar in
+// text and themes, other traditions first — with the reasons why.
+// ?limit= caps them (default store.RelatedLimit).
+func (h *QuoteHandler) Related(c *gin.Context) {
+	limit := store.RelatedLimit
+	if c.Query("limit") != "" {
+		page, ok := parsePage(c)
+		if !ok {
+			return
+		}
+		limit = page.Limit
+	}
+	related, err := store.Related(h.repo, c.Param("id"), limit)
+	if err != nil {
+		lookupError(c, "quote", err)
+		return
+	}
+
+	items := make([]gin.H, len(related))
+	for i, r := range related {
+		items[i] = h.enrich(r.Quote)
+		items[i]["score"] = r.Score
+		items[i]["similarity"] = r.Similarity
+		items[i]["shared_themes"] = r.SharedThemes
+		items[i]["cross_tradition"] = r.CrossTradition
+	}
+	c.JSON(http.StatusOK, gin.H{"related": items, "count": len(items)})
+}
+
+// enrich wraps a quote with its philosopher and philosophy names.
+func (h *QuoteHandler) enrich(q models.Quote) gin.H {
+	return gin.H{
 		"quote":       q,
-		"philosopher": philosopher.Name,
-		"philosophy":  h.store.Philosophies[q.PhilosophyID].Name,
-	})
+		"philosopher": philosopherName(h.repo, q.PhilosopherID),
+		"philosophy":  philosophyName(h.repo, q.PhilosophyID),
+	}
 }
 
-// Random returns a random quote. Uses map iteration order
-// which is randomized in Go by design.
-func (h *QuoteHandler) Random(c *gin.Context) {
-	for _, q := range h.store.Quotes {
-		philosopher := h.store.Philosophers[q.PhilosopherID]
-		c.JSON(http.StatusOK, gin.H{
-			"quote":       q,
-			"philosopher": philosopher.Name,
-			"philosophy":  h.store.Philosophies[q.PhilosophyID].Name,
-		})
+// philosopherName looks up a display name, or "" if unknown.
+func philosopherName(repo store.Repository, id string) string {
+	p, _ := repo.GetPhilosopher(id)
+	return p.Name
+}
+
+// philosophyName looks up a display name, or "" if unknown.
+func philosophyName(repo store.Repository, id string) string {
+	p, _ := repo.GetPhilosophy(id)
+	return p.Name
+}
+
+// lookupError responds 404 for unknown IDs and 500 for anything else.
+func lookupError(c *gin.Context, what string, err error) {
+	if errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": what + " not found"})
 		return
 	}
-	c.JSON(http.StatusNotFound, gin.H{"error": "no quotes available"})
+	serverError(c, what+" lookup", err)
+}
+
+// serverError logs the cause and hides it from the client.
+func serverError(c *gin.Context, where string, err error) {
+	log.Printf("%s: %v", where, err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
 }
 
 func contains(slice []string, item string) bool {
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"perennial-wisdom/store"
+)
+
+// RevisionHandler serves one kind's revision history:
+//
+//	GET  /api/<kind>/:id/revisions                 newest first
+//	GET  /api/<kind>/:id/revisions/:n              one revision and what it changed
+//	POST /api/<kind>/:id/revisions/:n/rollback     restore the entity to revision n
+//
+// A rollback is a write like any other: it records a new revision and is
+// rejected (422) if the old state refers to entities since deleted.
+type RevisionHandler struct {
+	repo store.ReadWriter
+	kind string // store.History kind, e.g. "quote"
+}
+
+// NewRevisionHandler creates the history handler for one kind.
+func NewRevisionHandler(repo store.ReadWriter, kind string) *RevisionHandler {
+	return &RevisionHandler{repo: repo, kind: kind}
+}
+
+// List returns an entity's revisions, newest first.
+func (h *RevisionHandler) List(c *gin.Context) {
+	revs, err := h.repo.ListRevisions(h.kind, c.Param("id"))
+	if err != nil {
+		serverError(c, h.kind+" revisions", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"revisions": revs, "count": len(revs)})
+}
+
+// Get returns one revision with its field-level changes from the
+// revision before.
+func (h *RevisionHandler) Get(c *gin.Context) {
+	n, ok := revisionNumber(c)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "revision not found"})
+		return
+	}
+	rev, err := h.repo.GetRevision(h.kind, c.Param("id"), n)
+	if err != nil {
+		lookupError(c, "revision", err)
+		return
+	}
+	changes, err := store.DiffRevision(h.repo, rev)
+	if err != nil {
+		serverError(c, h.kind+" revision diff", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"revision": rev, "changes": changes})
+}
+
+// Rollback restores the entity to revision n and returns the revision
+// that records the rollback. The X-Change-Note header is appended to the
+// rollback's note.
+func (h *RevisionHandler) Rollback(c *gin.Context) {
+	n, ok := revisionNumber(c)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "revision not found"})
+		return
+	}
+	id := c.Param("id")
+	if err := store.Rollback(h.repo, h.kind, id, n, changeOf(c, c.GetHeader("X-Change-Note"))); err != nil {
+		writeError(c, "revision", err)
+		return
+	}
+	revs, err := h.repo.ListRevisions(h.kind, id)
+	if err != nil || len(revs) == 0 {
+		serverError(c, h.kind+" revisions reload", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"revision": revs[0]})
+}
+
+// revisionNumber reads the :n path parameter.
+func revisionNumber(c *gin.Context) (int, bool) {
+	n, err := strconv.Atoi(c.Param("n"))
+	return n, err == nil && n > 0
+}
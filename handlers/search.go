@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"perennial-wisdom/store"
+)
+
+// SearchHandler serves ranked full-text search over quotes.
+type SearchHandler struct {
+	repo store.Repository
+}
+
+// NewSearchHandler creates a SearchHandler with explicit repository dependency.
+func NewSearchHandler(repo store.Repository) *SearchHandler {
+	return &SearchHandler{repo: repo}
+}
+
+// Search ranks live quotes against ?q=, best first, with highlighted
+// snippets and facet counts by tradition and theme over every match.
+// Filter dimensions narrow the matches; pages follow ?limit= and ?offset=.
+// Philosophers, traditions and themes whose names the query roughly
+// matches come back as names; when nothing matches, did_you_mean may
+// offer a corrected query.
+//
+//	GET /api/search?q=fear+of+death&tradition=stoic
+func (h *SearchHandler) Search(c *gin.Context) {
+	s, err := store.ParseSearch(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	res, err := h.repo.SearchQuotes(s)
+	if err != nil {
+		serverError(c, "SearchHandler.Search", err)
+		return
+	}
+	prev, next := searchPages(c, s, res)
+	c.JSON(http.StatusOK, gin.H{
+		"query":  s.Text,
+		"hits":   res.Hits,
+		"count":  len(res.Hits),
+		"total":  res.Total,
+		"facets": res.Facets,
+		"names":  res.Names,
+		"next":   next,
+		"prev":   prev,
+
+		"did_you_mean": res.DidYouMean,
+	})
+}
+
+// All searches every kind of entity at once: quotes as Search does,
+// plus philosophers, traditions, themes and evidence, grouped by kind
+// with a count for each. ?limit= caps every group; ?offset= and the
+// filter dimensions page and narrow the quotes.
+//
+//	GET /api/search/all?q=death
+func (h *SearchHandler) All(c *gin.Context) {
+	s, err := store.ParseSearch(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	res, err := store.SearchAll(h.repo, s)
+	if err != nil {
+		serverError(c, "SearchHandler.All", err)
+		return
+	}
+	prev, next := searchPages(c, s, res.Quotes)
+	quotes := gin.H{
+		"hits":   res.Quotes.Hits,
+		"count":  len(res.Quotes.Hits),
+		"total":  res.Quotes.Total,
+		"facets": res.Quotes.Facets,
+		"names":  res.Quotes.Names,
+		"next":   next,
+		"prev":   prev,
+
+		"did_you_mean": res.Quotes.DidYouMean,
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"query":  s.Text,
+		"total":  res.Total,
+		"counts": res.Counts,
+		"quotes": quotes,
+		"groups": res.Groups,
+	})
+}
+
+// searchPages links the pages of results either side of this one, or
+// nil (JSON null) at either end.
+func searchPages(c *gin.Context, s store.SearchQuery, res store.SearchResults) (prev, next any) {
+	limit, offset := s.Window()
+	at := func(o int) string {
+		q := c.Request.URL.Query()
+		q.Set("offset", strconv.Itoa(o))
+		return c.Request.URL.Path + "?" + q.Encode()
+	}
+	if offset > 0 {
+		prev = at(max(offset-limit, 0))
+	}
+	if offset+limit < res.Total {
+		next = at(offset + limit)
+	}
+	return prev, next
+}
+
+// searchHitView is a search hit ready for the templates.
+type searchHitView struct {
+	quoteView
+	Snippet template.HTML // escaped by the store; only <mark> is markup
+}
+
+// nameView is a name a search matched, with the page it names.
+type nameView struct {
+	store.NameMatch
+	URL string
+}
+
+// kindPages maps entity kinds onto their pages.
+var kindPages = map[string]string{
+	"philosopher": "/pages/philosophers/",
+	"philosophy":  "/pages/philosophies/",
+	"theme":       "/pages/themes/",
+	"evidence":    "/pages/evidence/",
+	"quote":       "/pages/quotes/",
+}
+
+// groupView is a kind of entity a search matched, for the results page.
+type groupView struct {
+	Label string
+	Total int
+	Hits  []entityHitView
+}
+
+// entityHitView is an entity a search matched, with the page it names.
+type entityHitView struct {
+	store.EntityHit
+	URL     string
+	Snippet template.HTML // escaped by the store; only <mark> is markup
+}
+
+// groupLabels name the kinds of entity on the results page.
+var groupLabels = map[string]string{
+	"philosopher": "Philosophers",
+	"philosophy":  "Schools",
+	"theme":       "Themes",
+	"evidence":    "Evidence",
+}
+
+// searchView shapes quote search results for the pages.
+func (p *Pages) searchView(c *gin.Context, s store.SearchQuery, res store.SearchResults) gin.H {
+	hits := make([]searchHitView, len(res.Hits))
+	for i, h := range res.Hits {
+		hits[i] = searchHitView{quoteView: p.quoteView(h.Quote), Snippet: template.HTML(h.Snippet)}
+	}
+	names := make([]nameView, len(res.Names))
+	for i, n := range res.Names {
+		names[i] = nameView{NameMatch: n, URL: kindPages[n.Kind] + n.ID}
+	}
+	prev, next := searchPages(c, s, res)
+	data := gin.H{
+		"Query":  s.Text,
+		"Hits":   hits,
+		"Total":  res.Total,
+		"Facets": res.Facets,
+		"Names":  names,
+		"Prev":   prev,
+		"Next":   next,
+	}
+	if res.DidYouMean != "" {
+		data["DidYouMean"] = res.DidYouMean
+		data["DidYouMeanURL"] = "/pages/search?" + url.Values{"q": {res.DidYouMean}}.Encode()
+	}
+	return data
+}
+
+// Search renders the full results page, with facets to narrow by.
+func (p *Pages) Search(c *gin.Context) {
+	s, err := store.ParseSearch(c.Request.URL.Query())
+	if err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	// A broken search should not take the page down with it.
+	all, err := store.SearchAll(p.repo, s)
+	if err != nil {
+		log.Printf("Search: SearchAll error: %v", err)
+	}
+	data := p.searchView(c, s, all.Quotes)
+	var groups []groupView
+	found := map[string]bool{}
+	for _, g := range all.Groups {
+		if g.Total == 0 {
+			continue
+		}
+		gv := groupView{Label: groupLabels[g.Kind], Total: g.Total}
+		for _, h := range g.Hits {
+			gv.Hits = append(gv.Hits, entityHitView{EntityHit: h, URL: kindPages[h.Kind] + h.ID, Snippet: template.HTML(h.Snippet)})
+			found[h.Kind+"/"+h.ID] = true
+		}
+		groups = append(groups, gv)
+	}
+	// Names the groups already show needn't be repeated.
+	var names []nameView
+	for _, n := range data["Names"].([]nameView) {
+		if !found[n.Kind+"/"+n.ID] {
+			names = append(names, n)
+		}
+	}
+	data["Names"] = names
+	data["Groups"] = groups
+	data["GroupTotal"] = all.Total - all.Quotes.Total
+	data["Page"] = "search"
+	data["Title"] = "Search"
+	data["Filter"] = gin.H{"Tradition": c.Query("tradition"), "Theme": c.Query("theme")}
+	data["FacetURL"] = func(dim, id string) string {
+		q := url.Values{"q": {s.Text}}
+		for _, d := range []string{"tradition", "theme"} {
+			if v := c.Query(d); v != "" && d != dim {
+				q.Set(d, v)
+			}
+		}
+		if id != "" {
+			q.Set(dim, id)
+		}
+		return "/pages/search?" + q.Encode()
+	}
+	p.render(c, http.StatusOK, data)
+}
+
+// SearchPartial returns the top hits as the dropdown under the
+// search-as-you-type box in the navigation bar (for HTMX).
+func (p *Pages) SearchPartial(c *gin.Context) {
+	q := c.Request.URL.Query()
+	s := store.SearchQuery{Text: q.Get("q"), Limit: 6}
+	res, err := p.repo.SearchQuotes(s)
+	if err != nil {
+		log.Printf("SearchPartial: SearchQuotes error: %v", err)
+	}
+	data := p.searchView(c, s, res)
+	data["More"] = "/pages/search?" + url.Values{"q": {s.Text}}.Encode()
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	p.tmpl.ExecuteTemplate(c.Writer, "search-results", data)
+}
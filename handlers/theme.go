@@ -12,50 +12,63 @@ import (
 // ThemeHandler serves theme-related endpoints.
 // Themes are the cross-tradition connective tissue — the "perennial" threads.
 type ThemeHandler struct {
-	store *store.Store
+	repo store.Repository
 }
 
-// NewThemeHandler creates a ThemeHandler with explicit store dependency.
-func NewThemeHandler(s *store.Store) *ThemeHandler {
-	return &ThemeHandler{store: s}
+// NewThemeHandler creates a ThemeHandler with explicit repository dependency.
+func NewThemeHandler(repo store.Repository) *ThemeHandler {
+	return &ThemeHandler{repo: repo}
 }
 
-// List returns all themes.
+// List returns themes ordered by name, paginated with ?limit= and ?cursor=.
 func (h *ThemeHandler) List(c *gin.Context) {
-	var results []models.Theme
-	for _, t := range h.store.Themes {
-		results = append(results, t)
+	page, ok := parsePage(c)
+	if !ok {
+		return
+	}
+	results, cur, err := h.repo.ListThemes(page)
+	if err != nil {
+		serverError(c, "ThemeHandler.List", err)
+		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"themes": results, "count": len(results)})
+	c.JSON(http.StatusOK, listResponse(c, "themes", results, len(results), cur))
 }
 
 // Get returns a single theme by ID, with quotes across traditions
 // that address this theme — the cross-correlation view.
 func (h *ThemeHandler) Get(c *gin.Context) {
 	id := c.Param("id")
-	t, ok := h.store.Themes[id]
-	if !ok {
-		c.JSON(http.StatusNotFound, gin.H{"error": "theme not found"})
+	t, err := h.repo.GetTheme(id)
+	if err != nil {
+		lookupError(c, "theme", err)
 		return
 	}
 
 	// Gather quotes that reference this theme
+	themed, _, err := h.repo.ListQuotes(store.Where("theme", id), store.Page{})
+	if err != nil {
+		serverError(c, "ThemeHandler.Get", err)
+		return
+	}
 	var quotes []gin.H
-	for _, q := range h.store.Quotes {
-		if contains(q.ThemeIDs, id) {
-			quotes = append(quotes, gin.H{
-				"quote":       q.Text,
-				"philosopher": h.store.Philosophers[q.PhilosopherID].Name,
-				"philosophy":  h.store.Philosophies[q.PhilosophyID].Name,
-				"source":      q.Source,
-			})
-		}
+	for _, q := range themed {
+		quotes = append(quotes, gin.H{
+			"quote":       q.Text,
+			"philosopher": philosopherName(h.repo, q.PhilosopherID),
+			"philosophy":  philosophyName(h.repo, q.PhilosophyID),
+			"source":      q.Source,
+		})
 	}
 
 	// Gather evidence supporting this theme
+	all, _, err := h.repo.ListEvidence(nil, store.Page{})
+	if err != nil {
+		serverError(c, "ThemeHandler.Get", err)
+		return
+	}
 	var evidence []models.Evidence
-	for _, e := range h.store.Evidence {
+	for _, e := range all {
 		if contains(e.ThemeIDs, id) {
 			evidence = append(evidence, e)
 		}
@@ -64,8 +77,8 @@ func (h *ThemeHandler) Get(c *gin.Context) {
 	// Gather philosophy names that address this theme
 	var philosophies []string
 	for _, pid := range t.PhilosophyIDs {
-		if p, ok := h.store.Philosophies[pid]; ok {
-			philosophies = append(philosophies, p.Name)
+		if name := philosophyName(h.repo, pid); name != "" {
+			philosophies = append(philosophies, name)
 		}
 	}
 
@@ -76,3 +89,36 @@ func (h *ThemeHandler) Get(c *gin.Context) {
 		"evidence":     evidence,
 	})
 }
+
+// Parallels returns the theme's quotes grouped by tradition, side by
+// side: a column for every tradition the theme lists, empty ones flagged
+// as gaps, then any other tradition quoted on it.
+func (h *ThemeHandler) Parallels(c *gin.Context) {
+	p, err := store.ThemeParallels(h.repo, c.Param("id"))
+	if err != nil {
+		lookupError(c, "theme", err)
+		return
+	}
+
+	columns := make([]gin.H, len(p.Columns))
+	for i, col := range p.Columns {
+		quotes := make([]gin.H, len(col.Quotes))
+		for j, q := range col.Quotes {
+			quotes[j] = gin.H{"quote": q, "philosopher": philosopherName(h.repo, q.PhilosopherID)}
+		}
+		columns[i] = gin.H{
+			"philosophy_id": col.PhilosophyID,
+			"philosophy":    col.Name,
+			"listed":        col.Listed,
+			"gap":           col.Gap,
+			"count":         len(col.Quotes),
+			"quotes":        quotes,
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"theme":      p.Theme,
+		"traditions": columns,
+		"gaps":       p.Gaps,
+		"covered":    p.Covered,
+	})
+}
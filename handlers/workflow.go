@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"perennial-wisdom/models"
+	"perennial-wisdom/store"
+)
+
+// WorkflowHandler moves quotes through review and publication:
+//
+//	POST /api/quotes/:id/status  {"status": "in_review"}
+//	POST /api/quotes/:id/status  {"status": "published", "publish_at": "2030-01-01T09:00:00Z"}
+//	GET  /api/review             quotes in review or scheduled, by ID
+//
+// Disallowed moves — including a self-approval under the second-approver
+// rule — are 422. Like every write, a move is recorded in the revision
+// history with the optional X-Change-Note header.
+type WorkflowHandler struct {
+	repo store.ReadWriter
+	wf   store.Workflow
+}
+
+// NewWorkflowHandler creates a WorkflowHandler enforcing wf.
+func NewWorkflowHandler(repo store.ReadWriter, wf store.Workflow) *WorkflowHandler {
+	return &WorkflowHandler{repo: repo, wf: wf}
+}
+
+// Status moves a quote to the status in the JSON body and returns it.
+func (h *WorkflowHandler) Status(c *gin.Context) {
+	var body struct {
+		Status    string     `json:"status"`
+		PublishAt *time.Time `json:"publish_at"`
+	}
+	if !decodeBody(c, &body) {
+		return
+	}
+	id := c.Param("id")
+	if err := h.wf.Transition(h.repo, id, body.Status, body.PublishAt, changeOf(c, c.GetHeader("X-Change-Note"))); err != nil {
+		writeError(c, "quote", err)
+		return
+	}
+	q, err := h.repo.GetQuote(id)
+	if err != nil {
+		serverError(c, "quote reload", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"quote": q})
+}
+
+// Review lists the quotes awaiting a decision: those in review and those
+// published on a schedule that hasn't arrived. Filters and pagination
+// work as on /api/quotes.
+func (h *WorkflowHandler) Review(c *gin.Context) {
+	page, ok := parsePage(c)
+	if !ok {
+		return
+	}
+	f := store.ParseFilter(c.Request.URL.Query(), store.QuoteDimensions...)
+	f["status"] = store.Condition{Values: []string{store.StatusInReview, store.StatusScheduled}}
+	results, cur, err := h.repo.ListQuotes(f, page)
+	if err != nil {
+		serverError(c, "WorkflowHandler.Review", err)
+		return
+	}
+	c.JSON(http.StatusOK, listResponse(c, "quotes", results, len(results), cur))
+}
+
+// keepStatus guards the generic quote writes: status and publish_at
+// only change through Workflow. Creates start as drafts; replaces that
+// leave the status out keep the stored one, and are saved by wf.Edit.
+func keepStatus(repo store.ReadWriter, wf store.Workflow) (create, update func(models.Quote, store.Change) error) {
+	create = func(q models.Quote, ch store.Change) error {
+		if (q.Status != "" && q.Status != store.StatusDraft) || q.PublishAt != nil {
+			return statusMoved()
+		}
+		return repo.CreateQuote(q, ch)
+	}
+	update = func(q models.Quote, ch store.Change) error {
+		stored, err := repo.GetQuote(q.ID)
+		if err != nil {
+			return err
+		}
+		if q.Status == "" && q.PublishAt == nil {
+			q.Status, q.PublishAt = stored.Status, stored.PublishAt
+		}
+		if q.Status != stored.Status || !samePublishAt(q.PublishAt, stored.PublishAt) {
+			return statusMoved()
+		}
+		return wf.Edit(repo, q, ch)
+	}
+	return create, update
+}
+
+func statusMoved() error {
+	return &store.ValidationError{Problems: []store.Problem{{
+		Field: "status", Message: "use POST /api/quotes/:id/status to change status or publish_at",
+	}}}
+}
+
+func samePublishAt(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"perennial-wisdom/models"
+	"perennial-wisdom/store"
+)
+
+// WriteHandler serves POST/PUT/PATCH/DELETE for one kind of entity.
+// The per-kind plumbing is a handful of method values on the repository,
+// so all five kinds share one implementation of the HTTP semantics:
+//
+//	POST   /api/<kind>      create; 201, 409 if the id or slug is taken
+//	PUT    /api/<kind>/:id  replace the whole entity; 404 if missing
+//	PATCH  /api/<kind>/:id  merge the given fields into the stored entity
+//	DELETE /api/<kind>/:id  204; references to it are cleared
+//
+// Validation failures (missing fields, unknown references) are 422.
+// Every write is recorded in the revision history, under the author
+// RequireToken identified and the optional X-Change-Note header.
+type WriteHandler[T any] struct {
+	name   string // JSON envelope key and error noun, e.g. "quote"
+	path   string // collection path for Location headers
+	id     func(*T) *string
+	get    func(id string) (T, error)
+	create func(T, store.Change) error
+	update func(T, store.Change) error
+	delete func(id string, ch store.Change) error
+}
+
+// NewQuoteWriteHandler creates the write handler for quotes. New quotes
+// are drafts; status changes go through WorkflowHandler, and edits are
+// saved under wf.
+func NewQuoteWriteHandler(repo store.ReadWriter, wf store.Workflow) *WriteHandler[models.Quote] {
+	create, update := keepStatus(repo, wf)
+	return &WriteHandler[models.Quote]{
+		name: "quote", path: "/api/quotes",
+		id:  func(q *models.Quote) *string { return &q.ID },
+		get: repo.GetQuote, create: create, update: update, delete: repo.DeleteQuote,
+	}
+}
+
+// NewPhilosopherWriteHandler creates the write handler for philosophers.
+func NewPhilosopherWriteHandler(repo store.ReadWriter) *WriteHandler[models.Philosopher] {
+	return &WriteHandler[models.Philosopher]{
+		name: "philosopher", path: "/api/philosophers",
+		id:  func(p *models.Philosopher) *string { return &p.ID },
+		get: repo.GetPhilosopher, create: repo.CreatePhilosopher, update: repo.UpdatePhilosopher, delete: repo.DeletePhilosopher,
+	}
+}
+
+// NewPhilosophyWriteHandler creates the write handler for schools.
+func NewPhilosophyWriteHandler(repo store.ReadWriter) *WriteHandler[models.Philosophy] {
+	return &WriteHandler[models.Philosophy]{
+		name: "philosophy", path: "/api/philosophies",
+		id:  func(p *models.Philosophy) *string { return &p.ID },
+		get: repo.GetPhilosophy, create: repo.CreatePhilosophy, update: repo.UpdatePhilosophy, delete: repo.DeletePhilosophy,
+	}
+}
+
+// NewThemeWriteHandler creates the write handler for themes.
+func NewThemeWriteHandler(repo store.ReadWriter) *WriteHandler[models.Theme] {
+	return &WriteHandler[models.Theme]{
+		name: "theme", path: "/api/themes",
+		id:  func(t *models.Theme) *string { return &t.ID },
+		get: repo.GetTheme, create: repo.CreateTheme, update: repo.UpdateTheme, delete: repo.DeleteTheme,
+	}
+}
+
+// NewEvidenceWriteHandler creates the write handler for evidence.
+func NewEvidenceWriteHandler(repo store.ReadWriter) *WriteHandler[models.Evidence] {
+	return &WriteHandler[models.Evidence]{
+		name: "evidence", path: "/api/evidence",
+		id:  func(e *models.Evidence) *string { return &e.ID },
+		get: repo.GetEvidence, create: repo.CreateEvidence, update: repo.UpdateEvidence, delete: repo.DeleteEvidence,
+	}
+}
+
+// Create adds a new entity from the JSON body.
+func (h *WriteHandler[T]) Create(c *gin.Context) {
+	var v T
+	if !decodeBody(c, &v) {
+		return
+	}
+	if err := h.create(v, changeOf(c, c.GetHeader("X-Change-Note"))); err != nil {
+		writeError(c, h.name, err)
+		return
+	}
+	id := *h.id(&v)
+	c.Header("Location", h.path+"/"+id)
+	h.respond(c, http.StatusCreated, id)
+}
+
+// Replace overwrites an entity with the JSON body. The path may name it
+// by slug; the body's id may be omitted, and if present must be the
+// entity's id.
+func (h *WriteHandler[T]) Replace(c *gin.Context) {
+	stored, err := h.get(c.Param("id"))
+	if err != nil {
+		lookupError(c, h.name, err)
+		return
+	}
+	id := *h.id(&stored)
+	var v T
+	if !decodeBody(c, &v) || !h.pinID(c, &v, id) {
+		return
+	}
+	if err := h.update(v, changeOf(c, c.GetHeader("X-Change-Note"))); err != nil {
+		writeError(c, h.name, err)
+		return
+	}
+	h.respond(c, http.StatusOK, id)
+}
+
+// Patch applies the JSON body's fields on top of the stored entity.
+// Fields left out keep their values; arrays given are replaced whole.
+func (h *WriteHandler[T]) Patch(c *gin.Context) {
+	stored, err := h.get(c.Param("id"))
+	if err != nil {
+		lookupError(c, h.name, err)
+		return
+	}
+	id := *h.id(&stored)
+	// Decode onto a deep copy: json reuses slice and map storage, which
+	// may be shared with the repository's own copy.
+	var v T
+	raw, _ := json.Marshal(stored)
+	json.Unmarshal(raw, &v)
+	if !decodeBody(c, &v) || !h.pinID(c, &v, id) {
+		return
+	}
+	if err := h.update(v, changeOf(c, c.GetHeader("X-Change-Note"))); err != nil {
+		writeError(c, h.name, err)
+		return
+	}
+	h.respond(c, http.StatusOK, id)
+}
+
+// Delete removes an entity, which the path may name by slug.
+func (h *WriteHandler[T]) Delete(c *gin.Context) {
+	stored, err := h.get(c.Param("id"))
+	if err != nil {
+		lookupError(c, h.name, err)
+		return
+	}
+	if err := h.delete(*h.id(&stored), changeOf(c, c.GetHeader("X-Change-Note"))); err != nil {
+		writeError(c, h.name, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// pinID gives the entity the id the path resolved to, rejecting bodies
+// that try to rename it — IDs are referenced from elsewhere and never
+// change.
+func (h *WriteHandler[T]) pinID(c *gin.Context, v *T, id string) bool {
+	got := h.id(v)
+	if *got != "" && *got != id {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "id in body does not match path; ids cannot be changed"})
+		return false
+	}
+	*got = id
+	return true
+}
+
+// respond re-reads the entity so clients see exactly what was stored.
+func (h *WriteHandler[T]) respond(c *gin.Context, status int, id string) {
+	v, err := h.get(id)
+	if err != nil {
+		serverError(c, h.name+" reload", err)
+		return
+	}
+	c.JSON(status, gin.H{h.name: v})
+}
+
+// decodeBody strictly decodes JSON, rejecting unknown fields so typos
+// don't silently vanish. Only an application/json body is read: it's the
+// one kind a page on another site can't send without a CORS preflight.
+func decodeBody(c *gin.Context, v any) bool {
+	if ct, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type")); ct != "application/json" {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Content-Type must be application/json"})
+		return false
+	}
+	dec := json.NewDecoder(c.Request.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON body: " + err.Error()})
+		return false
+	}
+	return true
+}
+
+// writeError maps store write errors onto HTTP statuses.
+func writeError(c *gin.Context, what string, err error) {
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": what + " not found"})
+	case errors.Is(err, store.ErrConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, store.ErrInvalid):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		serverError(c, what+" write", err)
+	}
+}
@@ -4,29 +4,99 @@ import (
 	"html/template"
 	"log"
 	"os"
+	"strconv"
 
 	"perennial-wisdom/db"
+	"perennial-wisdom/handlers"
 	"perennial-wisdom/router"
 	"perennial-wisdom/store"
 )
 
 func main() {
-	// Open PostgreSQL connection
-	database := db.Open("")
+	// Subcommands (migrate, ...) run and exit instead of serving
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// Open database — SQLite file when DB_PATH is set, PostgreSQL otherwise
+	database := db.Open(os.Getenv("DB_PATH"))
 	defer database.Close()
 
-	// Create query layer
+	// Bring the schema up to date before serving
+	if err := db.Migrate(database); err != nil {
+		log.Fatalf("failed to migrate database: %v", err)
+	}
+
+	// A fresh database gets the seed corpus; existing data is left as curated
+	if empty, err := db.IsEmpty(database); err != nil {
+		log.Fatalf("failed to inspect database: %v", err)
+	} else if empty {
+		if err := db.Seed(database); err != nil {
+			log.Fatalf("failed to seed database: %v", err)
+		}
+		log.Println("Seeded database from store seed corpus")
+	}
+
+	// Create query layer and the repository both API and pages read from
 	queries := db.NewQueries(database)
+	repo := db.NewRepository(queries)
+
+	// SQLite searches an in-process index; build it before the first search
+	if err := repo.WarmSearch(); err != nil {
+		log.Fatalf("failed to build search index: %v", err)
+	}
 
 	// Parse HTML templates
 	tmpl := template.Must(template.ParseGlob("templates/*.html"))
 	template.Must(tmpl.ParseGlob("templates/partials/*.html"))
 
-	// In-memory store still available for JSON API (legacy, can be removed later)
-	s := store.New()
+	// DAILY_WINDOW is how many days pass before a quote of the day repeats
+	var daily store.Daily
+	if w := os.Getenv("DAILY_WINDOW"); w != "" {
+		n, err := strconv.Atoi(w)
+		if err != nil || n < 1 {
+			log.Fatalf("DAILY_WINDOW must be a positive number of days, not %q", w)
+		}
+		daily.Window = n
+	}
+
+	// RANDOM_WEIGHTS, like "philosopher:epictetus=3,theme:death=0.5", bias
+	// random quotes; unset, every quote is as likely as any other
+	var weights store.Weights
+	if w := os.Getenv("RANDOM_WEIGHTS"); w != "" {
+		var err error
+		if weights, err = store.ParseWeights(w); err != nil {
+			log.Fatalf("RANDOM_WEIGHTS: %v", err)
+		}
+	}
+
+	// ADMIN_TOKENS, like "ana:s3cret,ben:0ther", gives each curator their
+	// own token, which names them in the revision history; ADMIN_TOKEN
+	// alone is a single curator, "admin"
+	curators, err := handlers.ParseCurators(os.Getenv("ADMIN_TOKENS"))
+	if err != nil {
+		log.Fatalf("ADMIN_TOKENS: %v", err)
+	}
+	if token := os.Getenv("ADMIN_TOKEN"); token != "" {
+		curators["admin"] = token
+	}
+
+	// REQUIRE_SECOND_APPROVER=true stops curators publishing their own work
+	workflow := store.Workflow{SecondApprover: os.Getenv("REQUIRE_SECOND_APPROVER") == "true"}
+	if workflow.SecondApprover && len(curators) < 2 {
+		log.Printf("REQUIRE_SECOND_APPROVER is set with fewer than two curators: nothing can be published")
+	}
 
 	// Wire all routes with explicit dependencies
-	r := router.Setup(s, queries, tmpl)
+	r := router.Setup(repo, tmpl, router.Options{
+		Curators: curators,
+		Workflow: workflow,
+		Daily:    daily,
+		Weights:  weights,
+	})
 
 	// Port — configurable via env, defaults to 8080
 	port := os.Getenv("PORT")
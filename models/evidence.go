@@ -9,5 +9,6 @@ type Evidence struct {
 	Finding     string   `json:"finding"`
 	Field       string   `json:"field"` // "neuroscience", "neuropsychology", "psychology"
 	Source      string   `json:"source"`
+	Strength    string   `json:"evidence_strength,omitempty"`
 	ThemeIDs    []string `json:"theme_ids"`
 }
@@ -1,14 +1,33 @@
 package models
 
+import "time"
+
 // Quote is the central entity — a piece of perennial wisdom.
 // It links a philosopher's words to themes, a school of thought,
 // and optionally to scientific evidence supporting the insight.
+// Exposition fields deepen the quote at increasing levels of detail.
+// Status moves through the editorial workflow (draft, in_review,
+// published, retired); only published quotes are public, and not before
+// PublishAt when it is set.
 type Quote struct {
-	ID           string   `json:"id"`
-	Text         string   `json:"text"`
-	PhilosopherID string  `json:"philosopher_id"`
-	PhilosophyID string   `json:"philosophy_id"`
-	Source       string   `json:"source"`
-	ThemeIDs     []string `json:"theme_ids"`
-	EvidenceIDs  []string `json:"evidence_ids,omitempty"`
+	ID                     string         `json:"id"`
+	Title                  string         `json:"title,omitempty"`
+	Slug                   string         `json:"slug,omitempty"`
+	Text                   string         `json:"text"`
+	TextScholarly          string         `json:"text_scholarly,omitempty"`
+	PhilosopherID          string         `json:"philosopher_id"`
+	PhilosophyID           string         `json:"philosophy_id"`
+	Source                 string         `json:"source"`
+	SourceLocation         string         `json:"source_location,omitempty"`
+	OriginalScript         string         `json:"original_script,omitempty"`
+	ExpositionBrief        string         `json:"exposition_brief,omitempty"`
+	ExpositionStandard     string         `json:"exposition_standard,omitempty"`
+	ExpositionScholarly    string         `json:"exposition_scholarly,omitempty"`
+	ReflectionPrompt       string         `json:"reflection_prompt,omitempty"`
+	ModernReinterpretation string         `json:"modern_reinterpretation,omitempty"`
+	Meta                   map[string]any `json:"meta,omitempty"`
+	Status                 string         `json:"status,omitempty"`
+	PublishAt              *time.Time     `json:"publish_at,omitempty"`
+	ThemeIDs               []string       `json:"theme_ids"`
+	EvidenceIDs            []string       `json:"evidence_ids,omitempty"`
 }
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Revision is an immutable record of one write to a corpus entity:
+// who made it, when, why, and the entity as it stood afterwards.
+// Revisions are numbered 1, 2, 3… per entity and never edited.
+type Revision struct {
+	Kind      string          `json:"kind"` // "quote", "philosopher", "philosophy", "theme", "evidence"
+	EntityID  string          `json:"entity_id"`
+	Number    int             `json:"number"`
+	Action    string          `json:"action"` // "create", "update", "delete", or "baseline" for pre-history state
+	Author    string          `json:"author"`
+	Note      string          `json:"note,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data,omitempty"` // snapshot after the write; empty once deleted
+}
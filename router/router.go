@@ -5,55 +5,128 @@ import (
 
 	"github.com/gin-gonic/gin"
 
-	"perennial-wisdom/db"
 	"perennial-wisdom/handlers"
 	"perennial-wisdom/store"
 )
 
+// Options configures the curation side of the router.
+type Options struct {
+	// Curators unlock the write routes, each with their own bearer
+	// token, and the /admin UI, with it as a bearer token or Basic auth
+	// password. Without any they answer 403.
+	Curators handlers.Curators
+
+	// Workflow governs quote review and publication.
+	Workflow store.Workflow
+
+	// Daily picks the quote of the day.
+	Daily store.Daily
+
+	// Weights bias which quotes come up at random.
+	Weights store.Weights
+}
+
 // Setup creates a Gin engine with all routes wired.
 // All dependencies are explicit — no init(), no reflection, no magic.
-func Setup(s *store.Store, q *db.Queries, tmpl *template.Template) *gin.Engine {
+// The JSON API and the HTML pages share one repository, so they always
+// serve the same corpus, and only published quotes reach either.
+func Setup(repo store.ReadWriter, tmpl *template.Template, opts Options) *gin.Engine {
 	r := gin.Default()
 
 	// Health check
 	r.GET("/health", handlers.Health)
 
-	// --- JSON API (existing) ---
+	// --- JSON API ---
 
 	// Quotes — center stage
-	qh := handlers.NewQuoteHandler(s)
+	qh := handlers.NewQuoteHandler(repo, opts.Weights)
 	r.GET("/api/quotes", qh.List)
 	r.GET("/api/quotes/random", qh.Random)
+	r.GET("/api/quotes/today", handlers.NewDailyHandler(repo, opts.Daily).Today)
 	r.GET("/api/quotes/:id", qh.Get)
+	r.GET("/api/quotes/:id/related", qh.Related)
 
 	// Philosophers — the teachers
-	ph := handlers.NewPhilosopherHandler(s)
+	ph := handlers.NewPhilosopherHandler(repo)
 	r.GET("/api/philosophers", ph.List)
 	r.GET("/api/philosophers/:id", ph.Get)
 
 	// Philosophies — the schools
-	pyh := handlers.NewPhilosophyHandler(s)
+	pyh := handlers.NewPhilosophyHandler(repo)
 	r.GET("/api/philosophies", pyh.List)
 	r.GET("/api/philosophies/:id", pyh.Get)
 
 	// Themes — the perennial threads across traditions
-	th := handlers.NewThemeHandler(s)
+	th := handlers.NewThemeHandler(repo)
 	r.GET("/api/themes", th.List)
 	r.GET("/api/themes/:id", th.Get)
+	r.GET("/api/themes/:id/parallels", th.Parallels)
 
 	// Evidence — neuroscience & neuropsychology
-	eh := handlers.NewEvidenceHandler(s)
+	eh := handlers.NewEvidenceHandler(repo)
 	r.GET("/api/evidence", eh.List)
 	r.GET("/api/evidence/:id", eh.Get)
 
+	// Search — ranked full-text search with snippets and facets, over
+	// quotes or every kind of entity
+	sh := handlers.NewSearchHandler(repo)
+	r.GET("/api/search", sh.Search)
+	r.GET("/api/search/all", sh.All)
+
+	// Graph — the corpus as a typed graph for Gephi and Graphviz
+	r.GET("/api/graph", handlers.NewGraphHandler(repo).Graph)
+
+	// Analytics — how themes and traditions co-occur, for editors
+	r.GET("/api/analytics/cooccurrence", handlers.NewAnalyticsHandler(repo).Cooccurrence)
+
+	// Export — the whole corpus as NDJSON or JSON-LD, streamed
+	r.GET("/api/export", handlers.NewExportHandler(repo).Export)
+
+	// --- Feeds (RSS and Atom, for feed readers) ---
+
+	fh := handlers.NewFeedHandler(repo, opts.Daily)
+	r.GET("/feeds/daily.xml", fh.Daily)
+	r.GET("/feeds/daily.atom", fh.Daily)
+	r.GET("/feeds/new-quotes.xml", fh.NewQuotes)
+	r.GET("/feeds/new-quotes.atom", fh.NewQuotes)
+	r.GET("/feeds/themes/:file", fh.Theme)
+	r.GET("/feeds/traditions/:file", fh.Tradition)
+
+	// --- Write API (curation) ---
+
+	w := r.Group("/api", handlers.RequireToken(opts.Curators), handlers.SameOrigin())
+	writes(w, "/quotes", handlers.NewQuoteWriteHandler(repo, opts.Workflow))
+	writes(w, "/philosophers", handlers.NewPhilosopherWriteHandler(repo))
+	writes(w, "/philosophies", handlers.NewPhilosophyWriteHandler(repo))
+	writes(w, "/themes", handlers.NewThemeWriteHandler(repo))
+	writes(w, "/evidence", handlers.NewEvidenceWriteHandler(repo))
+
+	// Revision history — every write above is recorded
+	revisions(w, "/quotes", handlers.NewRevisionHandler(repo, "quote"))
+	revisions(w, "/philosophers", handlers.NewRevisionHandler(repo, "philosopher"))
+	revisions(w, "/philosophies", handlers.NewRevisionHandler(repo, "philosophy"))
+	revisions(w, "/themes", handlers.NewRevisionHandler(repo, "theme"))
+	revisions(w, "/evidence", handlers.NewRevisionHandler(repo, "evidence"))
+
+	// Editorial workflow — drafts reach the public only once published
+	wf := handlers.NewWorkflowHandler(repo, opts.Workflow)
+	w.POST("/quotes/:id/status", wf.Status)
+	w.GET("/review", wf.Review)
+
 	// --- HTML Pages (HTMX + Tailwind) ---
 
-	pages := handlers.NewPages(q, tmpl)
+	pages := handlers.NewPages(repo, tmpl, opts.Daily, opts.Weights)
 
 	r.GET("/", pages.Home)
 	r.GET("/partials/random-quote", pages.RandomQuotePartial)
+	r.GET("/partials/today", pages.TodayPartial)
+	r.GET("/partials/quotes", pages.QuotesPartial)
+	r.GET("/partials/search", pages.SearchPartial)
+	r.GET("/partials/graph", pages.GraphPartial)
 
 	r.GET("/pages/quotes", pages.Quotes)
+	r.GET("/pages/quotes/:id", pages.QuoteDetail)
+	r.GET("/pages/search", pages.Search)
 	r.GET("/pages/philosophers", pages.Philosophers)
 	r.GET("/pages/philosophers/:id", pages.PhilosopherDetail)
 	r.GET("/pages/philosophies", pages.Philosophies)
@@ -62,6 +135,52 @@ func Setup(s *store.Store, q *db.Queries, tmpl *template.Template) *gin.Engine {
 	r.GET("/pages/themes/:id", pages.ThemeDetail)
 	r.GET("/pages/evidence", pages.Evidence)
 	r.GET("/pages/evidence/:id", pages.EvidenceDetail)
+	r.GET("/pages/graph", pages.Graph)
+	r.GET("/pages/cooccurrence", pages.Cooccurrence)
+
+	// --- Admin UI (curation in the browser) ---
+
+	admin := handlers.NewAdmin(repo, tmpl, opts.Workflow)
+	a := r.Group("/admin", handlers.RequireLogin(opts.Curators), handlers.SameOrigin())
+	a.GET("", admin.Dashboard)
+	a.GET("/review", admin.Review)
+	a.GET("/import", admin.ImportForm)
+	a.POST("/import", admin.Import)
+	a.POST("/quotes/preview", admin.QuotePreview)
+	a.POST("/quotes/:id/status", admin.QuoteStatus)
+	adminScreens(a, "/quotes", admin.Quotes())
+	adminScreens(a, "/philosophers", admin.Philosophers())
+	adminScreens(a, "/philosophies", admin.Philosophies())
+	adminScreens(a, "/themes", admin.Themes())
+	adminScreens(a, "/evidence", admin.Evidence())
 
 	return r
 }
+
+// writes registers the four write routes for one collection.
+func writes[T any](g *gin.RouterGroup, path string, h *handlers.WriteHandler[T]) {
+	g.POST(path, h.Create)
+	g.PUT(path+"/:id", h.Replace)
+	g.PATCH(path+"/:id", h.Patch)
+	g.DELETE(path+"/:id", h.Delete)
+}
+
+// revisions registers the history routes for one collection.
+func revisions(g *gin.RouterGroup, path string, h *handlers.RevisionHandler) {
+	g.GET(path+"/:id/revisions", h.List)
+	g.GET(path+"/:id/revisions/:n", h.Get)
+	g.POST(path+"/:id/revisions/:n/rollback", h.Rollback)
+}
+
+// adminScreens registers the admin routes for one collection.
+func adminScreens[T any](g *gin.RouterGroup, path string, h *handlers.AdminResource[T]) {
+	g.GET(path, h.List)
+	g.GET(path+"/new", h.New)
+	g.POST(path, h.Create)
+	g.GET(path+"/:id", h.Edit)
+	g.POST(path+"/:id", h.Update)
+	g.POST(path+"/:id/delete", h.Delete)
+	g.GET(path+"/:id/revisions", h.Revisions)
+	g.GET(path+"/:id/revisions/:n", h.Revision)
+	g.POST(path+"/:id/revisions/:n/rollback", h.Rollback)
+}
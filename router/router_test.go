@@ -1,9 +1,12 @@
 package router_test
 
 import (
+	"bytes"
 	"database/sql"
 	"encoding/json"
+	"encoding/xml"
 	"html/template"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -14,6 +17,7 @@ import (
 	_ "modernc.org/sqlite"
 
 	"perennial-wisdom/db"
+	"perennial-wisdom/handlers"
 	"perennial-wisdom/router"
 	"perennial-wisdom/store"
 )
@@ -22,31 +26,41 @@ func init() {
 	gin.SetMode(gin.TestMode)
 }
 
+// testToken is curator ana's token, which the test router accepts for
+// writes; secondToken is ben's.
+const (
+	testToken   = "test-admin-token"
+	secondToken = "second-admin-token"
+)
+
 // setupTestRouter creates a fully-wired router with in-memory DB and minimal templates.
 func setupTestRouter(t *testing.T) *gin.Engine {
 	t.Helper()
 
-	// In-memory store for JSON API
-	s := store.New()
-
-	// In-memory SQLite for HTML pages
+	// In-memory SQLite behind the shared repository
 	conn, err := sql.Open("sqlite", ":memory:")
 	if err != nil {
 		t.Fatalf("failed to open in-memory db: %v", err)
 	}
+	conn.SetMaxOpenConns(1) // one connection, or each gets its own empty :memory: database
 	conn.Exec("PRAGMA foreign_keys=ON")
 	database := sqlx.NewDb(conn, "sqlite")
 
 	db.Migrate(database)
 	db.Seed(database)
 
-	q := db.NewQueries(database)
+	repo := db.NewRepository(db.NewQueries(database))
 
 	// Minimal template set for testing — each named template produces predictable output
-	tmpl := template.Must(template.New("base").Parse(`{{define "base"}}<!DOCTYPE html><title>{{.Title}}</title>{{end}}`))
-	template.Must(tmpl.New("random-quote").Parse(`{{define "random-quote"}}<q>{{.Text}}</q>{{end}}`))
+	tmpl := template.Must(template.New("base").Parse(`{{define "base"}}<!DOCTYPE html><title>{{.Title}}</title>{{range .Items}}<li>{{.ID}}</li>{{end}}{{end}}`))
+	template.Must(tmpl.New("random-quote").Parse(`{{define "random-quote"}}<q>{{.Text}}</q><button hx-get="{{.Another}}"></button>{{end}}`))
+	template.Must(tmpl.New("quote-page").Parse(`{{define "quote-page"}}{{range .Quotes}}<q>{{.Text}}</q>{{end}}{{if .Next}}<button hx-get="{{.Next}}"></button>{{end}}{{end}}`))
+	template.Must(tmpl.New("search-results").Parse(`{{define "search-results"}}{{range .Hits}}<p>{{.Snippet}}</p>{{end}}{{end}}`))
+	template.Must(tmpl.New("graph-view").Parse(`{{define "graph-view"}}<svg>{{range .Nodes}}<a hx-get="{{.Partial}}"><circle/></a>{{end}}</svg>{{end}}`))
+	template.Must(tmpl.New("quote-card").Parse(`{{define "quote-card"}}<q>{{.Text}}</q> — {{.PhilosopherName}}{{end}}`))
+	template.Must(tmpl.New("today-quote").Parse(`{{define "today-quote"}}<q>{{.Quote.Text}}</q>{{end}}`))
 
-	return router.Setup(s, q, tmpl)
+	return router.Setup(repo, tmpl, router.Options{Curators: handlers.Curators{"ana": testToken, "ben": secondToken}})
 }
 
 // ---- Route Existence ----
@@ -183,6 +197,404 @@ func TestAPIEvidenceIntegration(t *testing.T) {
 	}
 }
 
+func TestAPIExport(t *testing.T) {
+	r := setupTestRouter(t)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/export", nil)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "application/x-ndjson") {
+		t.Fatalf("ndjson: expected 200 application/x-ndjson, got %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	var first map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil || first["type"] != "philosophy" {
+		t.Errorf("first line: %s (%v)", lines[0], err)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/export?format=jsonld", nil)
+	req.Host = "wisdom.example"
+	r.ServeHTTP(w, req)
+	var doc map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil || w.Header().Get("Content-Type") != "application/ld+json; charset=utf-8" {
+		t.Fatalf("jsonld: %q (%v)", w.Header().Get("Content-Type"), err)
+	}
+	if ctx := doc["@context"].(map[string]any); ctx["@base"] != "http://wisdom.example/" {
+		t.Errorf("@base should follow the request host, got %v", ctx["@base"])
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/export?format=xml", nil)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("unknown format: expected 400, got %d", w.Code)
+	}
+}
+
+func TestFeeds(t *testing.T) {
+	r := setupTestRouter(t)
+	get := func(path string, header ...string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		req.Host = "wisdom.example"
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	for path, want := range map[string]string{
+		"/feeds/daily.xml":             "application/rss+xml",
+		"/feeds/daily.atom":            "application/atom+xml",
+		"/feeds/new-quotes.xml":        "application/rss+xml",
+		"/feeds/new-quotes.atom":       "application/atom+xml",
+		"/feeds/themes/death.xml":      "application/rss+xml",
+		"/feeds/traditions/stoic.atom": "application/atom+xml",
+	} {
+		w := get(path)
+		if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), want) {
+			t.Errorf("%s: expected %s, got %d %q", path, want, w.Code, w.Header().Get("Content-Type"))
+			continue
+		}
+		if err := xml.Unmarshal(w.Body.Bytes(), new(struct{})); err != nil {
+			t.Errorf("%s: not XML: %v", path, err)
+		}
+		if !strings.Contains(w.Body.String(), "http://wisdom.example"+path) {
+			t.Errorf("%s: no self link", path)
+		}
+	}
+
+	w := get("/feeds/themes/death.xml")
+	if !strings.Contains(w.Body.String(), `<guid isPermaLink="true">http://wisdom.example/pages/quotes/`) {
+		t.Errorf("expected quote pages as GUIDs:\n%s", w.Body.String())
+	}
+	modified := w.Header().Get("Last-Modified")
+	if w := get("/feeds/themes/death.xml", "If-Modified-Since", modified); w.Code != http.StatusNotModified {
+		t.Errorf("expected 304 for an unchanged feed, got %d", w.Code)
+	}
+
+	for _, path := range []string{"/feeds/themes/nope.xml", "/feeds/traditions/nope.atom", "/feeds/themes/death.json"} {
+		if w := get(path); w.Code != http.StatusNotFound {
+			t.Errorf("%s: expected 404, got %d", path, w.Code)
+		}
+	}
+}
+
+func TestAPISearch(t *testing.T) {
+	r := setupTestRouter(t)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/search?q=mind&limit=1", nil)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var body struct {
+		Total  int
+		Hits   []struct{ Snippet string }
+		Facets map[string][]struct{ ID string }
+		Next   *string
+	}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if body.Total == 0 || len(body.Hits) != 1 || !strings.Contains(body.Hits[0].Snippet, "<mark>") {
+		t.Errorf("expected a highlighted hit, got %s", w.Body.String())
+	}
+	if len(body.Facets["tradition"]) == 0 || len(body.Facets["theme"]) == 0 {
+		t.Errorf("expected tradition and theme facets, got %v", body.Facets)
+	}
+	if body.Total > 1 && (body.Next == nil || !strings.Contains(*body.Next, "offset=1")) {
+		t.Errorf("expected a next page link, got %v", body.Next)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/search?q=Epictetos", nil)
+	r.ServeHTTP(w, req)
+	var named struct{ Names []struct{ Kind, ID string } }
+	json.Unmarshal(w.Body.Bytes(), &named)
+	if len(named.Names) == 0 || named.Names[0].ID != "epictetus" {
+		t.Errorf("expected a rough name match, got %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/search?q=mind&offset=x", nil)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("bad offset: expected 400, got %d", w.Code)
+	}
+}
+
+func TestAPISearchAll(t *testing.T) {
+	r := setupTestRouter(t)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/search/all?q=death&limit=2", nil)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var body struct {
+		Total  int
+		Counts map[string]int
+		Quotes struct{ Total int }
+		Groups []struct {
+			Kind  string
+			Total int
+			Hits  []struct{ ID string }
+		}
+	}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if body.Counts["quote"] != body.Quotes.Total || body.Counts["theme"] == 0 || body.Counts["evidence"] == 0 {
+		t.Errorf("counts: %v", body.Counts)
+	}
+	for _, g := range body.Groups {
+		if len(g.Hits) > 2 || g.Total != body.Counts[g.Kind] {
+			t.Errorf("group %s: %d hits, total %d", g.Kind, len(g.Hits), g.Total)
+		}
+	}
+}
+
+func TestAPIQuoteRelated(t *testing.T) {
+	r := setupTestRouter(t)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/quotes/e1/related?limit=3", nil)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var body struct {
+		Count   int
+		Related []struct {
+			Quote          struct{ ID string }
+			Philosophy     string
+			Score          float64
+			CrossTradition bool `json:"cross_tradition"`
+		}
+	}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if body.Count != 3 || len(body.Related) != 3 {
+		t.Fatalf("expected 3 related quotes, got %s", w.Body.String())
+	}
+	cross := 0
+	for i, rel := range body.Related {
+		if rel.Quote.ID == "e1" || rel.Philosophy == "" || i > 0 && rel.Score > body.Related[i-1].Score {
+			t.Errorf("related[%d]: %+v", i, rel)
+		}
+		if rel.CrossTradition {
+			cross++
+		}
+	}
+	if cross == 0 {
+		t.Errorf("expected quotes from other traditions: %s", w.Body.String())
+	}
+
+	for path, want := range map[string]int{
+		"/api/quotes/nope/related":       http.StatusNotFound,
+		"/api/quotes/e1/related?limit=0": http.StatusBadRequest,
+		"/pages/quotes/e1":               http.StatusOK,
+		"/pages/quotes/nope":             http.StatusNotFound,
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		r.ServeHTTP(w, req)
+		if w.Code != want {
+			t.Errorf("%s: expected %d, got %d", path, want, w.Code)
+		}
+	}
+}
+
+func TestAPIQuoteToday(t *testing.T) {
+	r := setupTestRouter(t)
+	get := func(path string) (*httptest.ResponseRecorder, map[string]any) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		r.ServeHTTP(w, req)
+		var body map[string]any
+		json.Unmarshal(w.Body.Bytes(), &body)
+		return w, body
+	}
+
+	w, christmas := get("/api/quotes/today?date=2026-12-25")
+	if w.Code != http.StatusOK || christmas["date"] != "2026-12-25" || christmas["philosopher"] == "" {
+		t.Fatalf("expected the quote for 2026-12-25, got %d: %s", w.Code, w.Body.String())
+	}
+	if _, again := get("/api/quotes/today?date=2026-12-25&tz=Asia/Tokyo"); again["quote"].(map[string]any)["id"] != christmas["quote"].(map[string]any)["id"] {
+		t.Errorf("same day, different quote: %v vs %v", again["quote"], christmas["quote"])
+	}
+
+	// Fourteen hours ahead of UTC and eleven behind are never on the same day.
+	w, east := get("/api/quotes/today?tz=Pacific/Kiritimati")
+	_, west := get("/api/quotes/today?tz=Pacific/Pago_Pago")
+	if east["date"] == west["date"] || east["timezone"] != "Pacific/Kiritimati" {
+		t.Errorf("expected different days east and west: %v, %v", east["date"], west["date"])
+	}
+	if !strings.HasPrefix(w.Header().Get("Cache-Control"), "public, max-age=") {
+		t.Errorf("expected today's quote to be cacheable, got %q", w.Header().Get("Cache-Control"))
+	}
+
+	for path, want := range map[string]int{
+		"/api/quotes/today?tz=Mars/Olympus":  http.StatusBadRequest,
+		"/api/quotes/today?date=25/12/2026":  http.StatusBadRequest,
+		"/partials/today?tz=Europe/Paris":    http.StatusOK,
+		"/partials/today?tz=not-a-time-zone": http.StatusOK,
+	} {
+		if w, _ := get(path); w.Code != want {
+			t.Errorf("%s: expected %d, got %d", path, want, w.Code)
+		}
+	}
+}
+
+func TestAPIThemeParallels(t *testing.T) {
+	r := setupTestRouter(t)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/themes/death/parallels", nil)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var body struct {
+		Theme struct {
+			PhilosophyIDs []string `json:"philosophy_ids"`
+		}
+		Traditions []struct {
+			PhilosophyID string `json:"philosophy_id"`
+			Gap          bool
+			Count        int
+			Quotes       []struct{ Philosopher string }
+		}
+		Gaps    []string
+		Covered int
+	}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if len(body.Traditions) < len(body.Theme.PhilosophyIDs) || body.Covered+len(body.Gaps) != len(body.Theme.PhilosophyIDs) {
+		t.Fatalf("every listed tradition needs a column: %s", w.Body.String())
+	}
+	for i, pid := range body.Theme.PhilosophyIDs {
+		col := body.Traditions[i]
+		if col.PhilosophyID != pid || col.Gap != (col.Count == 0) || len(col.Quotes) != col.Count {
+			t.Errorf("column %d: %+v", i, col)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/themes/nope/parallels", nil)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("unknown theme: expected 404, got %d", w.Code)
+	}
+}
+
+func TestAPIGraph(t *testing.T) {
+	r := setupTestRouter(t)
+
+	for path, want := range map[string]string{
+		"/api/graph":                               "application/json",
+		"/api/graph?format=graphml":                "application/graphml+xml",
+		"/api/graph?format=dot&root=stoic&depth=2": "text/vnd.graphviz",
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), want) {
+			t.Errorf("%s: %d %s", path, w.Code, w.Header().Get("Content-Type"))
+		}
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/graph?root=stoic&depth=1", nil)
+	r.ServeHTTP(w, req)
+	var body struct {
+		Graph struct {
+			Nodes map[string]struct{ Metadata struct{ Kind string } }
+			Edges []struct{ Source, Target string }
+		}
+	}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if _, ok := body.Graph.Nodes["philosophy:stoic"]; !ok || len(body.Graph.Nodes) < 2 {
+		t.Fatalf("neighborhood of stoic: %s", w.Body.String())
+	}
+	for _, e := range body.Graph.Edges {
+		if body.Graph.Nodes[e.Source].Metadata.Kind == "" || body.Graph.Nodes[e.Target].Metadata.Kind == "" {
+			t.Errorf("edge leaves the subgraph: %+v", e)
+		}
+	}
+
+	for path, want := range map[string]int{
+		"/api/graph?format=svg":      http.StatusBadRequest,
+		"/api/graph?root=x&depth=-1": http.StatusBadRequest,
+		"/api/graph?root=nope":       http.StatusNotFound,
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		r.ServeHTTP(w, req)
+		if w.Code != want {
+			t.Errorf("%s: expected %d, got %d", path, want, w.Code)
+		}
+	}
+}
+
+func TestAPICooccurrence(t *testing.T) {
+	r := setupTestRouter(t)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/analytics/cooccurrence", nil)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	type matrix struct {
+		Universe string
+		Total    int
+		Rows     []struct{ ID string }
+		Cols     []struct{ ID string }
+		Cells    [][]struct {
+			Count   int
+			PMI     *float64
+			Jaccard float64
+		}
+	}
+	var body struct {
+		Quotes             int
+		ThemeTradition     matrix `json:"theme_tradition"`
+		ThemeTheme         matrix `json:"theme_theme"`
+		TraditionTradition matrix `json:"tradition_tradition"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if body.Quotes == 0 {
+		t.Fatalf("no quotes counted: %s", w.Body.String())
+	}
+	for name, m := range map[string]matrix{"theme_tradition": body.ThemeTradition, "theme_theme": body.ThemeTheme, "tradition_tradition": body.TraditionTradition} {
+		if len(m.Rows) == 0 || len(m.Cells) != len(m.Rows) || len(m.Cells[0]) != len(m.Cols) {
+			t.Errorf("%s: %d rows, %d cols, %d cell rows", name, len(m.Rows), len(m.Cols), len(m.Cells))
+			continue
+		}
+		filled := 0
+		for _, row := range m.Cells {
+			for _, c := range row {
+				if (c.Count > 0) != (c.PMI != nil) || c.Jaccard < 0 || c.Jaccard > 1 {
+					t.Errorf("%s: bad cell %+v", name, c)
+				}
+				if c.Count > 0 {
+					filled++
+				}
+			}
+		}
+		if filled == 0 {
+			t.Errorf("%s is empty", name)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/pages/cooccurrence", nil)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("/pages/cooccurrence: expected 200, got %d", w.Code)
+	}
+}
+
 // ---- HTML Pages Integration ----
 
 func TestPageHome(t *testing.T) {
@@ -212,6 +624,641 @@ func TestPageRandomQuotePartial(t *testing.T) {
 	if w.Code != http.StatusOK {
 		t.Errorf("/partials/random-quote: expected 200, got %d", w.Code)
 	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/partials/random-quote?theme=death&seed=s&n=2", nil)
+	r.ServeHTTP(w, req)
+	if !strings.Contains(w.Body.String(), `hx-get="/partials/random-quote?n=3&amp;seed=s&amp;theme=death"`) {
+		t.Errorf("expected another to step on through the seed: %s", w.Body.String())
+	}
+}
+
+func TestAPIQuoteRandomSeeded(t *testing.T) {
+	r := setupTestRouter(t)
+	type pick struct {
+		Quote struct {
+			ID            string
+			PhilosopherID string `json:"philosopher_id"`
+		}
+		Seed string
+		N    int
+		Next string
+	}
+	get := func(path string) (int, pick) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		r.ServeHTTP(w, req)
+		var p pick
+		json.Unmarshal(w.Body.Bytes(), &p)
+		return w.Code, p
+	}
+
+	code, first := get("/api/quotes/random?philosopher=epictetus")
+	if code != http.StatusOK || first.Seed == "" || first.N != 0 || first.Quote.PhilosopherID != "epictetus" {
+		t.Fatalf("expected a seeded Epictetus quote, got %d %+v", code, first)
+	}
+	if _, again := get("/api/quotes/random?philosopher=epictetus&seed=" + first.Seed); again.Quote.ID != first.Quote.ID {
+		t.Errorf("seed %s gave %s, then %s", first.Seed, first.Quote.ID, again.Quote.ID)
+	}
+
+	// Following "next" shows each of his quotes once.
+	seen := map[string]bool{}
+	for p := first; !seen[p.Quote.ID]; _, p = get(p.Next) {
+		if p.Quote.PhilosopherID != "epictetus" {
+			t.Fatalf("filter lost at %d: %+v", p.N, p)
+		}
+		seen[p.Quote.ID] = true
+	}
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/quotes?philosopher=epictetus", nil)
+	r.ServeHTTP(w, req)
+	var list struct{ Count int }
+	json.Unmarshal(w.Body.Bytes(), &list)
+	if len(seen) != list.Count {
+		t.Errorf("saw %d of his %d quotes before a repeat", len(seen), list.Count)
+	}
+
+	for path, want := range map[string]int{
+		"/api/quotes/random?evidence_backed=true": http.StatusOK,
+		"/api/quotes/random?evidence_backed=most": http.StatusBadRequest,
+		"/api/quotes/random?n=-1":                 http.StatusBadRequest,
+		"/api/quotes/random?philosopher=nobody":   http.StatusNotFound,
+		"/partials/random-quote?n=x":              http.StatusBadRequest,
+	} {
+		if code, _ := get(path); code != want {
+			t.Errorf("%s: expected %d, got %d", path, want, code)
+		}
+	}
+}
+
+func TestPageGraphExplorer(t *testing.T) {
+	r := setupTestRouter(t)
+
+	for path, want := range map[string]int{
+		"/pages/graph":                       http.StatusOK,
+		"/pages/graph?root=stoic&depth=2":    http.StatusOK,
+		"/partials/graph?root=theme:control": http.StatusOK,
+		"/pages/graph?root=nope":             http.StatusNotFound,
+		"/partials/graph?root=stoic&depth=0": http.StatusBadRequest,
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		r.ServeHTTP(w, req)
+		if w.Code != want {
+			t.Errorf("%s: expected %d, got %d", path, want, w.Code)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/partials/graph?root=stoic", nil)
+	r.ServeHTTP(w, req)
+	body := w.Body.String()
+	// Each node reloads the drawing around itself; quotes aren't drawn.
+	if !strings.Contains(body, `hx-get="/partials/graph?depth=1&amp;root=philosophy%3Astoic"`) ||
+		!strings.Contains(body, "root=philosopher%3Aepictetus") || strings.Contains(body, "root=quote") {
+		t.Errorf("partial: %s", body)
+	}
+}
+
+func TestPageSearchPartial(t *testing.T) {
+	r := setupTestRouter(t)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/partials/search?q=mind", nil)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "<mark>mind</mark>") {
+		t.Errorf("expected highlighted hits, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestPageQuotesPartialLoadsMore(t *testing.T) {
+	r := setupTestRouter(t)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/partials/quotes?limit=2&tradition=stoic", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("/partials/quotes: expected 200, got %d", w.Code)
+	}
+	body := w.Body.String()
+	if strings.Count(body, "<q>") != 2 {
+		t.Errorf("expected 2 quotes, got: %s", body)
+	}
+	if !strings.Contains(body, `hx-get="/partials/quotes?cursor=`) || !strings.Contains(body, "tradition=stoic") {
+		t.Errorf("expected a load-more link keeping the filter, got: %s", body)
+	}
+}
+
+// ---- Write API ----
+
+func TestWriteAPIRequiresToken(t *testing.T) {
+	r := setupTestRouter(t)
+
+	for _, auth := range []string{"", "Bearer wrong", "Basic " + testToken} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", "/api/quotes/e1", nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Authorization %q: expected 401, got %d", auth, w.Code)
+		}
+	}
+
+	// The admin UI's Basic login doesn't reach the API, so a browser
+	// logged in to /admin can't be steered into API writes.
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/quotes/e1/revisions/1/rollback", nil)
+	req.SetBasicAuth("editor", testToken)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Basic auth on the API: expected 401, got %d", w.Code)
+	}
+}
+
+func TestWriteAPIRefusesCrossSiteRequests(t *testing.T) {
+	r := setupTestRouter(t)
+	send := func(path, body string, header ...string) int {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+testToken)
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if got := send("/api/quotes/e1/revisions/1/rollback", "", "Origin", "https://evil.example"); got != http.StatusForbidden {
+		t.Errorf("rollback from another origin: expected 403, got %d", got)
+	}
+	if got := send("/api/quotes/e1/revisions/1/rollback", "", "Sec-Fetch-Site", "cross-site"); got != http.StatusForbidden {
+		t.Errorf("cross-site rollback: expected 403, got %d", got)
+	}
+	for _, ct := range []string{"", "text/plain", "application/x-www-form-urlencoded"} {
+		if got := send("/api/quotes", `{"id":"x1","text":"Smuggled."}`, "Content-Type", ct); got != http.StatusUnsupportedMediaType {
+			t.Errorf("Content-Type %q: expected 415, got %d", ct, got)
+		}
+	}
+	if got := send("/api/quotes", `{"id":"x1","text":"Sent properly."}`, "Content-Type", "application/json; charset=utf-8"); got != http.StatusCreated {
+		t.Errorf("application/json with a charset: expected 201, got %d", got)
+	}
+}
+
+func TestWriteAPIQuoteLifecycle(t *testing.T) {
+	r := setupTestRouter(t)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+testToken)
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	steps := []struct {
+		method, path, body string
+		want               int
+	}{
+		{"POST", "/api/quotes", `{"id":"w1","slug":"w1","text":"Waste no more time.","philosopher_id":"marcus-aurelius","philosophy_id":"stoic","theme_ids":["virtue"]}`, 201},
+		{"POST", "/api/quotes", `{"id":"w1","text":"again"}`, 409},
+		{"POST", "/api/quotes", `{"id":"w2","slug":"w1","text":"slug clash"}`, 409},
+		{"POST", "/api/quotes", `{"id":"w3","text":"x","theme_ids":["nope"]}`, 422},
+		{"POST", "/api/quotes", `{"id":"w4","text":"x","typo_field":1}`, 400},
+		{"PATCH", "/api/quotes/w1", `{"source":"Meditations 10.16"}`, 200},
+		{"PATCH", "/api/quotes/w1", `{"id":"renamed"}`, 400},
+		{"PUT", "/api/quotes/missing", `{"text":"x"}`, 404},
+		{"DELETE", "/api/quotes/w1", ``, 204},
+		{"DELETE", "/api/quotes/w1", ``, 404},
+	}
+	for _, s := range steps {
+		w := send(s.method, s.path, s.body)
+		if w.Code != s.want {
+			t.Fatalf("%s %s %s: expected %d, got %d: %s", s.method, s.path, s.body, s.want, w.Code, w.Body.String())
+		}
+		if s.method == "PATCH" && s.want == 200 {
+			var body map[string]map[string]interface{}
+			json.Unmarshal(w.Body.Bytes(), &body)
+			if body["quote"]["source"] != "Meditations 10.16" || body["quote"]["text"] != "Waste no more time." {
+				t.Errorf("PATCH should merge fields, got %v", body["quote"])
+			}
+		}
+	}
+
+	// A quote's slug names it in the path as well as its id does.
+	const q = `"text":"Waste no more time.","philosopher_id":"marcus-aurelius","philosophy_id":"stoic","theme_ids":["virtue"]`
+	for _, s := range []struct {
+		method, path, body string
+	}{
+		{"POST", "/api/quotes", `{"id":"w5","slug":"waste-no-time",` + q + `}`},
+		{"PATCH", "/api/quotes/waste-no-time", `{"source":"Meditations 10.16"}`},
+		{"PATCH", "/api/quotes/waste-no-time", `{"id":"w5","source_location":"10.16"}`},
+		{"PUT", "/api/quotes/waste-no-time", `{"slug":"waste-no-time",` + q + `}`},
+		{"DELETE", "/api/quotes/waste-no-time", ``},
+	} {
+		w := send(s.method, s.path, s.body)
+		if w.Code/100 != 2 {
+			t.Fatalf("%s %s %s: expected success, got %d: %s", s.method, s.path, s.body, w.Code, w.Body.String())
+		}
+		if s.method == "DELETE" {
+			continue
+		}
+		var body map[string]map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &body)
+		if body["quote"]["id"] != "w5" {
+			t.Errorf("%s %s: expected quote w5, got %v", s.method, s.path, body["quote"])
+		}
+	}
+	if w := send("DELETE", "/api/quotes/w5", ""); w.Code != http.StatusNotFound {
+		t.Errorf("expected w5 already deleted by its slug, got %d", w.Code)
+	}
+}
+
+func TestRevisionAPIHistoryAndRollback(t *testing.T) {
+	r := setupTestRouter(t)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+testToken)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Author", "mallory") // ignored: the token says who
+		req.Header.Set("X-Change-Note", "correct attribution")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := send("PATCH", "/api/quotes/e1", `{"source":"Enchiridion 5"}`); w.Code != http.StatusOK {
+		t.Fatalf("PATCH: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w := send("GET", "/api/quotes/e1/revisions", "")
+	var list struct {
+		Revisions []map[string]interface{} `json:"revisions"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &list)
+	if w.Code != http.StatusOK || len(list.Revisions) != 2 {
+		t.Fatalf("expected baseline + update, got %d: %s", w.Code, w.Body.String())
+	}
+	if rev := list.Revisions[0]; rev["author"] != "ana" || rev["note"] != "correct attribution" || rev["action"] != "update" {
+		t.Errorf("unexpected latest revision: %v", rev)
+	}
+
+	w = send("GET", "/api/quotes/e1/revisions/2", "")
+	var one struct {
+		Changes []map[string]interface{} `json:"changes"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &one)
+	if len(one.Changes) != 1 || one.Changes[0]["field"] != "source" || one.Changes[0]["after"] != "Enchiridion 5" {
+		t.Errorf("expected a single source change, got %s", w.Body.String())
+	}
+
+	if w = send("POST", "/api/quotes/e1/revisions/1/rollback", ""); w.Code != http.StatusOK {
+		t.Fatalf("rollback: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	w = send("GET", "/api/quotes/e1", "")
+	if strings.Contains(w.Body.String(), "Enchiridion 5") {
+		t.Errorf("rollback should restore the original source: %s", w.Body.String())
+	}
+
+	for path, want := range map[string]int{
+		"/api/quotes/e1/revisions/99":   404,
+		"/api/quotes/e1/revisions/zero": 404,
+	} {
+		if w := send("GET", path, ""); w.Code != want {
+			t.Errorf("%s: expected %d, got %d", path, want, w.Code)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/quotes/e1/revisions", nil)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("history without a token: expected 401, got %d", w.Code)
+	}
+}
+
+// ---- Admin UI ----
+
+func TestWorkflowAPI(t *testing.T) {
+	r := setupTestRouter(t)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		if method != "GET" || path == "/api/review" {
+			req.Header.Set("Authorization", "Bearer "+testToken)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	steps := []struct {
+		method, path, body string
+		want               int
+	}{
+		{"POST", "/api/quotes", `{"id":"wf1","text":"Not yet.","status":"published"}`, 422},
+		{"POST", "/api/quotes", `{"id":"wf1","text":"Not yet."}`, 201},
+		{"GET", "/api/quotes/wf1", ``, 404},
+		{"PATCH", "/api/quotes/wf1", `{"status":"published"}`, 422},
+		{"PATCH", "/api/quotes/wf1", `{"source":"Notebook"}`, 200},
+		{"POST", "/api/quotes/wf1/status", `{"status":"retired"}`, 422},
+		{"POST", "/api/quotes/wf1/status", `{"status":"in_review"}`, 200},
+		{"GET", "/api/review", ``, 200},
+		{"POST", "/api/quotes/wf1/status", `{"status":"published","publish_at":"2999-01-01T00:00:00Z"}`, 200},
+		{"GET", "/api/quotes/wf1", ``, 404},
+		{"POST", "/api/quotes/wf1/status", `{"status":"published"}`, 200},
+		{"GET", "/api/quotes/wf1", ``, 200},
+		{"POST", "/api/quotes/missing/status", `{"status":"published"}`, 404},
+	}
+	for _, s := range steps {
+		w := send(s.method, s.path, s.body)
+		if w.Code != s.want {
+			t.Fatalf("%s %s %s: expected %d, got %d: %s", s.method, s.path, s.body, s.want, w.Code, w.Body.String())
+		}
+		if s.path == "/api/review" {
+			var body struct{ Quotes []struct{ ID string } }
+			json.Unmarshal(w.Body.Bytes(), &body)
+			if len(body.Quotes) != 1 || body.Quotes[0].ID != "wf1" {
+				t.Errorf("review queue: expected wf1, got %+v", body.Quotes)
+			}
+		}
+	}
+}
+
+func TestWorkflowAPISecondApprover(t *testing.T) {
+	conn, _ := sql.Open("sqlite", ":memory:")
+	conn.SetMaxOpenConns(1)
+	database := sqlx.NewDb(conn, "sqlite")
+	db.Migrate(database)
+	db.Seed(database)
+	r := router.Setup(db.NewRepository(db.NewQueries(database)), template.New("base"), router.Options{
+		Curators: handlers.Curators{"ana": testToken, "ben": secondToken},
+		Workflow: store.Workflow{SecondApprover: true},
+	})
+	send := func(token, method, path, body string) int {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Author", "ben") // claims nothing: the token names the author
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	steps := []struct {
+		token, method, path, body string
+		want                      int
+	}{
+		{testToken, "POST", "/api/quotes", `{"id":"sa1","text":"Mine."}`, 201},
+		{testToken, "POST", "/api/quotes/sa1/status", `{"status":"in_review"}`, 200},
+		{testToken, "POST", "/api/quotes/sa1/status", `{"status":"published"}`, 422},
+		{secondToken, "POST", "/api/quotes/sa1/status", `{"status":"published"}`, 200},
+		{testToken, "GET", "/api/quotes/sa1", ``, 200},
+		// An edit after publication needs approval like the first text.
+		{secondToken, "PATCH", "/api/quotes/sa1", `{"text":"Mine, revised."}`, 200},
+		{testToken, "GET", "/api/quotes/sa1", ``, 404},
+		{secondToken, "POST", "/api/quotes/sa1/status", `{"status":"published"}`, 422},
+		{testToken, "POST", "/api/quotes/sa1/status", `{"status":"published"}`, 200},
+		{testToken, "GET", "/api/quotes/sa1", ``, 200},
+	}
+	for _, s := range steps {
+		if got := send(s.token, s.method, s.path, s.body); got != s.want {
+			t.Fatalf("%s %s %s: expected %d, got %d", s.method, s.path, s.body, s.want, got)
+		}
+	}
+}
+
+func TestAdminRequiresToken(t *testing.T) {
+	r := setupTestRouter(t)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/admin", nil)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", w.Code)
+	}
+	if !strings.Contains(strings.Join(w.Header().Values("WWW-Authenticate"), ","), "Basic") {
+		t.Errorf("expected a Basic challenge so browsers prompt, got %v", w.Header().Values("WWW-Authenticate"))
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/admin", nil)
+	req.SetBasicAuth("editor", testToken)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("Basic auth with the token: expected 200, got %d", w.Code)
+	}
+}
+
+func TestAdminListsQuotesOfEveryStatus(t *testing.T) {
+	r := setupTestRouter(t)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/quotes", strings.NewReader(`{"id":"draft-1","text":"Not yet.","philosopher_id":"seneca","philosophy_id":"stoic"}`))
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create draft: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	for path, want := range map[string]bool{
+		"/admin/quotes?limit=100":                  true,
+		"/admin/quotes?limit=100&status=draft":     true,
+		"/admin/quotes?limit=100&status=published": false,
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		req.SetBasicAuth("editor", testToken)
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d", path, w.Code)
+		}
+		if got := strings.Contains(w.Body.String(), "<li>draft-1</li>"); got != want {
+			t.Errorf("%s: draft listed %v, want %v", path, got, want)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/admin/quotes?status=lost", nil)
+	req.SetBasicAuth("editor", testToken)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("unknown status: expected 400, got %d", w.Code)
+	}
+}
+
+// A store failure is a 500, not a missing entity.
+func TestAdminReportsStoreFailures(t *testing.T) {
+	conn, _ := sql.Open("sqlite", ":memory:")
+	conn.SetMaxOpenConns(1)
+	database := sqlx.NewDb(conn, "sqlite")
+	db.Migrate(database)
+	db.Seed(database)
+	r := router.Setup(db.NewRepository(db.NewQueries(database)), template.Must(template.New("base").Parse(`{{define "base"}}{{.Error}}{{end}}`)), router.Options{
+		Curators: handlers.Curators{"ana": testToken},
+	})
+	conn.Close()
+
+	for _, path := range []string{"/admin/quotes/e1/delete", "/admin/quotes/e1/revisions/1/rollback"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", path, nil)
+		req.SetBasicAuth("editor", testToken)
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("%s with the database gone: expected 500, got %d: %s", path, w.Code, w.Body.String())
+		}
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, _ := mw.CreateFormFile("file", "sheet.csv")
+	fw.Write([]byte("id,text\nup-1,Uploaded.\n"))
+	mw.WriteField("commit", "1")
+	mw.Close()
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/admin/import", &body)
+	req.SetBasicAuth("editor", testToken)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusInternalServerError || strings.Contains(w.Body.String(), "closed") {
+		t.Errorf("import with the database gone: expected a generic 500, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestAdminQuoteForms(t *testing.T) {
+	r := setupTestRouter(t)
+
+	post := func(path, form string, header ...string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", path, strings.NewReader(form))
+		req.SetBasicAuth("editor", testToken)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post("/admin/quotes", "id=a1&text=Waste+no+more+time.&philosopher_id=marcus-aurelius&philosophy_id=stoic&theme_ids=virtue&theme_ids=death")
+	if w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/admin/quotes/a1?saved=1" {
+		t.Fatalf("create: expected 303 to the edit form, got %d %q", w.Code, w.Header().Get("Location"))
+	}
+
+	w = post("/admin/quotes", "id=a2&text=&theme_ids=nope")
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("invalid form: expected 422, got %d", w.Code)
+	}
+	w = post("/admin/quotes", "id=a1&text=again")
+	if w.Code != http.StatusConflict {
+		t.Errorf("taken id: expected 409, got %d", w.Code)
+	}
+	w = post("/admin/quotes/a1", "text=Edited.&philosopher_id=marcus-aurelius&theme_ids=virtue", "Origin", "https://evil.example")
+	if w.Code != http.StatusForbidden {
+		t.Errorf("cross-origin save: expected 403, got %d", w.Code)
+	}
+	w = post("/admin/quotes/a1", "text=Edited.&philosopher_id=marcus-aurelius&theme_ids=virtue")
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("update: expected 303, got %d: %s", w.Code, w.Body.String())
+	}
+	w = post("/admin/quotes/a1/status", "to=published&change_note=ready")
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("publish: expected 303, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/quotes/a1", nil)
+	r.ServeHTTP(w, req)
+	var body map[string]map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if body["quote"]["text"] != "Edited." || len(body["quote"]["theme_ids"].([]interface{})) != 1 {
+		t.Errorf("update not stored: %v", body["quote"])
+	}
+
+	w = post("/admin/quotes/preview", "text=Unsaved+draft&philosopher_id=marcus-aurelius")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "<q>Unsaved draft</q> — Marcus Aurelius") {
+		t.Errorf("preview: expected the rendered card, got %d %q", w.Code, w.Body.String())
+	}
+
+	for _, path := range []string{"/admin/quotes/a1/revisions", "/admin/quotes/a1/revisions/2"} {
+		w = httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		req.SetBasicAuth("editor", testToken)
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: expected 200, got %d", path, w.Code)
+		}
+	}
+	if w = post("/admin/quotes/a1/revisions/1/rollback", "change_note=undo"); w.Code != http.StatusSeeOther {
+		t.Errorf("rollback: expected 303, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// A scheduled time is the curator's wall clock, in the zone the form sends.
+	if w = post("/admin/quotes/a1/status", "to=published&publish_at=2999-01-01T09:00&tz=Mars/Olympus"); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("unknown zone: expected 422, got %d", w.Code)
+	}
+	if w = post("/admin/quotes/a1/status", "to=published&publish_at=2999-01-01T09:00&tz=Asia/Tokyo"); w.Code != http.StatusSeeOther {
+		t.Fatalf("schedule: expected 303, got %d: %s", w.Code, w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/review", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	r.ServeHTTP(w, req)
+	if !strings.Contains(w.Body.String(), `"publish_at":"2999-01-01T00:00:00Z"`) {
+		t.Errorf("09:00 in Tokyo should be 00:00 UTC: %s", w.Body.String())
+	}
+
+	if w = post("/admin/quotes/a1/delete", ""); w.Code != http.StatusSeeOther {
+		t.Errorf("delete: expected 303, got %d", w.Code)
+	}
+}
+
+func TestAdminImportUpload(t *testing.T) {
+	r := setupTestRouter(t)
+
+	upload := func(name, content string, commit bool) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		fw, _ := mw.CreateFormFile("file", name)
+		fw.Write([]byte(content))
+		if commit {
+			mw.WriteField("commit", "1")
+		}
+		mw.Close()
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/admin/import", &body)
+		req.SetBasicAuth("editor", testToken)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		r.ServeHTTP(w, req)
+		return w
+	}
+	stored := func(id string) bool {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/quotes/"+id+"/revisions", nil)
+		req.Header.Set("Authorization", "Bearer "+testToken)
+		r.ServeHTTP(w, req)
+		return strings.Contains(w.Body.String(), `"action":"create"`)
+	}
+
+	if w := upload("sheet.json", `[{"id":`, true); w.Code != http.StatusBadRequest {
+		t.Errorf("unparseable: expected 400, got %d", w.Code)
+	}
+	sheet := "id,text,philosopher,themes\nup-1,Uploaded.,Epictetus,control\n"
+	if w := upload("sheet.csv", sheet, false); w.Code != http.StatusOK || stored("up-1") {
+		t.Errorf("dry run: expected 200 and nothing stored, got %d", w.Code)
+	}
+	if w := upload("sheet.json", `[{"id":"up-2","text":"Bad.","themes":["nope"]}]`, true); w.Code != http.StatusUnprocessableEntity || stored("up-2") {
+		t.Errorf("problems: expected 422 and nothing stored, got %d", w.Code)
+	}
+	if w := upload("sheet.csv", sheet, true); w.Code != http.StatusOK || !stored("up-1") {
+		t.Errorf("import: expected 200 and up-1 stored, got %d", w.Code)
+	}
 }
 
 // ---- 404 for unknown routes ----
@@ -0,0 +1,137 @@
+package store
+
+import "math"
+
+// Cooccurrence is how often themes and traditions turn up together in
+// the live quotes, counted three ways.
+type Cooccurrence struct {
+	Quotes int `json:"quotes"` // live quotes counted
+
+	// ThemeTradition counts the quotes on each theme from each
+	// tradition: rows are themes, columns traditions.
+	ThemeTradition CoMatrix `json:"theme_tradition"`
+	// ThemeTheme counts the quotes carrying both of two themes. The
+	// diagonal holds each theme's own count.
+	ThemeTheme CoMatrix `json:"theme_theme"`
+	// TraditionTradition counts the themes two traditions both have
+	// quotes on — where they meet.
+	TraditionTradition CoMatrix `json:"tradition_tradition"`
+}
+
+// CoMatrix is a co-occurrence matrix over a universe of items, quotes
+// or themes: how many items fall under both a row and a column label.
+type CoMatrix struct {
+	Universe string     `json:"universe"` // "quotes" or "themes"
+	Total    int        `json:"total"`    // items in the universe
+	Rows     []CoLabel  `json:"rows"`
+	Cols     []CoLabel  `json:"cols"`
+	Cells    [][]CoCell `json:"cells"` // Cells[row][col]
+}
+
+// CoLabel is a theme or tradition heading a row or column, with the
+// number of items it labels.
+type CoLabel struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// CoCell is one pair of labels. PMI is the pointwise mutual information
+// in bits — above 0 when the pair meets more often than chance would
+// have it, null when it never does. Jaccard is the share of the items
+// under either label that are under both.
+type CoCell struct {
+	Count   int      `json:"count"`
+	PMI     *float64 `json:"pmi"`
+	Jaccard float64  `json:"jaccard"`
+}
+
+// Cooccur counts how themes and traditions co-occur in the live quotes.
+// Every theme and tradition gets a row and column, in ID order, even
+// with nothing to count; quotes' links to themes that don't exist are
+// ignored.
+func Cooccur(repo Repository) (Cooccurrence, error) {
+	var co Cooccurrence
+	quotes, _, err := repo.ListQuotes(nil, Page{})
+	if err != nil {
+		return co, err
+	}
+	themes, _, err := repo.ListThemes(Page{})
+	if err != nil {
+		return co, err
+	}
+	traditions, _, err := repo.ListPhilosophies(Page{})
+	if err != nil {
+		return co, err
+	}
+
+	themeQuotes := map[string]map[string]bool{}     // theme → its quotes
+	traditionQuotes := map[string]map[string]bool{} // tradition → its quotes
+	traditionThemes := map[string]map[string]bool{} // tradition → themes it has quotes on
+	var themeLabels, traditionLabels []CoLabel
+	for _, t := range themes {
+		themeQuotes[t.ID] = map[string]bool{}
+		themeLabels = append(themeLabels, CoLabel{ID: t.ID, Name: t.Name})
+	}
+	for _, p := range traditions {
+		traditionQuotes[p.ID] = map[string]bool{}
+		traditionThemes[p.ID] = map[string]bool{}
+		traditionLabels = append(traditionLabels, CoLabel{ID: p.ID, Name: p.Name})
+	}
+	for _, q := range quotes {
+		if traditionQuotes[q.PhilosophyID] != nil {
+			traditionQuotes[q.PhilosophyID][q.ID] = true
+		}
+		for _, tid := range q.ThemeIDs {
+			if themeQuotes[tid] == nil {
+				continue
+			}
+			themeQuotes[tid][q.ID] = true
+			if traditionThemes[q.PhilosophyID] != nil {
+				traditionThemes[q.PhilosophyID][tid] = true
+			}
+		}
+	}
+
+	co.Quotes = len(quotes)
+	co.ThemeTradition = coMatrix("quotes", len(quotes), themeLabels, themeQuotes, traditionLabels, traditionQuotes)
+	co.ThemeTheme = coMatrix("quotes", len(quotes), themeLabels, themeQuotes, themeLabels, themeQuotes)
+	co.TraditionTradition = coMatrix("themes", len(themes), traditionLabels, traditionThemes, traditionLabels, traditionThemes)
+	return co, nil
+}
+
+// coMatrix crosses two labelings of a universe of total items, each
+// label's items given by its set.
+func coMatrix(universe string, total int, rows []CoLabel, rowSets map[string]map[string]bool, cols []CoLabel, colSets map[string]map[string]bool) CoMatrix {
+	m := CoMatrix{Universe: universe, Total: total, Rows: counted(rows, rowSets), Cols: counted(cols, colSets)}
+	m.Cells = make([][]CoCell, len(m.Rows))
+	for i, r := range m.Rows {
+		m.Cells[i] = make([]CoCell, len(m.Cols))
+		for j, c := range m.Cols {
+			both := 0
+			for item := range rowSets[r.ID] {
+				if colSets[c.ID][item] {
+					both++
+				}
+			}
+			cell := CoCell{Count: both}
+			if both > 0 {
+				pmi := round6(math.Log2(float64(both) * float64(total) / float64(r.Count*c.Count)))
+				cell.PMI = &pmi
+				cell.Jaccard = round6(float64(both) / float64(r.Count+c.Count-both))
+			}
+			m.Cells[i][j] = cell
+		}
+	}
+	return m
+}
+
+// counted is labels with their items counted.
+func counted(labels []CoLabel, sets map[string]map[string]bool) []CoLabel {
+	out := make([]CoLabel, len(labels))
+	for i, l := range labels {
+		l.Count = len(sets[l.ID])
+		out[i] = l
+	}
+	return out
+}
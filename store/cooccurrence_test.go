@@ -0,0 +1,87 @@
+package store_test
+
+import (
+	"math"
+	"testing"
+
+	"perennial-wisdom/models"
+	"perennial-wisdom/store"
+)
+
+func TestCooccur(t *testing.T) {
+	s := store.New()
+	ch := store.Change{Author: "ana"}
+	// A corpus of its own: clear the seed quotes, keep the themes and schools.
+	quotes, _, _ := s.ListQuotes(store.Where("status", store.Statuses...), store.Page{})
+	for _, q := range quotes {
+		if err := s.DeleteQuote(q.ID, ch); err != nil {
+			t.Fatalf("DeleteQuote %s: %v", q.ID, err)
+		}
+	}
+	for _, q := range []models.Quote{
+		{ID: "co-1", PhilosopherID: "seneca", PhilosophyID: "stoic", ThemeIDs: []string{"death", "control"}},
+		{ID: "co-2", PhilosopherID: "epictetus", PhilosophyID: "stoic", ThemeIDs: []string{"control"}},
+		{ID: "co-3", PhilosopherID: "buddha", PhilosophyID: "buddhist", ThemeIDs: []string{"death"}},
+		{ID: "co-4", PhilosopherID: "laozi", PhilosophyID: "taoist", ThemeIDs: []string{"death"}, Status: store.StatusDraft},
+	} {
+		q.Text = "Text of " + q.ID
+		if q.Status == "" {
+			q.Status = store.StatusPublished
+		}
+		if err := s.CreateQuote(q, ch); err != nil {
+			t.Fatalf("CreateQuote %s: %v", q.ID, err)
+		}
+	}
+
+	co, err := store.Cooccur(s)
+	if err != nil {
+		t.Fatalf("Cooccur: %v", err)
+	}
+	if co.Quotes != 3 {
+		t.Errorf("quotes: %d", co.Quotes)
+	}
+	cell := func(m store.CoMatrix, row, col string) store.CoCell {
+		t.Helper()
+		for i, r := range m.Rows {
+			for j, c := range m.Cols {
+				if r.ID == row && c.ID == col {
+					return m.Cells[i][j]
+				}
+			}
+		}
+		t.Fatalf("no cell %s × %s", row, col)
+		return store.CoCell{}
+	}
+	pmi := func(c store.CoCell) float64 {
+		if c.PMI == nil {
+			return math.Inf(-1)
+		}
+		return *c.PMI
+	}
+
+	// Two of three quotes are on death, two are Stoic, one is both.
+	if c := cell(co.ThemeTradition, "death", "stoic"); c.Count != 1 || pmi(c) != math.Round(math.Log2(0.75)*1e6)/1e6 || c.Jaccard != 0.333333 {
+		t.Errorf("death × stoic: %+v (pmi %v)", c, pmi(c))
+	}
+	if c := cell(co.ThemeTradition, "control", "stoic"); c.Count != 2 || pmi(c) <= 0 || c.Jaccard != 1 {
+		t.Errorf("control × stoic: %+v", c)
+	}
+	if c := cell(co.ThemeTradition, "death", "taoist"); c.Count != 0 || c.PMI != nil || c.Jaccard != 0 {
+		t.Errorf("drafts don't count: %+v", c)
+	}
+	if c := cell(co.ThemeTheme, "death", "control"); c.Count != 1 || c.Jaccard != 0.333333 {
+		t.Errorf("death × control: %+v", c)
+	}
+	if c := cell(co.ThemeTheme, "death", "death"); c.Count != 2 || c.Jaccard != 1 {
+		t.Errorf("diagonal: %+v", c)
+	}
+	// Stoics and Buddhists meet on one theme, death.
+	if c := cell(co.TraditionTradition, "stoic", "buddhist"); c.Count != 1 || co.TraditionTradition.Universe != "themes" {
+		t.Errorf("stoic × buddhist: %+v", c)
+	}
+
+	themes, _, _ := s.ListThemes(store.Page{})
+	if len(co.ThemeTradition.Rows) != len(themes) || len(co.ThemeTheme.Cols) != len(themes) {
+		t.Errorf("every theme needs a row and column: %d rows for %d themes", len(co.ThemeTradition.Rows), len(themes))
+	}
+}
@@ -0,0 +1,195 @@
+package store
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"perennial-wisdom/models"
+)
+
+// DefaultDailyWindow is how many days pass, by default, before the quote
+// of the day may come round again: a week.
+const DefaultDailyWindow = 7
+
+// PinKey is the quote Meta key editors pin a quote of the day with: a
+// date as YYYY-MM-DD, a list of them, or several in one string
+// separated by commas or spaces (as a CSV import's meta.daily column
+// would have them).
+const PinKey = "daily"
+
+// Daily picks the quote of the day.
+//
+// The pick depends on nothing but the date and the live corpus — no
+// clock, no randomness, no state — so every replica serving the same
+// corpus agrees on it, and a reader sees the quote of their own date
+// wherever they are. The unpinned live quotes are dealt like a deck:
+// each run through it takes as many days as there are quotes, in an
+// order shuffled by hashing the run's number with each quote ID, and
+// arranged so that no run opens with a quote the last one closed on:
+// no quote comes back within Window days of its last showing.
+//
+// Pinned quotes are kept for the days they are pinned to and leave the
+// deck. Publishing, retiring or pinning a quote changes the deck and so
+// deals the days afresh; the window holds between such changes.
+type Daily struct {
+	// Window is how many days must pass before a quote is shown again.
+	// Zero means DefaultDailyWindow. The deck can keep quotes at most
+	// half its size apart; a longer window is cut to that.
+	Window int
+}
+
+// DailyQuote is the quote of the day for one date.
+type DailyQuote struct {
+	Date   string // YYYY-MM-DD
+	Quote  models.Quote
+	Pinned bool // an editor chose it for the date
+	Window int  // the window kept, after cutting to the deck
+}
+
+// Pick returns the quote of the day for date — its year, month and day
+// as they fall in date's own location. ErrNotFound means there is
+// nothing to show: no quote is pinned to the date and none is left in
+// the deck.
+func (d Daily) Pick(repo Repository, date time.Time) (DailyQuote, error) {
+	dk, err := d.load(repo)
+	if err != nil {
+		return DailyQuote{}, err
+	}
+	return dk.pick(date)
+}
+
+// dailyDeck is the live corpus as Daily deals it: the quotes pinned to
+// each date and the deck the other days are dealt from. Loading it once
+// lets a caller pick many days without reading the corpus for each.
+type dailyDeck struct {
+	pins   map[string]models.Quote
+	deck   []models.Quote
+	window int
+}
+
+// load reads the live quotes from repo and sorts them into pins and deck.
+func (d Daily) load(repo Repository) (dailyDeck, error) {
+	quotes, _, err := repo.ListQuotes(nil, Page{})
+	if err != nil {
+		return dailyDeck{}, err
+	}
+
+	pins := map[string]models.Quote{}
+	var deck []models.Quote
+	for _, q := range quotes {
+		dates, err := PinnedDates(q)
+		if err != nil || len(dates) == 0 {
+			deck = append(deck, q)
+			continue
+		}
+		for _, pin := range dates {
+			// Quotes come in ID order, so a clash goes to the first.
+			if _, taken := pins[pin]; !taken {
+				pins[pin] = q
+			}
+		}
+	}
+
+	window := min(max(cmp.Or(d.Window, DefaultDailyWindow), 0), len(deck)/2)
+	return dailyDeck{pins: pins, deck: deck, window: window}, nil
+}
+
+// pick is Pick for a loaded deck.
+func (dk dailyDeck) pick(date time.Time) (DailyQuote, error) {
+	day := date.Format(time.DateOnly)
+	n := len(dk.deck)
+	if q, ok := dk.pins[day]; ok {
+		return DailyQuote{Date: day, Quote: q, Pinned: true, Window: dk.window}, nil
+	}
+	if n == 0 {
+		return DailyQuote{}, ErrNotFound
+	}
+
+	y, m, dd := date.Date()
+	days := int(time.Date(y, m, dd, 0, 0, 0, 0, time.UTC).Unix() / 86400)
+	run, pos := days/n, days%n
+	if pos < 0 {
+		run, pos = run-1, pos+n
+	}
+	return DailyQuote{Date: day, Quote: deal(dk.deck, run, dk.window)[pos], Window: dk.window}, nil
+}
+
+// deal orders the deck for one run. The deck is split for good into
+// two halves by shuffling it once: a run opens with window quotes from
+// the first half and closes with window from the second, the rest in
+// between, each part in the run's shuffled order. As no run closes on
+// a quote the next opens with, more than window days pass between two
+// showings of a quote. It takes a deck of at least two windows.
+func deal(deck []models.Quote, run, window int) []models.Quote {
+	order := shuffle(deck, run)
+	if window == 0 {
+		return order
+	}
+	opener := map[string]bool{}
+	for _, q := range shuffle(deck, -1)[:(len(deck)+1)/2] {
+		opener[q.ID] = true
+	}
+	var head, middle, tail []models.Quote
+	for _, q := range order {
+		if len(head) < window && opener[q.ID] {
+			head = append(head, q)
+		} else {
+			middle = append(middle, q)
+		}
+	}
+	for i := len(middle) - 1; i >= 0 && len(tail) < window; i-- {
+		if q := middle[i]; !opener[q.ID] {
+			tail = append([]models.Quote{q}, tail...)
+			middle = slices.Delete(middle, i, i+1)
+		}
+	}
+	return slices.Concat(head, middle, tail)
+}
+
+// shuffle orders the deck by a hash of each quote ID with run: the same
+// everywhere, and different from one run to the next.
+func shuffle(deck []models.Quote, run int) []models.Quote {
+	key := func(q models.Quote) uint64 { return hash64(run, q.ID) }
+	order := slices.Clone(deck)
+	slices.SortStableFunc(order, func(a, b models.Quote) int {
+		return cmp.Or(cmp.Compare(key(a), key(b)), strings.Compare(a.ID, b.ID))
+	})
+	return order
+}
+
+// PinnedDates returns the dates q is pinned to as quote of the day, as
+// YYYY-MM-DD, read from its Meta under PinKey. It is an error for any
+// of them not to be a date.
+func PinnedDates(q models.Quote) ([]string, error) {
+	var raw []string
+	switch v := q.Meta[PinKey].(type) {
+	case nil:
+		return nil, nil
+	case string:
+		raw = strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ' ' })
+	case []string:
+		raw = v
+	case []any:
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("%v is not a date", item)
+			}
+			raw = append(raw, s)
+		}
+	default:
+		return nil, fmt.Errorf("%v is not a date or list of dates", v)
+	}
+	dates := make([]string, 0, len(raw))
+	for _, s := range raw {
+		s = strings.TrimSpace(s)
+		if _, err := time.Parse(time.DateOnly, s); err != nil {
+			return nil, fmt.Errorf("%q is not a date (YYYY-MM-DD)", s)
+		}
+		dates = append(dates, s)
+	}
+	return dates, nil
+}
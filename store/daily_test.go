@@ -0,0 +1,141 @@
+package store_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"perennial-wisdom/models"
+	"perennial-wisdom/store"
+)
+
+// day is a date in UTC.
+func day(s string) time.Time {
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestDailyPickIsTheSameAllDay(t *testing.T) {
+	s := store.New()
+	first, err := store.Daily{}.Pick(s, day("2026-03-01"))
+	if err != nil {
+		t.Fatalf("Pick: %v", err)
+	}
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	late, _ := store.Daily{}.Pick(s, time.Date(2026, 3, 1, 23, 59, 0, 0, tokyo))
+	if first.Date != "2026-03-01" || late.Quote.ID != first.Quote.ID || first.Pinned {
+		t.Errorf("2026-03-01 gave %s then %s", first.Quote.ID, late.Quote.ID)
+	}
+	if next, _ := (store.Daily{}).Pick(s, day("2026-03-02")); next.Quote.ID == first.Quote.ID {
+		t.Errorf("%s two days running", first.Quote.ID)
+	}
+}
+
+func TestDailyPickKeepsTheWindow(t *testing.T) {
+	s := store.New()
+	live, _, _ := s.ListQuotes(nil, store.Page{})
+	d := store.Daily{Window: 10}
+
+	last := map[string]int{}
+	start := day("2025-11-20")
+	for i := range 3 * len(live) {
+		pick, err := d.Pick(s, start.AddDate(0, 0, i))
+		if err != nil {
+			t.Fatalf("Pick: %v", err)
+		}
+		if pick.Window != 10 {
+			t.Fatalf("window %d, want 10", pick.Window)
+		}
+		if seen, ok := last[pick.Quote.ID]; ok && i-seen <= 10 {
+			t.Errorf("%s on %s, only %d days after it last was", pick.Quote.ID, pick.Date, i-seen)
+		}
+		last[pick.Quote.ID] = i
+	}
+	if len(last) != len(live) {
+		t.Errorf("%d of %d quotes came up in three runs of the deck", len(last), len(live))
+	}
+
+	if pick, _ := (store.Daily{Window: 1000}).Pick(s, start); pick.Window != len(live)/2 {
+		t.Errorf("window %d for a deck of %d", pick.Window, len(live))
+	}
+	if pick, _ := (store.Daily{}).Pick(s, start); pick.Window != store.DefaultDailyWindow {
+		t.Errorf("default window %d", pick.Window)
+	}
+}
+
+func TestDailyPins(t *testing.T) {
+	s := store.New()
+	q, _ := s.GetQuote("s1")
+	q.Meta = map[string]any{store.PinKey: []any{"2026-12-25", "2027-12-25"}}
+	if err := s.UpdateQuote(q, store.Change{Author: "ana"}); err != nil {
+		t.Fatalf("UpdateQuote: %v", err)
+	}
+
+	for _, date := range []string{"2026-12-25", "2027-12-25"} {
+		pick, err := store.Daily{}.Pick(s, day(date))
+		if err != nil || pick.Quote.ID != "s1" || !pick.Pinned {
+			t.Errorf("%s: %+v, %v", date, pick, err)
+		}
+	}
+	for i := range 200 {
+		if pick, _ := (store.Daily{}).Pick(s, day("2026-10-01").AddDate(0, 0, i)); pick.Quote.ID == "s1" && !pick.Pinned {
+			t.Fatalf("pinned quote dealt on %s", pick.Date)
+		}
+	}
+
+	q.Meta = map[string]any{store.PinKey: "2026-12-25, tomorrow"}
+	var verr *store.ValidationError
+	if err := s.UpdateQuote(q, store.Change{Author: "ana"}); !errors.As(err, &verr) || verr.Problems[0].Field != "meta.daily" {
+		t.Errorf("expected a bad pin to be refused, got %v", err)
+	}
+
+	other, _ := s.GetQuote("e1")
+	other.Meta = map[string]any{store.PinKey: "2026-12-25"}
+	if err := s.UpdateQuote(other, store.Change{Author: "ana"}); err != nil {
+		t.Fatalf("UpdateQuote: %v", err)
+	}
+	report, err := store.Validate(s)
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	clashes := 0
+	for _, is := range report.Issues {
+		if is.Check == store.CheckPinClash {
+			clashes++
+			if is.ID != "s1" || is.Severity != store.SeverityWarning {
+				t.Errorf("clash: %+v", is)
+			}
+		}
+	}
+	if clashes != 1 {
+		t.Errorf("expected one clash, got %d", clashes)
+	}
+	if pick, _ := (store.Daily{}).Pick(s, day("2026-12-25")); pick.Quote.ID != "e1" {
+		t.Errorf("a clash goes to the first quote by ID, got %s", pick.Quote.ID)
+	}
+}
+
+func TestPinnedDates(t *testing.T) {
+	for _, tc := range []struct {
+		meta any
+		want int
+		bad  bool
+	}{
+		{nil, 0, false},
+		{"2026-01-01", 1, false},
+		{"2026-01-01, 2026-02-01 2026-03-01", 3, false},
+		{[]string{"2026-01-01"}, 1, false},
+		{[]any{"2026-01-01", "2026-01-02"}, 2, false},
+		{"New Year", 0, true},
+		{[]any{20260101}, 0, true},
+		{true, 0, true},
+	} {
+		dates, err := store.PinnedDates(models.Quote{Meta: map[string]any{store.PinKey: tc.meta}})
+		if (err != nil) != tc.bad || len(dates) != tc.want {
+			t.Errorf("%v: %v, %v", tc.meta, dates, err)
+		}
+	}
+}
@@ -0,0 +1,351 @@
+package store
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"perennial-wisdom/models"
+)
+
+// Export formats.
+const (
+	FormatNDJSON = "ndjson"
+	FormatJSONLD = "jsonld"
+)
+
+// exportPageSize is how many entities Export reads at a time. Only one
+// page is in memory at once, however large the corpus.
+const exportPageSize = 100
+
+// Export streams the public corpus to w: every school, philosopher,
+// theme, evidence entry and live quote, in that order, so references
+// always point back at something already written.
+//
+// FormatNDJSON writes one JSON object per line: an entity
+//
+//	{"type":"quote","id":"e1","data":{...}}
+//
+// followed by one line per relationship it has
+//
+//	{"type":"relation","from":"quote:e1","rel":"theme","to":"theme:control"}
+//
+// FormatJSONLD writes a single schema.org document whose @graph holds a
+// Quotation, Person, DefinedTerm or ScholarlyArticle node per entity.
+// Node IDs are the entities' API URLs, relative to base. Our own terms
+// live under base too if it is an absolute URL, or under a fixed URN if
+// not, so they stay absolute either way.
+//
+// If w has a Flush method it is called after every page.
+func Export(repo Repository, w io.Writer, format, base string) error {
+	bw := bufio.NewWriter(w)
+	var sink exportSink
+	switch format {
+	case FormatNDJSON:
+		sink = &ndjsonSink{enc: json.NewEncoder(bw)}
+	case FormatJSONLD:
+		sink = &jsonldSink{w: bw, base: strings.TrimSuffix(base, "/")}
+	default:
+		return fmt.Errorf("unknown export format %q (use ndjson or jsonld)", format)
+	}
+	flush := func() error {
+		if err := bw.Flush(); err != nil {
+			return err
+		}
+		if f, ok := w.(interface{ Flush() }); ok {
+			f.Flush()
+		}
+		return nil
+	}
+
+	if err := sink.begin(); err != nil {
+		return err
+	}
+	steps := []func() error{
+		func() error {
+			return eachPage(repo.ListPhilosophies, func(p models.Philosophy) string { return NameKey(p.Name, p.ID) }, flush,
+				func(p models.Philosophy) error { return sink.philosophy(p) })
+		},
+		func() error {
+			list := func(p Page) ([]models.Philosopher, Cursors, error) { return repo.ListPhilosophers(nil, p) }
+			return eachPage(list, func(p models.Philosopher) string { return p.ID }, flush,
+				func(p models.Philosopher) error { return sink.philosopher(p) })
+		},
+		func() error {
+			return eachPage(repo.ListThemes, func(t models.Theme) string { return NameKey(t.Name, t.ID) }, flush,
+				func(t models.Theme) error { return sink.theme(t) })
+		},
+		func() error {
+			list := func(p Page) ([]models.Evidence, Cursors, error) { return repo.ListEvidence(nil, p) }
+			return eachPage(list, func(e models.Evidence) string { return e.ID }, flush,
+				func(e models.Evidence) error { return sink.evidence(e) })
+		},
+		func() error {
+			list := func(p Page) ([]models.Quote, Cursors, error) { return repo.ListQuotes(nil, p) }
+			return eachPage(list, func(q models.Quote) string { return q.ID }, flush,
+				func(q models.Quote) error { return sink.quote(q) })
+		},
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return err
+		}
+	}
+	if err := sink.end(); err != nil {
+		return err
+	}
+	return flush()
+}
+
+// eachPage walks a list one page at a time, calling fn for every item
+// and done after every page.
+func eachPage[T any](list func(Page) ([]T, Cursors, error), key func(T) string, done func() error, fn func(T) error) error {
+	p := Page{Limit: exportPageSize}
+	for {
+		items, cur, err := list(p)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+		if err := done(); err != nil {
+			return err
+		}
+		if cur.Next == "" || len(items) == 0 {
+			return nil
+		}
+		p.After = key(items[len(items)-1])
+	}
+}
+
+// exportSink writes entities in one format.
+type exportSink interface {
+	begin() error
+	philosophy(models.Philosophy) error
+	philosopher(models.Philosopher) error
+	theme(models.Theme) error
+	evidence(models.Evidence) error
+	quote(models.Quote) error
+	end() error
+}
+
+// --- NDJSON ---
+
+type ndjsonSink struct {
+	enc *json.Encoder
+}
+
+type ndjsonEntity struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+	Data any    `json:"data"`
+}
+
+type ndjsonRelation struct {
+	Type string `json:"type"` // always "relation"
+	From string `json:"from"`
+	Rel  string `json:"rel"`
+	To   string `json:"to"`
+}
+
+func (s *ndjsonSink) begin() error { return nil }
+func (s *ndjsonSink) end() error   { return nil }
+
+// write encodes an entity line, then a relation line per target.
+func (s *ndjsonSink) write(kind, id string, v any, rels ...relation) error {
+	if err := s.enc.Encode(ndjsonEntity{Type: kind, ID: id, Data: v}); err != nil {
+		return err
+	}
+	for _, r := range rels {
+		for _, to := range r.ids {
+			if to == "" {
+				continue
+			}
+			if err := s.enc.Encode(ndjsonRelation{Type: "relation", From: kind + ":" + id, Rel: r.name, To: r.kind + ":" + to}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// relation is one kind of link from an entity to others.
+type relation struct {
+	name string
+	kind string
+	ids  []string
+}
+
+func (s *ndjsonSink) philosophy(p models.Philosophy) error {
+	return s.write("philosophy", p.ID, p, relation{"related", "philosophy", p.RelatedIDs})
+}
+
+func (s *ndjsonSink) philosopher(p models.Philosopher) error {
+	return s.write("philosopher", p.ID, p, relation{"philosophy", "philosophy", []string{p.PhilosophyID}})
+}
+
+func (s *ndjsonSink) theme(t models.Theme) error {
+	return s.write("theme", t.ID, t, relation{"philosophy", "philosophy", t.PhilosophyIDs})
+}
+
+func (s *ndjsonSink) evidence(e models.Evidence) error {
+	return s.write("evidence", e.ID, e, relation{"theme", "theme", e.ThemeIDs})
+}
+
+func (s *ndjsonSink) quote(q models.Quote) error {
+	return s.write("quote", q.ID, q,
+		relation{"philosopher", "philosopher", []string{q.PhilosopherID}},
+		relation{"philosophy", "philosophy", []string{q.PhilosophyID}},
+		relation{"theme", "theme", q.ThemeIDs},
+		relation{"evidence", "evidence", q.EvidenceIDs},
+	)
+}
+
+// --- JSON-LD ---
+
+// jsonldSink writes {"@context": ..., "@graph": [node, node, ...]} one
+// node at a time.
+type jsonldSink struct {
+	w     *bufio.Writer
+	base  string
+	nodes int
+}
+
+// vocabURN names our vocabulary when the export has no absolute base.
+const vocabURN = "urn:perennial-wisdom:vocab#"
+
+// context maps onto schema.org, with a small vocabulary of our own
+// for the links schema.org has no property for.
+func (s *jsonldSink) context() map[string]any {
+	vocab := vocabURN
+	if u, err := url.Parse(s.base); err == nil && u.IsAbs() {
+		vocab = s.base + "/vocab#"
+	}
+	ctx := map[string]any{
+		"@vocab": "https://schema.org/",
+		"pw":     vocab,
+	}
+	if s.base != "" {
+		ctx["@base"] = s.base + "/"
+	}
+	return ctx
+}
+
+func (s *jsonldSink) begin() error {
+	ctx, err := json.Marshal(s.context())
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(s.w, "{\"@context\":%s,\"@graph\":[\n", ctx)
+	return err
+}
+
+func (s *jsonldSink) end() error {
+	_, err := s.w.WriteString("\n]}\n")
+	return err
+}
+
+func (s *jsonldSink) node(n map[string]any) error {
+	for k, v := range n {
+		if v == "" || v == nil {
+			delete(n, k)
+		}
+		if refs, ok := v.([]map[string]string); ok && len(refs) == 0 {
+			delete(n, k)
+		}
+	}
+	b, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	if s.nodes > 0 {
+		s.w.WriteString(",\n")
+	}
+	s.nodes++
+	_, err = s.w.Write(b)
+	return err
+}
+
+// paths are the API collections entity IRIs point into.
+var paths = map[string]string{
+	"philosophy":  "api/philosophies/",
+	"philosopher": "api/philosophers/",
+	"theme":       "api/themes/",
+	"evidence":    "api/evidence/",
+	"quote":       "api/quotes/",
+}
+
+func iri(kind, id string) string { return paths[kind] + id }
+
+// ref is a link to another node, or nil for an empty ID.
+func ref(kind, id string) any {
+	if id == "" {
+		return nil
+	}
+	return map[string]string{"@id": iri(kind, id)}
+}
+
+func refs(kind string, ids []string) []map[string]string {
+	out := make([]map[string]string, 0, len(ids))
+	for _, id := range ids {
+		if id == "" {
+			continue
+		}
+		out = append(out, map[string]string{"@id": iri(kind, id)})
+	}
+	return out
+}
+
+func (s *jsonldSink) philosophy(p models.Philosophy) error {
+	return s.node(map[string]any{
+		"@id": iri("philosophy", p.ID), "@type": "DefinedTerm", "identifier": p.ID,
+		"name": p.Name, "description": p.Origin, "inDefinedTermSet": "traditions",
+		"pw:principles": p.CorePrinciples, "pw:related": refs("philosophy", p.RelatedIDs),
+	})
+}
+
+func (s *jsonldSink) philosopher(p models.Philosopher) error {
+	return s.node(map[string]any{
+		"@id": iri("philosopher", p.ID), "@type": "Person", "identifier": p.ID,
+		"name": p.Name, "description": p.Bio, "disambiguatingDescription": p.Era,
+		"knowsAbout": ref("philosophy", p.PhilosophyID), "pw:teachings": p.KeyTeachings,
+	})
+}
+
+func (s *jsonldSink) theme(t models.Theme) error {
+	return s.node(map[string]any{
+		"@id": iri("theme", t.ID), "@type": "DefinedTerm", "identifier": t.ID,
+		"name": t.Name, "description": t.Description, "inDefinedTermSet": "themes",
+		"pw:traditions": refs("philosophy", t.PhilosophyIDs),
+	})
+}
+
+func (s *jsonldSink) evidence(e models.Evidence) error {
+	return s.node(map[string]any{
+		"@id": iri("evidence", e.ID), "@type": "ScholarlyArticle", "identifier": e.ID,
+		"name": e.Title, "abstract": e.Finding, "genre": e.Field, "citation": e.Source,
+		"about": refs("theme", e.ThemeIDs), "pw:strength": e.Strength,
+	})
+}
+
+func (s *jsonldSink) quote(q models.Quote) error {
+	var work any
+	if q.Source != "" {
+		work = map[string]string{"@type": "CreativeWork", "name": q.Source, "position": q.SourceLocation}
+	}
+	return s.node(map[string]any{
+		"@id": iri("quote", q.ID), "@type": "Quotation", "identifier": q.ID,
+		"name": q.Title, "text": q.Text, "description": q.ExpositionBrief,
+		"creator": ref("philosopher", q.PhilosopherID), "isPartOf": work,
+		"about":         append(refs("theme", q.ThemeIDs), refs("philosophy", []string{q.PhilosophyID})...),
+		"citation":      refs("evidence", q.EvidenceIDs),
+		"inLanguage":    "en",
+		"pw:philosophy": ref("philosophy", q.PhilosophyID),
+	})
+}
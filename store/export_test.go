@@ -0,0 +1,139 @@
+package store_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"perennial-wisdom/models"
+	"perennial-wisdom/store"
+)
+
+func TestExportNDJSON(t *testing.T) {
+	s := store.New()
+	draft := models.Quote{ID: "exp-draft", Text: "Not yet.", PhilosopherID: "epictetus", PhilosophyID: "stoic"}
+	if err := s.CreateQuote(draft, store.Change{Author: "ana"}); err != nil {
+		t.Fatalf("CreateQuote: %v", err)
+	}
+	// Enough published quotes to span several export pages.
+	for i := range 150 {
+		q := models.Quote{ID: fmt.Sprintf("exp-%03d", i), Text: fmt.Sprintf("Exported %d.", i), PhilosopherID: "epictetus",
+			PhilosophyID: "stoic", ThemeIDs: []string{"control"}, Status: store.StatusPublished}
+		if err := s.CreateQuote(q, store.Change{Author: "ana"}); err != nil {
+			t.Fatalf("CreateQuote: %v", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := store.Export(s, &buf, store.FormatNDJSON, ""); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+
+	counts := map[string]int{}
+	ids := map[string]bool{}
+	relations := map[string]bool{}
+	sc := bufio.NewScanner(&buf)
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		var line struct{ Type, ID, From, Rel, To string }
+		if err := json.Unmarshal(sc.Bytes(), &line); err != nil {
+			t.Fatalf("line %q: %v", sc.Text(), err)
+		}
+		if line.Type == "relation" {
+			if !ids[line.From] {
+				t.Errorf("relation %s before its entity", line.From)
+			}
+			relations[line.From+" "+line.Rel+" "+line.To] = true
+			continue
+		}
+		counts[line.Type]++
+		ids[line.Type+":"+line.ID] = true
+	}
+
+	quotes, _, _ := s.ListQuotes(nil, store.Page{})
+	themes, _, _ := s.ListThemes(store.Page{})
+	if counts["quote"] != len(quotes) || counts["theme"] != len(themes) || counts["philosopher"] == 0 || counts["evidence"] == 0 {
+		t.Errorf("entity counts %v; want %d quotes, %d themes", counts, len(quotes), len(themes))
+	}
+	if ids["quote:exp-draft"] {
+		t.Error("drafts must not be exported")
+	}
+	for _, want := range []string{
+		"quote:exp-149 philosopher philosopher:epictetus",
+		"quote:exp-149 theme theme:control",
+		"philosopher:epictetus philosophy philosophy:stoic",
+	} {
+		if !relations[want] {
+			t.Errorf("missing relation %q", want)
+		}
+	}
+}
+
+func TestExportJSONLD(t *testing.T) {
+	var buf bytes.Buffer
+	if err := store.Export(store.New(), &buf, store.FormatJSONLD, "https://example.org/"); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	var doc struct {
+		Context map[string]any   `json:"@context"`
+		Graph   []map[string]any `json:"@graph"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("not a JSON document: %v", err)
+	}
+	if doc.Context["@vocab"] != "https://schema.org/" || doc.Context["@base"] != "https://example.org/" ||
+		doc.Context["pw"] != "https://example.org/vocab#" {
+		t.Errorf("context: %v", doc.Context)
+	}
+
+	types := map[string]int{}
+	var quote map[string]any
+	for _, n := range doc.Graph {
+		types[n["@type"].(string)]++
+		if n["@id"] == "api/quotes/e1" {
+			quote = n
+		}
+	}
+	for _, typ := range []string{"Quotation", "Person", "DefinedTerm", "ScholarlyArticle"} {
+		if types[typ] == 0 {
+			t.Errorf("no %s nodes in %v", typ, types)
+		}
+	}
+	if quote == nil {
+		t.Fatal("quote e1 missing from the graph")
+	}
+	creator, _ := quote["creator"].(map[string]any)
+	if !strings.HasPrefix(fmt.Sprint(creator["@id"]), "api/philosophers/") || quote["text"] == "" {
+		t.Errorf("quotation node: %v", quote)
+	}
+	if work, _ := quote["isPartOf"].(map[string]any); work["@type"] != "CreativeWork" {
+		t.Errorf("quotation source: %v", quote["isPartOf"])
+	}
+}
+
+func TestExportJSONLDWithoutBase(t *testing.T) {
+	for _, base := range []string{"", "/mirror"} {
+		var buf bytes.Buffer
+		if err := store.Export(store.New(), &buf, store.FormatJSONLD, base); err != nil {
+			t.Fatalf("Export: %v", err)
+		}
+		var doc struct {
+			Context map[string]any `json:"@context"`
+		}
+		if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+			t.Fatalf("not a JSON document: %v", err)
+		}
+		if doc.Context["pw"] != "urn:perennial-wisdom:vocab#" {
+			t.Errorf("base %q: vocabulary %v is not absolute", base, doc.Context["pw"])
+		}
+	}
+}
+
+func TestExportRejectsUnknownFormat(t *testing.T) {
+	if err := store.Export(store.New(), &bytes.Buffer{}, "xml", ""); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
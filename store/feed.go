@@ -0,0 +1,394 @@
+package store
+
+import (
+	"cmp"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"perennial-wisdom/models"
+)
+
+// Feed formats.
+const (
+	FormatRSS  = "rss"
+	FormatAtom = "atom"
+)
+
+// FeedLimit is how many entries a feed carries, newest first.
+const FeedLimit = 20
+
+// Feed is a syndication feed, written as RSS 2.0 or Atom by WriteFeed.
+type Feed struct {
+	Title   string
+	About   string // what the feed follows, in a sentence
+	Link    string // the page of the site the feed follows
+	Self    string // the feed's own URL, which Atom also takes as its ID
+	Entries []FeedEntry
+}
+
+// FeedEntry is one quote in a feed.
+type FeedEntry struct {
+	// ID is the entry's permanent, unique ID: its page's URL when
+	// Permalink is set, else a tag: URI.
+	ID         string
+	Permalink  bool
+	Title      string
+	Link       string
+	Author     string
+	Published  time.Time
+	Updated    time.Time
+	Categories []string // the quote's tradition and themes
+	Summary    string   // plain text
+	Content    string   // HTML: the quote, its source and its exposition
+}
+
+// Updated is when the feed last changed: its newest entry's update, or
+// the Unix epoch for an empty feed.
+func (f Feed) Updated() time.Time {
+	updated := time.Unix(0, 0).UTC()
+	for _, e := range f.Entries {
+		if e.Updated.After(updated) {
+			updated = e.Updated
+		}
+	}
+	return updated
+}
+
+// QuoteFeed is the FeedLimit live quotes matching f that went public
+// most recently, newest first. Entry IDs and links are pages under
+// base, the site's URL.
+func QuoteFeed(repo Repository, f Filter, base string) ([]FeedEntry, error) {
+	quotes, _, err := repo.ListQuotes(f, Page{})
+	if err != nil {
+		return nil, err
+	}
+	stamps, err := repo.QuoteStamps()
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(quotes, func(a, b models.Quote) int {
+		return cmp.Or(stamps[b.ID].Published.Compare(stamps[a.ID].Published), strings.Compare(a.ID, b.ID))
+	})
+	quotes = quotes[:min(len(quotes), FeedLimit)]
+
+	names, err := feedNames(repo)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]FeedEntry, len(quotes))
+	for i, q := range quotes {
+		entries[i] = names.entry(q, stamps[q.ID], base)
+	}
+	return entries, nil
+}
+
+// DailyFeed is the quote of the day for the FeedLimit days up to today,
+// by the calendar in UTC, newest first. Each day is its own entry,
+// published at the day's start, so a quote that comes round again is
+// new again; its ID is a tag: URI naming the day.
+func DailyFeed(repo Repository, d Daily, today time.Time, base string) ([]FeedEntry, error) {
+	dk, err := d.load(repo)
+	if err != nil {
+		return nil, err
+	}
+	stamps, err := repo.QuoteStamps()
+	if err != nil {
+		return nil, err
+	}
+	names, err := feedNames(repo)
+	if err != nil {
+		return nil, err
+	}
+	y, m, dd := today.UTC().Date()
+	var entries []FeedEntry
+	for i := range FeedLimit {
+		day := time.Date(y, m, dd-i, 0, 0, 0, 0, time.UTC)
+		pick, err := dk.pick(day)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		e := names.entry(pick.Quote, stamps[pick.Quote.ID], base)
+		e.ID, e.Permalink = "tag:"+tagAuthority(base)+","+pick.Date+":daily", false
+		e.Title = day.Format("2 January 2006") + ": " + e.Title
+		e.Published = day
+		if e.Updated.Before(day) {
+			e.Updated = day
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// tagAuthority is the host of base, without a port, as a tag: URI's
+// authority (RFC 4151).
+func tagAuthority(base string) string {
+	u, err := url.Parse(base)
+	if err != nil || u.Hostname() == "" {
+		return "perennial-wisdom"
+	}
+	return u.Hostname()
+}
+
+// feedLabels are the display names a feed entry needs.
+type feedLabels struct {
+	philosophers, philosophies, themes map[string]string
+}
+
+func feedNames(repo Repository) (feedLabels, error) {
+	l := feedLabels{map[string]string{}, map[string]string{}, map[string]string{}}
+	philosophers, _, err := repo.ListPhilosophers(nil, Page{})
+	if err != nil {
+		return l, err
+	}
+	for _, p := range philosophers {
+		l.philosophers[p.ID] = p.Name
+	}
+	philosophies, _, err := repo.ListPhilosophies(Page{})
+	if err != nil {
+		return l, err
+	}
+	for _, p := range philosophies {
+		l.philosophies[p.ID] = p.Name
+	}
+	themes, _, err := repo.ListThemes(Page{})
+	if err != nil {
+		return l, err
+	}
+	for _, t := range themes {
+		l.themes[t.ID] = t.Name
+	}
+	return l, nil
+}
+
+// entry is q as a feed entry, its page under base.
+func (l feedLabels) entry(q models.Quote, st Stamp, base string) FeedEntry {
+	page := strings.TrimSuffix(base, "/") + "/pages/quotes/" + q.ID
+	author := l.philosophers[q.PhilosopherID]
+	title := quoteLabel(q)
+	if author != "" && q.Title == "" {
+		title = author + ": " + title
+	}
+	var categories []string
+	if name := l.philosophies[q.PhilosophyID]; name != "" {
+		categories = append(categories, name)
+	}
+	for _, t := range q.ThemeIDs {
+		if name := l.themes[t]; name != "" {
+			categories = append(categories, name)
+		}
+	}
+
+	var content strings.Builder
+	fmt.Fprintf(&content, "<blockquote><p>%s</p></blockquote>\n", html.EscapeString(q.Text))
+	if author != "" || q.Source != "" {
+		content.WriteString("<p>—")
+		if author != "" {
+			fmt.Fprintf(&content, " %s", html.EscapeString(author))
+		}
+		if q.Source != "" {
+			fmt.Fprintf(&content, ", <cite>%s</cite>", html.EscapeString(q.Source))
+			if q.SourceLocation != "" {
+				fmt.Fprintf(&content, " %s", html.EscapeString(q.SourceLocation))
+			}
+		}
+		content.WriteString("</p>\n")
+	}
+	for _, exposition := range []string{q.ExpositionBrief, q.ExpositionStandard, q.ModernReinterpretation} {
+		for _, para := range strings.Split(exposition, "\n\n") {
+			if para = strings.TrimSpace(para); para != "" {
+				fmt.Fprintf(&content, "<p>%s</p>\n", html.EscapeString(para))
+			}
+		}
+	}
+	if q.ReflectionPrompt != "" {
+		fmt.Fprintf(&content, "<p><em>%s</em></p>\n", html.EscapeString(q.ReflectionPrompt))
+	}
+
+	updated := st.Updated
+	if updated.Before(st.Published) {
+		updated = st.Published
+	}
+	return FeedEntry{
+		ID:         page,
+		Permalink:  true,
+		Title:      title,
+		Link:       page,
+		Author:     author,
+		Published:  st.Published,
+		Updated:    updated,
+		Categories: categories,
+		Summary:    cmp.Or(q.ExpositionBrief, q.Text),
+		Content:    content.String(),
+	}
+}
+
+// WriteFeed writes f to w as RSS 2.0 or Atom.
+func WriteFeed(w io.Writer, f Feed, format string) error {
+	var doc any
+	switch format {
+	case FormatRSS:
+		doc = rssDocument(f)
+	case FormatAtom:
+		doc = atomDocument(f)
+	default:
+		return fmt.Errorf("unknown feed format %q (use rss or atom)", format)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// --- RSS 2.0 ---
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	DCNS    string     `xml:"xmlns:dc,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Self          atomLink  `xml:"atom:link"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Creator     string   `xml:"dc:creator,omitempty"`
+	Categories  []string `xml:"category"`
+	Description string   `xml:"description"`
+}
+
+type rssGUID struct {
+	Permalink bool   `xml:"isPermaLink,attr"`
+	ID        string `xml:",chardata"`
+}
+
+func rssDocument(f Feed) rssFeed {
+	doc := rssFeed{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		DCNS:    "http://purl.org/dc/elements/1.1/",
+		Channel: rssChannel{
+			Title:         f.Title,
+			Link:          f.Link,
+			Description:   f.About,
+			Self:          atomLink{Href: f.Self, Rel: "self", Type: "application/rss+xml"},
+			LastBuildDate: f.Updated().Format(time.RFC1123Z),
+		},
+	}
+	for _, e := range f.Entries {
+		doc.Channel.Items = append(doc.Channel.Items, rssItem{
+			Title:       e.Title,
+			Link:        e.Link,
+			GUID:        rssGUID{Permalink: e.Permalink, ID: e.ID},
+			PubDate:     e.Published.Format(time.RFC1123Z),
+			Creator:     e.Author,
+			Categories:  e.Categories,
+			Description: e.Content,
+		})
+	}
+	return doc
+}
+
+// --- Atom ---
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Updated  string      `xml:"updated"`
+	Author   atomPerson  `xml:"author"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Link       atomLink       `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Author     *atomPerson    `xml:"author,omitempty"`
+	Categories []atomCategory `xml:"category"`
+	Summary    atomText       `xml:"summary"`
+	Content    atomText       `xml:"content"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+func atomDocument(f Feed) atomFeed {
+	doc := atomFeed{
+		ID:       f.Self,
+		Title:    f.Title,
+		Subtitle: f.About,
+		Updated:  f.Updated().Format(time.RFC3339),
+		Author:   atomPerson{Name: "Perennial Wisdom"},
+		Links: []atomLink{
+			{Href: f.Self, Rel: "self", Type: "application/atom+xml"},
+			{Href: f.Link, Rel: "alternate", Type: "text/html"},
+		},
+	}
+	for _, e := range f.Entries {
+		entry := atomEntry{
+			ID:        e.ID,
+			Title:     e.Title,
+			Link:      atomLink{Href: e.Link, Rel: "alternate", Type: "text/html"},
+			Published: e.Published.Format(time.RFC3339),
+			Updated:   e.Updated.Format(time.RFC3339),
+			Summary:   atomText{Type: "text", Body: e.Summary},
+			Content:   atomText{Type: "html", Body: e.Content},
+		}
+		if e.Author != "" {
+			entry.Author = &atomPerson{Name: e.Author}
+		}
+		for _, c := range e.Categories {
+			entry.Categories = append(entry.Categories, atomCategory{Term: c})
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+	return doc
+}
@@ -0,0 +1,129 @@
+package store_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"perennial-wisdom/models"
+	"perennial-wisdom/store"
+)
+
+func TestQuoteFeedIsNewestFirst(t *testing.T) {
+	s := store.New()
+	ch := store.Change{Author: "ana"}
+	if err := s.CreateQuote(models.Quote{ID: "f1", Text: "Fresh <and> new.", PhilosopherID: "seneca", PhilosophyID: "stoic", ThemeIDs: []string{"death"}, Source: "Letters", ExpositionBrief: "A brief.", ExpositionStandard: "One.\n\nTwo."}, ch); err != nil {
+		t.Fatalf("CreateQuote: %v", err)
+	}
+	if err := (store.Workflow{}).Transition(s, "f1", store.StatusPublished, nil, ch); err != nil {
+		t.Fatalf("Transition: %v", err)
+	}
+
+	entries, err := store.QuoteFeed(s, nil, "https://example.org")
+	if err != nil {
+		t.Fatalf("QuoteFeed: %v", err)
+	}
+	if len(entries) != store.FeedLimit {
+		t.Fatalf("%d entries, want %d", len(entries), store.FeedLimit)
+	}
+	e := entries[0]
+	if e.ID != "https://example.org/pages/quotes/f1" || !e.Permalink || e.Link != e.ID {
+		t.Errorf("newest entry: %+v", e)
+	}
+	if e.Author != "Seneca" || !strings.HasPrefix(e.Title, "Seneca: ") || e.Summary != "A brief." {
+		t.Errorf("entry names: %+v", e)
+	}
+	for _, want := range []string{"Fresh &lt;and&gt; new.", "<cite>Letters</cite>", "<p>One.</p>", "<p>Two.</p>"} {
+		if !strings.Contains(e.Content, want) {
+			t.Errorf("content lacks %q:\n%s", want, e.Content)
+		}
+	}
+	for _, e := range entries[1:] {
+		if e.Published.After(entries[0].Published) {
+			t.Errorf("%s is newer than the first entry", e.ID)
+		}
+	}
+
+	stoic, _ := store.QuoteFeed(s, store.Where("philosophy", "stoic"), "https://example.org")
+	for _, e := range stoic {
+		if e.Categories[0] != "Stoicism" {
+			t.Errorf("%s in the stoic feed: %v", e.ID, e.Categories)
+		}
+	}
+}
+
+func TestDailyFeedHasAnEntryADay(t *testing.T) {
+	s := store.New()
+	entries, err := store.DailyFeed(s, store.Daily{}, time.Date(2026, 3, 1, 18, 0, 0, 0, time.UTC), "https://example.org:8080")
+	if err != nil {
+		t.Fatalf("DailyFeed: %v", err)
+	}
+	if len(entries) != store.FeedLimit {
+		t.Fatalf("%d entries, want %d", len(entries), store.FeedLimit)
+	}
+	if e := entries[0]; e.ID != "tag:example.org,2026-03-01:daily" || e.Permalink || !e.Published.Equal(day("2026-03-01")) {
+		t.Errorf("today's entry: %+v", e)
+	}
+	ids := map[string]bool{}
+	for i, e := range entries {
+		pick, _ := store.Daily{}.Pick(s, day("2026-03-01").AddDate(0, 0, -i))
+		if e.Link != "https://example.org:8080/pages/quotes/"+pick.Quote.ID {
+			t.Errorf("%s links %s, the day's quote is %s", e.ID, e.Link, pick.Quote.ID)
+		}
+		if ids[e.ID] {
+			t.Errorf("%s twice", e.ID)
+		}
+		ids[e.ID] = true
+	}
+}
+
+func TestWriteFeed(t *testing.T) {
+	s := store.New()
+	entries, _ := store.QuoteFeed(s, store.Where("theme", "death"), "https://example.org")
+	f := store.Feed{Title: "Death", Link: "https://example.org/pages/themes/death", Self: "https://example.org/feeds/themes/death.xml", Entries: entries}
+
+	var rss struct {
+		Channel struct {
+			Title string `xml:"title"`
+			Items []struct {
+				GUID        string `xml:"guid"`
+				Description string `xml:"description"`
+			} `xml:"item"`
+		} `xml:"channel"`
+	}
+	var buf bytes.Buffer
+	if err := store.WriteFeed(&buf, f, store.FormatRSS); err != nil {
+		t.Fatalf("WriteFeed: %v", err)
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &rss); err != nil {
+		t.Fatalf("RSS does not parse: %v\n%s", err, buf.String())
+	}
+	if rss.Channel.Title != "Death" || len(rss.Channel.Items) != len(entries) || rss.Channel.Items[0].GUID != entries[0].ID || !strings.Contains(rss.Channel.Items[0].Description, "<blockquote>") {
+		t.Errorf("RSS: %+v", rss.Channel)
+	}
+
+	var atom struct {
+		XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+		ID      string   `xml:"id"`
+		Updated string   `xml:"updated"`
+		Entries []struct {
+			ID string `xml:"id"`
+		} `xml:"entry"`
+	}
+	buf.Reset()
+	if err := store.WriteFeed(&buf, f, store.FormatAtom); err != nil {
+		t.Fatalf("WriteFeed: %v", err)
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &atom); err != nil {
+		t.Fatalf("Atom does not parse: %v\n%s", err, buf.String())
+	}
+	if atom.ID != f.Self || atom.Updated != f.Updated().Format(time.RFC3339) || len(atom.Entries) != len(entries) {
+		t.Errorf("Atom: %+v", atom)
+	}
+
+	if err := store.WriteFeed(&buf, f, "json"); err == nil {
+		t.Error("expected an unknown format to be refused")
+	}
+}
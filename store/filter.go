@@ -0,0 +1,116 @@
+package store
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Filter narrows a list query, one Condition per dimension
+// ("philosopher", "philosophy", "theme", "field", ...).
+// Conditions on different dimensions are ANDed. A nil Filter matches
+// everything. The in-memory store evaluates it in Go; db translates it
+// to SQL with bound parameters.
+type Filter map[string]Condition
+
+// Condition constrains one dimension.
+type Condition struct {
+	Values  []string // match any of these (or all of them, with All)
+	All     bool     // require every value — for multi-valued dimensions like theme
+	Exclude []string // reject entities carrying any of these
+}
+
+// Where builds a single-dimension filter matching any of values.
+// Empty values are dropped, so Where("theme", "") matches everything.
+func Where(dim string, values ...string) Filter {
+	f := Filter{}
+	var kept []string
+	for _, v := range values {
+		if v != "" {
+			kept = append(kept, v)
+		}
+	}
+	if len(kept) > 0 {
+		f[dim] = Condition{Values: kept}
+	}
+	return f
+}
+
+// ParseFilter reads the given dimensions from URL query parameters:
+//
+//	?theme=control&theme=death   any of control, death
+//	?theme=control,death         same, comma-separated
+//	?theme_match=all             require every listed theme
+//	?-theme=death                exclude death (also ?theme=-death)
+//
+// Parameters for other dimensions are ignored.
+func ParseFilter(q url.Values, dims ...string) Filter {
+	f := Filter{}
+	for _, dim := range dims {
+		var c Condition
+		for _, raw := range q[dim] {
+			for _, v := range splitValues(raw) {
+				if strings.HasPrefix(v, "-") {
+					c.Exclude = append(c.Exclude, v[1:])
+				} else {
+					c.Values = append(c.Values, v)
+				}
+			}
+		}
+		for _, raw := range q["-"+dim] {
+			c.Exclude = append(c.Exclude, splitValues(raw)...)
+		}
+		c.All = strings.EqualFold(q.Get(dim+"_match"), "all")
+
+		if len(c.Values) > 0 || len(c.Exclude) > 0 {
+			f[dim] = c
+		}
+	}
+	return f
+}
+
+// splitValues splits a comma-separated parameter, dropping blanks.
+func splitValues(raw string) []string {
+	var out []string
+	for _, v := range strings.Split(raw, ",") {
+		if v = strings.TrimSpace(v); v != "" && v != "-" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// Match reports whether an entity passes the filter. values returns the
+// entity's values for a dimension (nil for dimensions it doesn't have).
+func (f Filter) Match(values func(dim string) []string) bool {
+	for dim, c := range f {
+		if !c.match(values(dim)) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c Condition) match(have []string) bool {
+	for _, x := range c.Exclude {
+		if contains(have, x) {
+			return false
+		}
+	}
+	if len(c.Values) == 0 {
+		return true
+	}
+	if c.All {
+		for _, v := range c.Values {
+			if !contains(have, v) {
+				return false
+			}
+		}
+		return true
+	}
+	for _, v := range c.Values {
+		if contains(have, v) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package store_test
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+
+	"perennial-wisdom/store"
+)
+
+func TestParseFilter(t *testing.T) {
+	q, _ := url.ParseQuery("theme=control,death&theme=-virtue&-theme=ego&theme_match=all&philosopher=&field=ignored")
+	got := store.ParseFilter(q, "theme", "philosopher")
+
+	want := store.Filter{
+		"theme": {Values: []string{"control", "death"}, All: true, Exclude: []string{"virtue", "ego"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseFilter:\ngot  %+v\nwant %+v", got, want)
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	values := func(dim string) []string {
+		switch dim {
+		case "theme":
+			return []string{"control", "suffering"}
+		case "philosopher":
+			return []string{"epictetus"}
+		}
+		return nil
+	}
+
+	cases := []struct {
+		f    store.Filter
+		want bool
+	}{
+		{nil, true},
+		{store.Where("theme", "death", "control"), true},
+		{store.Filter{"theme": {Values: []string{"control", "suffering"}, All: true}}, true},
+		{store.Filter{"theme": {Values: []string{"control", "death"}, All: true}}, false},
+		{store.Filter{"theme": {Exclude: []string{"suffering"}}}, false},
+		{store.Filter{"theme": {Values: []string{"control"}}, "philosopher": {Values: []string{"seneca"}}}, false},
+		{store.Where("philosopher", ""), true},
+	}
+	for _, c := range cases {
+		if got := c.f.Match(values); got != c.want {
+			t.Errorf("%+v.Match = %v, want %v", c.f, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package store
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+
+	"perennial-wisdom/models"
+)
+
+// NameMatch is a philosopher, tradition or theme whose name matches a
+// search, exactly or roughly.
+type NameMatch struct {
+	Kind  string  `json:"kind"` // "philosopher", "philosophy" or "theme"
+	ID    string  `json:"id"`
+	Name  string  `json:"name"`
+	Score float64 `json:"score"` // trigram similarity; 1 is exact
+}
+
+// Fuzzy matching thresholds, on trigram similarity (shared trigrams
+// over all trigrams, as pg_trgm computes it).
+const (
+	nameThreshold    = 0.3 // pg_trgm's default
+	suggestThreshold = 0.4
+	maxNameMatches   = 10
+)
+
+// fuzzyWeight discounts a query term matched only through a typo.
+const fuzzyWeight = 0.5
+
+// letterFolds are letters with no decomposition to strip diacritics from.
+var letterFolds = map[rune]string{
+	'ø': "o", 'Ø': "o", 'æ': "ae", 'Æ': "ae", 'œ': "oe", 'Œ': "oe", 'ß': "ss",
+	'ł': "l", 'Ł': "l", 'đ': "d", 'Đ': "d", 'ð': "d", 'Ð': "d", 'þ': "th", 'Þ': "th", 'ı': "i",
+}
+
+// Unaccent lowercases s and strips its diacritics, so "Śaṅkara" and
+// "sankara" compare equal.
+func Unaccent(s string) string {
+	ascii := true
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		return strings.ToLower(s)
+	}
+	var b strings.Builder
+	for _, r := range norm.NFD.String(s) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		if f, ok := letterFolds[r]; ok {
+			b.WriteString(f)
+			continue
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// trigrams are the three-letter sequences of text's words, each padded
+// with two spaces in front and one behind, as pg_trgm pads them.
+func trigrams(text string) map[string]bool {
+	set := map[string]bool{}
+	for _, tok := range tokenize(text) {
+		r := []rune("  " + tok.word + " ")
+		for i := 0; i+3 <= len(r); i++ {
+			set[string(r[i:i+3])] = true
+		}
+	}
+	return set
+}
+
+// similarity is the Jaccard similarity of two trigram sets.
+func similarity(a, b map[string]bool) float64 {
+	shared := 0
+	for t := range a {
+		if b[t] {
+			shared++
+		}
+	}
+	if union := len(a) + len(b) - shared; union > 0 {
+		return float64(shared) / float64(union)
+	}
+	return 0
+}
+
+// editDistance counts the insertions, deletions, substitutions and
+// swaps of adjacent letters that turn a into b.
+func editDistance(a, b string) int {
+	s, t := []rune(a), []rune(b)
+	d := make([][]int, len(s)+1)
+	for i := range d {
+		d[i] = make([]int, len(t)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(s); i++ {
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(s)][len(t)]
+}
+
+// typos is how many edits a word of n letters may be off by and still
+// match: none for short words, where one edit makes another word.
+func typos(n int) int {
+	switch {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	}
+	return 2
+}
+
+// MatchNames scores candidates against search text and returns the best
+// that match, exactly or roughly, best first. A name scores the better
+// of its similarity to the whole text and the mean, over the text's
+// words, of each word's best similarity to one of the name's words —
+// so "sankara" finds "Adi Shankara" and "Lao Tse" finds "Lao Tzu".
+func MatchNames(text string, candidates []NameMatch) []NameMatch {
+	var words []map[string]bool
+	for _, tok := range tokenize(text) {
+		if !stopwords[tok.word] {
+			words = append(words, trigrams(tok.word))
+		}
+	}
+	if len(words) == 0 {
+		return []NameMatch{}
+	}
+	whole := trigrams(text)
+
+	matches := []NameMatch{}
+	for _, c := range candidates {
+		var nameWords []map[string]bool
+		for _, tok := range tokenize(c.Name) {
+			if !stopwords[tok.word] {
+				nameWords = append(nameWords, trigrams(tok.word))
+			}
+		}
+		mean := 0.0
+		for _, w := range words {
+			best := 0.0
+			for _, nw := range nameWords {
+				best = max(best, similarity(w, nw))
+			}
+			mean += best / float64(len(words))
+		}
+		score := max(similarity(whole, trigrams(c.Name)), mean)
+		if score >= nameThreshold {
+			c.Score = float64(int(score*1000+0.5)) / 1000
+			matches = append(matches, c)
+		}
+	}
+	slices.SortFunc(matches, func(a, b NameMatch) int {
+		return cmp.Or(cmp.Compare(b.Score, a.Score), cmp.Compare(a.Name, b.Name), cmp.Compare(a.ID, b.ID))
+	})
+	return matches[:min(len(matches), maxNameMatches)]
+}
+
+// Vocabulary counts the quotes that use each searchable word, folded
+// with Unaccent. Stopwords are left out.
+func Vocabulary(quotes []models.Quote) map[string]int {
+	vocab := map[string]int{}
+	for _, q := range quotes {
+		for w := range fieldWords(quoteFields(q)) {
+			vocab[w]++
+		}
+	}
+	return vocab
+}
+
+// fieldWords are the distinct folded words of fields, stopwords aside.
+func fieldWords(fields []field) map[string]bool {
+	words := map[string]bool{}
+	for _, f := range fields {
+		for _, tok := range tokenize(f.text) {
+			if !stopwords[tok.word] {
+				words[tok.word] = true
+			}
+		}
+	}
+	return words
+}
+
+// Suggest proposes a correction of search text from the words of
+// vocabulary and of names: each word the vocabulary lacks is replaced by
+// the known word most like it. It returns "" when it has nothing better.
+func Suggest(text string, vocabulary map[string]int, names []NameMatch) string {
+	known := maps.Clone(vocabulary)
+	if known == nil {
+		known = map[string]int{}
+	}
+	for _, n := range names {
+		for _, tok := range tokenize(n.Name) {
+			known[tok.word]++
+		}
+	}
+
+	var b strings.Builder
+	at, changed := 0, false
+	for _, tok := range tokenize(text) {
+		if stopwords[tok.word] || known[tok.word] > 0 {
+			continue
+		}
+		if fix := closest(tok.word, known); fix != "" {
+			b.WriteString(text[at:tok.start])
+			b.WriteString(fix)
+			at, changed = tok.end, true
+		}
+	}
+	if !changed {
+		return ""
+	}
+	b.WriteString(text[at:])
+	return b.String()
+}
+
+// closest is the known word most similar to word, by trigrams then by
+// edit distance, then the more common; "" if none is similar enough.
+func closest(word string, known map[string]int) string {
+	tri := trigrams(word)
+	best, bestScore, bestDist := "", suggestThreshold, 0
+	for w, n := range known {
+		score := similarity(tri, trigrams(w))
+		if score < bestScore {
+			continue
+		}
+		dist := editDistance(word, w)
+		if c := cmp.Or(cmp.Compare(score, bestScore), cmp.Compare(bestDist, dist),
+			cmp.Compare(n, known[best]), cmp.Compare(best, w)); best == "" || c > 0 {
+			best, bestScore, bestDist = w, score, dist
+		}
+	}
+	return best
+}
+
+// Fuzzy completes a search with what matched only roughly: the names
+// that match its text and, when neither a quote nor a name matched, a
+// "did you mean" built by Suggest. vocabulary is called only then.
+func Fuzzy(res *SearchResults, text string, names []NameMatch, vocabulary func() (map[string]int, error)) error {
+	res.Names = MatchNames(text, names)
+	if res.Total > 0 || len(res.Names) > 0 || strings.TrimSpace(text) == "" {
+		return nil
+	}
+	vocab, err := vocabulary()
+	if err != nil {
+		return err
+	}
+	res.DidYouMean = Suggest(text, vocab, names)
+	return nil
+}
@@ -0,0 +1,75 @@
+package store_test
+
+import (
+	"testing"
+
+	"perennial-wisdom/models"
+	"perennial-wisdom/store"
+)
+
+func TestUnaccent(t *testing.T) {
+	for in, want := range map[string]string{
+		"Śaṅkara":      "sankara",
+		"Ærø Łódź":     "aero lodz",
+		"Nietzsche":    "nietzsche",
+		"Straße, Þórr": "strasse, thorr",
+	} {
+		if got := store.Unaccent(in); got != want {
+			t.Errorf("Unaccent(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSearchMatchesNamesRoughly(t *testing.T) {
+	s := store.New()
+	for query, want := range map[string]string{
+		"Epictetos": "epictetus",
+		"Lao Tse":   "laozi",
+		"Śaṅkara":   "shankara",
+		"stoik":     "stoic",
+		"deth":      "death",
+	} {
+		res, err := s.SearchQuotes(store.SearchQuery{Text: query})
+		if err != nil {
+			t.Fatalf("SearchQuotes: %v", err)
+		}
+		if len(res.Names) == 0 || res.Names[0].ID != want {
+			t.Errorf("%q: names %+v, want %s first", query, res.Names, want)
+		}
+	}
+	if res, _ := s.SearchQuotes(store.SearchQuery{Text: "xyzzy"}); len(res.Names) != 0 || res.Names == nil {
+		t.Errorf("unrelated text matched names: %+v", res.Names)
+	}
+}
+
+func TestSearchToleratesTyposAndDiacritics(t *testing.T) {
+	s := store.New()
+	q := models.Quote{ID: "fz-1", Text: "Equanimity is the stillness of a río in winter.", PhilosopherID: "seneca",
+		PhilosophyID: "stoic", Status: store.StatusPublished}
+	if err := s.CreateQuote(q, store.Change{Author: "ana"}); err != nil {
+		t.Fatalf("CreateQuote: %v", err)
+	}
+	for _, query := range []string{"equanimty", "EQUANIMITY rio", "stilness winter"} {
+		res, _ := s.SearchQuotes(store.SearchQuery{Text: query})
+		if res.Total != 1 || res.Hits[0].Quote.ID != "fz-1" {
+			t.Errorf("%q: %+v", query, res.Hits)
+		}
+	}
+	exact, _ := s.SearchQuotes(store.SearchQuery{Text: "equanimity"})
+	typo, _ := s.SearchQuotes(store.SearchQuery{Text: "equanimty"})
+	if typo.Hits[0].Rank >= exact.Hits[0].Rank {
+		t.Errorf("a typo should rank below the word itself: %v vs %v", typo.Hits[0].Rank, exact.Hits[0].Rank)
+	}
+	if got, want := typo.Hits[0].Snippet, "<mark>Equanimity</mark> is the stillness of a río in winter."; got != want {
+		t.Errorf("snippet:\n got %q\nwant %q", got, want)
+	}
+
+	// Too far off to match, but close enough to suggest.
+	res, _ := s.SearchQuotes(store.SearchQuery{Text: "equanimitynes in winter"})
+	if res.Total != 0 || res.DidYouMean != "equanimity in winter" {
+		t.Errorf("did you mean: %q (total %d)", res.DidYouMean, res.Total)
+	}
+	if res, _ := s.SearchQuotes(store.SearchQuery{Text: "equanimity"}); res.DidYouMean != "" {
+		t.Errorf("suggestion despite a match: %q", res.DidYouMean)
+	}
+}
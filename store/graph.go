@@ -0,0 +1,326 @@
+package store
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"perennial-wisdom/models"
+)
+
+// Graph export formats.
+const (
+	FormatGraphML   = "graphml"
+	FormatDOT       = "dot"
+	FormatJSONGraph = "json" // JSON Graph Format, v2
+)
+
+// GraphKinds are the kinds of node in the corpus graph, in the order a
+// bare root ID is looked up in.
+var GraphKinds = []string{"philosophy", "philosopher", "theme", "evidence", "quote"}
+
+// graphLabelChars bounds a quote's label; the full text is an attribute.
+const graphLabelChars = 60
+
+// Graph is the public corpus as a typed, directed graph: every school,
+// philosopher, theme, evidence entry and live quote, linked the way
+// Export's relations link them.
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// GraphNode is one entity. Its ID is "kind:id", unique across kinds.
+type GraphNode struct {
+	ID    string `json:"id"`
+	Kind  string `json:"kind"`
+	Label string `json:"label"`
+	Text  string `json:"text,omitempty"` // a quote's full text
+}
+
+// GraphEdge links two nodes by their IDs. Rel names the link as Export
+// does: "philosophy", "philosopher", "theme", "evidence" or "related".
+type GraphEdge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Rel    string `json:"rel"`
+}
+
+// NodeID is the graph ID of an entity.
+func NodeID(kind, id string) string { return kind + ":" + id }
+
+// BuildGraph reads the public corpus into a Graph. Links to entities
+// that aren't there (or aren't public) are left out, and schools that
+// list each other as related are linked once.
+func BuildGraph(repo Repository) (Graph, error) {
+	var g Graph
+	type link struct {
+		from string
+		rel  string
+		kind string // of the nodes linked to
+		to   []string
+	}
+	var links []link
+	node := func(kind, id, label string, rels ...link) {
+		g.Nodes = append(g.Nodes, GraphNode{ID: NodeID(kind, id), Kind: kind, Label: label})
+		for _, l := range rels {
+			l.from = NodeID(kind, id)
+			links = append(links, l)
+		}
+	}
+
+	philosophies, _, err := repo.ListPhilosophies(Page{})
+	if err != nil {
+		return g, err
+	}
+	for _, p := range philosophies {
+		node("philosophy", p.ID, p.Name, link{rel: "related", kind: "philosophy", to: p.RelatedIDs})
+	}
+	philosophers, _, err := repo.ListPhilosophers(nil, Page{})
+	if err != nil {
+		return g, err
+	}
+	for _, p := range philosophers {
+		node("philosopher", p.ID, p.Name, link{rel: "philosophy", kind: "philosophy", to: []string{p.PhilosophyID}})
+	}
+	themes, _, err := repo.ListThemes(Page{})
+	if err != nil {
+		return g, err
+	}
+	for _, t := range themes {
+		node("theme", t.ID, t.Name, link{rel: "philosophy", kind: "philosophy", to: t.PhilosophyIDs})
+	}
+	evidence, _, err := repo.ListEvidence(nil, Page{})
+	if err != nil {
+		return g, err
+	}
+	for _, e := range evidence {
+		node("evidence", e.ID, e.Title, link{rel: "theme", kind: "theme", to: e.ThemeIDs})
+	}
+	quotes, _, err := repo.ListQuotes(nil, Page{})
+	if err != nil {
+		return g, err
+	}
+	for _, q := range quotes {
+		node("quote", q.ID, quoteLabel(q),
+			link{rel: "philosopher", kind: "philosopher", to: []string{q.PhilosopherID}},
+			link{rel: "philosophy", kind: "philosophy", to: []string{q.PhilosophyID}},
+			link{rel: "theme", kind: "theme", to: q.ThemeIDs},
+			link{rel: "evidence", kind: "evidence", to: q.EvidenceIDs})
+		g.Nodes[len(g.Nodes)-1].Text = q.Text
+	}
+
+	known := map[string]bool{}
+	for _, n := range g.Nodes {
+		known[n.ID] = true
+	}
+	seen := map[GraphEdge]bool{}
+	for _, l := range links {
+		for _, id := range l.to {
+			e := GraphEdge{Source: l.from, Target: NodeID(l.kind, id), Rel: l.rel}
+			if !known[e.Target] || e.Target == e.Source || seen[e] ||
+				l.rel == "related" && seen[GraphEdge{Source: e.Target, Target: e.Source, Rel: e.Rel}] {
+				continue
+			}
+			seen[e] = true
+			g.Edges = append(g.Edges, e)
+		}
+	}
+	return g, nil
+}
+
+// quoteLabel is a quote's title, or the start of its text.
+func quoteLabel(q models.Quote) string {
+	if q.Title != "" {
+		return q.Title
+	}
+	if utf8.RuneCountInString(q.Text) <= graphLabelChars {
+		return q.Text
+	}
+	r := []rune(q.Text)[:graphLabelChars-1]
+	return strings.TrimRight(string(r), " ,;:.") + "…"
+}
+
+// Resolve finds the node root names: a node ID ("philosophy:stoic") or
+// a bare entity ID ("stoic"), looked up kind by kind in GraphKinds order.
+func (g Graph) Resolve(root string) (GraphNode, error) {
+	candidates := []string{root}
+	if !strings.Contains(root, ":") {
+		candidates = candidates[:0]
+		for _, kind := range GraphKinds {
+			candidates = append(candidates, NodeID(kind, root))
+		}
+	}
+	for _, id := range candidates {
+		if i := slices.IndexFunc(g.Nodes, func(n GraphNode) bool { return n.ID == id }); i >= 0 {
+			return g.Nodes[i], nil
+		}
+	}
+	return GraphNode{}, ErrNotFound
+}
+
+// Neighborhood is the subgraph within depth links of root, whichever
+// way the links point, with every edge between the nodes it keeps.
+// root is as Resolve takes it; depth 0 is root alone.
+func (g Graph) Neighborhood(root string, depth int) (Graph, error) {
+	if depth < 0 {
+		return Graph{}, errors.New("depth must be a non-negative integer")
+	}
+	start, err := g.Resolve(root)
+	if err != nil {
+		return Graph{}, err
+	}
+	adjacent := map[string][]string{}
+	for _, e := range g.Edges {
+		adjacent[e.Source] = append(adjacent[e.Source], e.Target)
+		adjacent[e.Target] = append(adjacent[e.Target], e.Source)
+	}
+	keep := map[string]bool{start.ID: true}
+	frontier := []string{start.ID}
+	for range depth {
+		var next []string
+		for _, id := range frontier {
+			for _, n := range adjacent[id] {
+				if !keep[n] {
+					keep[n] = true
+					next = append(next, n)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	var sub Graph
+	for _, n := range g.Nodes {
+		if keep[n.ID] {
+			sub.Nodes = append(sub.Nodes, n)
+		}
+	}
+	for _, e := range g.Edges {
+		if keep[e.Source] && keep[e.Target] {
+			sub.Edges = append(sub.Edges, e)
+		}
+	}
+	return sub, nil
+}
+
+// WriteGraph writes g to w in one of the graph formats: GraphML for
+// Gephi and yEd, DOT for Graphviz, or the JSON Graph Format. Nodes and
+// edges keep their order; every format carries each node's kind and
+// label and each edge's relation.
+func WriteGraph(w io.Writer, g Graph, format string) error {
+	bw := bufio.NewWriter(w)
+	var err error
+	switch format {
+	case FormatGraphML:
+		err = writeGraphML(bw, g)
+	case FormatDOT:
+		err = writeDOT(bw, g)
+	case FormatJSONGraph:
+		err = writeJSONGraph(bw, g)
+	default:
+		return fmt.Errorf("unknown graph format %q (use graphml, dot or json)", format)
+	}
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+func writeGraphML(w *bufio.Writer, g Graph) error {
+	esc := func(s string) string {
+		var b strings.Builder
+		xml.EscapeText(&b, []byte(s))
+		return b.String()
+	}
+	w.WriteString(xml.Header)
+	w.WriteString(`<graphml xmlns="http://graphml.graphdrawing.org/xmlns">` + "\n")
+	w.WriteString(`  <key id="kind" for="node" attr.name="kind" attr.type="string"/>` + "\n")
+	w.WriteString(`  <key id="label" for="node" attr.name="label" attr.type="string"/>` + "\n")
+	w.WriteString(`  <key id="text" for="node" attr.name="text" attr.type="string"/>` + "\n")
+	w.WriteString(`  <key id="rel" for="edge" attr.name="rel" attr.type="string"/>` + "\n")
+	w.WriteString(`  <graph id="perennial-wisdom" edgedefault="directed">` + "\n")
+	for _, n := range g.Nodes {
+		fmt.Fprintf(w, `    <node id="%s"><data key="kind">%s</data><data key="label">%s</data>`, esc(n.ID), n.Kind, esc(n.Label))
+		if n.Text != "" {
+			fmt.Fprintf(w, `<data key="text">%s</data>`, esc(n.Text))
+		}
+		w.WriteString("</node>\n")
+	}
+	for i, e := range g.Edges {
+		fmt.Fprintf(w, `    <edge id="e%d" source="%s" target="%s"><data key="rel">%s</data></edge>`+"\n",
+			i, esc(e.Source), esc(e.Target), e.Rel)
+	}
+	_, err := w.WriteString("  </graph>\n</graphml>\n")
+	return err
+}
+
+// dotShapes tell the kinds of node apart in Graphviz.
+var dotShapes = map[string]string{
+	"philosophy":  "doubleoctagon",
+	"philosopher": "ellipse",
+	"theme":       "box",
+	"evidence":    "note",
+	"quote":       "plaintext",
+}
+
+func writeDOT(w *bufio.Writer, g Graph) error {
+	quote := func(s string) string {
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+	}
+	w.WriteString("digraph \"perennial-wisdom\" {\n")
+	for _, n := range g.Nodes {
+		fmt.Fprintf(w, "  %s [label=%s, kind=%s, shape=%s];\n", quote(n.ID), quote(n.Label), n.Kind, dotShapes[n.Kind])
+	}
+	for _, e := range g.Edges {
+		attrs := "label=" + e.Rel
+		if e.Rel == "related" {
+			attrs += ", dir=both"
+		}
+		fmt.Fprintf(w, "  %s -> %s [%s];\n", quote(e.Source), quote(e.Target), attrs)
+	}
+	_, err := w.WriteString("}\n")
+	return err
+}
+
+// jgfNode is a node of the JSON Graph Format.
+type jgfNode struct {
+	Label    string         `json:"label"`
+	Metadata map[string]any `json:"metadata"`
+}
+
+// jgfEdge is an edge of the JSON Graph Format.
+type jgfEdge struct {
+	Source   string `json:"source"`
+	Target   string `json:"target"`
+	Relation string `json:"relation"`
+}
+
+func writeJSONGraph(w *bufio.Writer, g Graph) error {
+	nodes := make(map[string]jgfNode, len(g.Nodes))
+	for _, n := range g.Nodes {
+		meta := map[string]any{"kind": n.Kind}
+		if n.Text != "" {
+			meta["text"] = n.Text
+		}
+		nodes[n.ID] = jgfNode{Label: n.Label, Metadata: meta}
+	}
+	edges := make([]jgfEdge, len(g.Edges))
+	for i, e := range g.Edges {
+		edges[i] = jgfEdge{e.Source, e.Target, e.Rel}
+	}
+	return json.NewEncoder(w).Encode(map[string]any{
+		"graph": map[string]any{
+			"id":       "perennial-wisdom",
+			"directed": true,
+			"nodes":    nodes,
+			"edges":    edges,
+		},
+	})
+}
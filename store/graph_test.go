@@ -0,0 +1,168 @@
+package store_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+
+	"perennial-wisdom/models"
+	"perennial-wisdom/store"
+)
+
+func TestBuildGraphLinksTheCorpus(t *testing.T) {
+	s := store.New()
+	if err := s.CreateQuote(models.Quote{ID: "g-draft", Text: "Unpublished.", PhilosopherID: "seneca",
+		PhilosophyID: "stoic", ThemeIDs: []string{"death"}, Status: store.StatusDraft}, store.Change{Author: "ana"}); err != nil {
+		t.Fatalf("CreateQuote: %v", err)
+	}
+	g, err := store.BuildGraph(s)
+	if err != nil {
+		t.Fatalf("BuildGraph: %v", err)
+	}
+
+	nodes := map[string]store.GraphNode{}
+	for _, n := range g.Nodes {
+		nodes[n.ID] = n
+	}
+	if _, ok := nodes["quote:g-draft"]; ok {
+		t.Error("drafts must not be in the graph")
+	}
+	if n := nodes["philosophy:stoic"]; n.Kind != "philosophy" || n.Label != "Stoicism" {
+		t.Errorf("stoic node: %+v", n)
+	}
+	edges := map[store.GraphEdge]bool{}
+	related := map[[2]string]int{}
+	for _, e := range g.Edges {
+		if nodes[e.Source].ID == "" || nodes[e.Target].ID == "" {
+			t.Errorf("dangling edge %+v", e)
+		}
+		edges[e] = true
+		if e.Rel == "related" {
+			pair := [2]string{min(e.Source, e.Target), max(e.Source, e.Target)}
+			related[pair]++
+		}
+	}
+	for _, want := range []store.GraphEdge{
+		{Source: "quote:e1", Target: "philosopher:epictetus", Rel: "philosopher"},
+		{Source: "quote:e1", Target: "philosophy:stoic", Rel: "philosophy"},
+		{Source: "quote:e1", Target: "theme:control", Rel: "theme"},
+		{Source: "philosopher:epictetus", Target: "philosophy:stoic", Rel: "philosophy"},
+		{Source: "theme:control", Target: "philosophy:stoic", Rel: "philosophy"},
+	} {
+		if !edges[want] {
+			t.Errorf("missing edge %+v", want)
+		}
+	}
+	if len(related) == 0 {
+		t.Error("no related schools")
+	}
+	for pair, n := range related {
+		if n != 1 {
+			t.Errorf("%v linked %d times", pair, n)
+		}
+	}
+}
+
+func TestGraphNeighborhood(t *testing.T) {
+	g, err := store.BuildGraph(store.New())
+	if err != nil {
+		t.Fatalf("BuildGraph: %v", err)
+	}
+	one, err := g.Neighborhood("epictetus", 1)
+	if err != nil {
+		t.Fatalf("Neighborhood: %v", err)
+	}
+	kinds := map[string]int{}
+	for _, n := range one.Nodes {
+		kinds[n.Kind]++
+	}
+	// Epictetus, his school, and his quotes.
+	if kinds["philosopher"] != 1 || kinds["philosophy"] != 1 || kinds["quote"] == 0 || kinds["theme"] != 0 {
+		t.Errorf("depth 1 around epictetus: %v", kinds)
+	}
+	two, _ := g.Neighborhood("philosopher:epictetus", 2)
+	if len(two.Nodes) <= len(one.Nodes) || len(two.Edges) <= len(one.Edges) {
+		t.Errorf("depth 2 should reach further: %d nodes vs %d", len(two.Nodes), len(one.Nodes))
+	}
+	if alone, _ := g.Neighborhood("stoic", 0); len(alone.Nodes) != 1 || alone.Nodes[0].ID != "philosophy:stoic" || len(alone.Edges) != 0 {
+		t.Errorf("depth 0: %+v", alone)
+	}
+	if _, err := g.Neighborhood("nope", 1); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("unknown root: %v", err)
+	}
+}
+
+func TestWriteGraphFormats(t *testing.T) {
+	g := store.Graph{
+		Nodes: []store.GraphNode{
+			{ID: "philosophy:stoic", Kind: "philosophy", Label: "Stoicism"},
+			{ID: "quote:q1", Kind: "quote", Label: `Say "no" & <mean> it`, Text: `Say "no" & <mean> it`},
+		},
+		Edges: []store.GraphEdge{{Source: "quote:q1", Target: "philosophy:stoic", Rel: "philosophy"}},
+	}
+
+	var buf bytes.Buffer
+	if err := store.WriteGraph(&buf, g, store.FormatGraphML); err != nil {
+		t.Fatalf("graphml: %v", err)
+	}
+	var doc struct {
+		Graph struct {
+			Nodes []struct {
+				ID   string `xml:"id,attr"`
+				Data []struct {
+					Key   string `xml:"key,attr"`
+					Value string `xml:",chardata"`
+				} `xml:"data"`
+			} `xml:"node"`
+			Edges []struct {
+				Source string `xml:"source,attr"`
+				Target string `xml:"target,attr"`
+			} `xml:"edge"`
+		} `xml:"graph"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("graphml doesn't parse: %v\n%s", err, buf.String())
+	}
+	if len(doc.Graph.Nodes) != 2 || doc.Graph.Nodes[1].Data[1].Value != `Say "no" & <mean> it` || len(doc.Graph.Edges) != 1 {
+		t.Errorf("graphml: %+v", doc)
+	}
+
+	buf.Reset()
+	if err := store.WriteGraph(&buf, g, store.FormatDOT); err != nil {
+		t.Fatalf("dot: %v", err)
+	}
+	for _, want := range []string{`digraph "perennial-wisdom" {`, `"quote:q1" [label="Say \"no\" & <mean> it"`, `"quote:q1" -> "philosophy:stoic" [label=philosophy];`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("dot lacks %s:\n%s", want, buf.String())
+		}
+	}
+
+	buf.Reset()
+	if err := store.WriteGraph(&buf, g, store.FormatJSONGraph); err != nil {
+		t.Fatalf("json: %v", err)
+	}
+	var jgf struct {
+		Graph struct {
+			Directed bool
+			Nodes    map[string]struct {
+				Label    string
+				Metadata map[string]string
+			}
+			Edges []struct{ Source, Target, Relation string }
+		}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &jgf); err != nil {
+		t.Fatalf("json: %v", err)
+	}
+	if !jgf.Graph.Directed || jgf.Graph.Nodes["philosophy:stoic"].Metadata["kind"] != "philosophy" ||
+		len(jgf.Graph.Edges) != 1 || jgf.Graph.Edges[0].Relation != "philosophy" {
+		t.Errorf("json: %+v", jgf)
+	}
+
+	if err := store.WriteGraph(&buf, g, "svg"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,435 @@
+package store
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"perennial-wisdom/models"
+)
+
+// Import formats.
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+// ErrUnreadable marks input Import could not parse.
+var ErrUnreadable = errors.New("unreadable input")
+
+// FormatOf guesses an import format from a file name's extension.
+func FormatOf(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		return FormatJSON
+	case ".yaml", ".yml":
+		return FormatYAML
+	}
+	return FormatCSV
+}
+
+// ImportOptions controls an Import.
+type ImportOptions struct {
+	Format string // FormatCSV, FormatJSON or FormatYAML
+	DryRun bool   // check and report, but write nothing
+	Change Change // recorded on every imported quote's revision
+}
+
+// ImportReport says what an Import did, or would do. Problems are listed
+// by row; if there are any, nothing was written.
+type ImportReport struct {
+	DryRun   bool
+	Rows     int
+	Created  []string // quote IDs, in file order
+	Problems []ImportProblem
+}
+
+// ImportProblem is one reason a row can't be imported. Row counts records
+// from 1 — for CSV it is the spreadsheet row, so the header is row 1.
+type ImportProblem struct {
+	Row     int
+	ID      string
+	Field   string
+	Message string
+}
+
+// OK reports whether the import found nothing wrong.
+func (r *ImportReport) OK() bool { return len(r.Problems) == 0 }
+
+// importColumns maps the column names a spreadsheet may use onto quote
+// fields: the API's JSON names, the SQL column names, and the plain
+// names researchers tend to type. Philosopher, tradition, theme and
+// evidence columns take names or titles as well as IDs.
+var importColumns = map[string]string{
+	"id": "id", "slug": "slug", "title": "title", "text": "text",
+	"text_scholarly": "text_scholarly", "original_script": "original_script",
+	"philosopher": "philosopher", "philosopher_id": "philosopher",
+	"philosophy": "philosophy", "philosophy_id": "philosophy", "tradition": "philosophy", "tradition_id": "philosophy",
+	"source": "source", "source_work": "source", "source_location": "source_location",
+	"exposition_brief": "exposition_brief", "exposition_standard": "exposition_standard",
+	"exposition_scholarly": "exposition_scholarly", "reflection_prompt": "reflection_prompt",
+	"modern_reinterpretation": "modern_reinterpretation",
+	"themes":                  "themes", "theme_ids": "themes", "theme": "themes",
+	"evidence": "evidence", "evidence_ids": "evidence",
+}
+
+// Import reads quotes from r and creates them as drafts, all in one
+// Batch. Rows are checked first: missing fields, references that resolve
+// to nothing, and IDs, slugs or texts that would duplicate a stored quote
+// or an earlier row. Any problem means nothing is written. The error is
+// for input that can't be parsed at all (ErrUnreadable), or a write that
+// failed anyway.
+//
+// Columns outside importColumns are problems too, reported on the first
+// row that has them, so a typo in a header can't silently drop data —
+// except "meta.<key>" columns, which land in the quote's Meta.
+func Import(rw ReadWriter, r io.Reader, opts ImportOptions) (*ImportReport, error) {
+	records, err := readRecords(r, opts.Format)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnreadable, err)
+	}
+	report := &ImportReport{DryRun: opts.DryRun, Rows: len(records)}
+	res, err := newResolver(rw)
+	if err != nil {
+		return nil, err
+	}
+
+	var quotes []models.Quote
+	for _, rec := range records {
+		q, problems := res.quote(rec.values)
+		for _, p := range problems {
+			report.Problems = append(report.Problems, ImportProblem{Row: rec.row, ID: q.ID, Field: p.Field, Message: p.Message})
+		}
+		quotes = append(quotes, q)
+	}
+	if !report.OK() || opts.DryRun {
+		if report.OK() {
+			for _, q := range quotes {
+				report.Created = append(report.Created, q.ID)
+			}
+		}
+		return report, nil
+	}
+
+	err = rw.Batch(func(tx ReadWriter) error {
+		for i, q := range quotes {
+			if err := tx.CreateQuote(q, opts.Change); err != nil {
+				return fmt.Errorf("row %d (%s): %w", records[i].row, q.ID, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, q := range quotes {
+		report.Created = append(report.Created, q.ID)
+	}
+	return report, nil
+}
+
+// record is one row: column name to a string, or to a list for the
+// multi-valued columns of JSON and YAML input.
+type record struct {
+	row    int
+	values map[string]any
+}
+
+// readRecords parses the input into records.
+func readRecords(r io.Reader, format string) ([]record, error) {
+	switch format {
+	case FormatCSV, "":
+		return readCSV(r)
+	case FormatJSON, FormatYAML:
+		var doc any
+		var err error
+		if format == FormatJSON {
+			err = json.NewDecoder(r).Decode(&doc)
+		} else {
+			err = yaml.NewDecoder(r).Decode(&doc)
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("parse %s: %w", format, err)
+		}
+		// Either a list of quotes, or {"quotes": [...]} as the API lists them.
+		if m, ok := doc.(map[string]any); ok {
+			doc = m["quotes"]
+		}
+		items, ok := doc.([]any)
+		if !ok && doc != nil {
+			return nil, fmt.Errorf("parse %s: expected a list of quotes", format)
+		}
+		records := make([]record, len(items))
+		for i, item := range items {
+			m, ok := item.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("parse %s: quote %d is not an object", format, i+1)
+			}
+			records[i] = record{row: i + 1, values: m}
+		}
+		return records, nil
+	}
+	return nil, fmt.Errorf("unknown import format %q (use csv, json or yaml)", format)
+}
+
+// readCSV reads a header row and the rows below it, skipping blank ones.
+// Spreadsheet exports often start with a byte order mark; it is dropped.
+func readCSV(r io.Reader) ([]record, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	cr := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	cr.FieldsPerRecord = -1
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("parse csv: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	header := rows[0]
+	var records []record
+	for n, row := range rows[1:] {
+		values := map[string]any{}
+		for i, v := range row {
+			if i < len(header) && strings.TrimSpace(v) != "" {
+				values[header[i]] = v
+			}
+		}
+		if len(values) > 0 {
+			records = append(records, record{row: n + 2, values: values})
+		}
+	}
+	return records, nil
+}
+
+// resolver maps what a row says onto IDs, against the corpus as it was
+// when the import began.
+type resolver struct {
+	philosophers map[string]string // id, or folded name, to id
+	philosophies map[string]string
+	themes       map[string]string
+	evidence     map[string]string
+
+	quoteIDs map[string]bool
+	slugs    map[string]string // slug to the quote that has it
+	texts    map[string]string // folded text to the quote that has it
+
+	unknown map[string]bool // columns already reported
+}
+
+func newResolver(repo Repository) (*resolver, error) {
+	res := &resolver{
+		philosophers: map[string]string{}, philosophies: map[string]string{},
+		themes: map[string]string{}, evidence: map[string]string{},
+		quoteIDs: map[string]bool{}, slugs: map[string]string{}, texts: map[string]string{},
+		unknown: map[string]bool{},
+	}
+	philosophers, _, err := repo.ListPhilosophers(nil, Page{})
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range philosophers {
+		index(res.philosophers, p.ID, p.Name)
+	}
+	philosophies, _, err := repo.ListPhilosophies(Page{})
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range philosophies {
+		index(res.philosophies, p.ID, p.Name)
+	}
+	themes, _, err := repo.ListThemes(Page{})
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range themes {
+		index(res.themes, t.ID, t.Name)
+	}
+	evidence, _, err := repo.ListEvidence(nil, Page{})
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range evidence {
+		index(res.evidence, e.ID, e.Title)
+	}
+	quotes, _, err := repo.ListQuotes(Where("status", Statuses...), Page{})
+	if err != nil {
+		return nil, err
+	}
+	for _, q := range quotes {
+		res.claim(q)
+	}
+	return res, nil
+}
+
+// index makes an entity findable by ID and by display name. IDs win
+// over names if the two ever collide.
+func index(m map[string]string, id, name string) {
+	if _, ok := m[fold(name)]; !ok && name != "" {
+		m[fold(name)] = id
+	}
+	m[id] = id
+}
+
+// fold normalises names and texts for matching: case, and runs of
+// whitespace and punctuation people type inconsistently.
+func fold(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '.' || r == ',' || r == '"' || r == '\'' || r == '-'
+	}), " ")
+}
+
+// claim records q's ID, slug and text as taken, for later rows.
+func (res *resolver) claim(q models.Quote) {
+	res.quoteIDs[q.ID] = true
+	if q.Slug != "" {
+		res.slugs[q.Slug] = q.ID
+	}
+	if t := fold(q.Text); t != "" {
+		res.texts[t] = q.ID
+	}
+}
+
+// quote builds a quote from a record's values and lists what's wrong
+// with it.
+func (res *resolver) quote(rec map[string]any) (models.Quote, []Problem) {
+	var q models.Quote
+	var problems []Problem
+	problem := func(field, format string, args ...any) {
+		problems = append(problems, Problem{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	values := map[string]any{}
+	for _, col := range slices.Sorted(maps.Keys(rec)) {
+		v := rec[col]
+		name := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(col)), " ", "_")
+		if key, ok := strings.CutPrefix(name, "meta."); ok && key != "" {
+			if q.Meta == nil {
+				q.Meta = map[string]any{}
+			}
+			q.Meta[key] = v
+			continue
+		}
+		field, ok := importColumns[name]
+		if !ok {
+			if !res.unknown[col] {
+				res.unknown[col] = true
+				problem(col, "unknown column")
+			}
+			continue
+		}
+		values[field] = v
+	}
+	text := func(field string) string { return strings.TrimSpace(scalar(values[field])) }
+
+	q.ID = text("id")
+	q.Slug = text("slug")
+	if q.ID == "" {
+		q.ID = q.Slug
+	}
+	q.Title = text("title")
+	q.Text = text("text")
+	q.TextScholarly = text("text_scholarly")
+	q.OriginalScript = text("original_script")
+	q.Source = text("source")
+	q.SourceLocation = text("source_location")
+	q.ExpositionBrief = text("exposition_brief")
+	q.ExpositionStandard = text("exposition_standard")
+	q.ExpositionScholarly = text("exposition_scholarly")
+	q.ReflectionPrompt = text("reflection_prompt")
+	q.ModernReinterpretation = text("modern_reinterpretation")
+
+	resolve := func(field, kind string, m map[string]string, v string) string {
+		if id, ok := m[v]; ok {
+			return id
+		}
+		if id, ok := m[fold(v)]; ok {
+			return id
+		}
+		problem(field, "unknown %s %q", kind, v)
+		return ""
+	}
+	if v := text("philosopher"); v != "" {
+		q.PhilosopherID = resolve("philosopher", "philosopher", res.philosophers, v)
+	}
+	if v := text("philosophy"); v != "" {
+		q.PhilosophyID = resolve("philosophy", "tradition", res.philosophies, v)
+	}
+	for _, v := range list(values["themes"]) {
+		if id := resolve("themes", "theme", res.themes, v); id != "" && !slices.Contains(q.ThemeIDs, id) {
+			q.ThemeIDs = append(q.ThemeIDs, id)
+		}
+	}
+	for _, v := range list(values["evidence"]) {
+		if id := resolve("evidence", "evidence", res.evidence, v); id != "" && !slices.Contains(q.EvidenceIDs, id) {
+			q.EvidenceIDs = append(q.EvidenceIDs, id)
+		}
+	}
+
+	// References were resolved above, so only the shape is left to check.
+	var verr *ValidationError
+	if err := CheckQuote(q, func(string, string) (bool, error) { return true, nil }); errors.As(err, &verr) {
+		problems = append(problems, verr.Problems...)
+	}
+
+	if res.quoteIDs[q.ID] {
+		problem("id", "duplicate: quote %q already exists", q.ID)
+	}
+	if owner, ok := res.slugs[q.Slug]; ok && q.Slug != "" {
+		problem("slug", "duplicate: slug %q is taken by quote %q", q.Slug, owner)
+	}
+	if owner, ok := res.texts[fold(q.Text)]; ok && q.Text != "" {
+		problem("text", "duplicate: same text as quote %q", owner)
+	}
+	res.claim(q)
+	return q, problems
+}
+
+// scalar renders a single value as text.
+func scalar(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []any:
+		return strings.Join(list(v), "; ")
+	}
+	return fmt.Sprint(v)
+}
+
+// list reads a multi-valued column: a JSON or YAML list, or text split on
+// semicolons, pipes or newlines — commas are left alone, since names
+// contain them.
+func list(v any) []string {
+	var raw []string
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case []any:
+		for _, item := range v {
+			raw = append(raw, scalar(item))
+		}
+	default:
+		raw = strings.FieldsFunc(scalar(v), func(r rune) bool { return r == ';' || r == '|' || r == '\n' })
+	}
+	var out []string
+	for _, s := range raw {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
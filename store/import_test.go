@@ -0,0 +1,108 @@
+package store_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"perennial-wisdom/models"
+	"perennial-wisdom/store"
+)
+
+const importCSV = "\ufeffid,Text,Philosopher,Tradition,Themes,Evidence,meta.sheet\n" +
+	"i1,First imported.,Epictetus,stoicism,dichotomy of control; Virtue,cognitive-reappraisal,A\n" +
+	",,,,,,\n" +
+	"i2,Second imported.,marcus-aurelius,stoic,impermanence,,B\n"
+
+func TestImportCSV(t *testing.T) {
+	s := store.New()
+	ch := store.Change{Author: "ana", Note: "import sheet"}
+
+	report, err := store.Import(s, strings.NewReader(importCSV), store.ImportOptions{Format: store.FormatCSV, DryRun: true, Change: ch})
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if !report.OK() || report.Rows != 2 || !reflect.DeepEqual(report.Created, []string{"i1", "i2"}) {
+		t.Fatalf("dry run report: %+v", report)
+	}
+	if _, err := s.GetQuote("i1"); !errors.Is(err, store.ErrNotFound) {
+		t.Fatalf("dry run wrote i1: %v", err)
+	}
+
+	report, err = store.Import(s, strings.NewReader(importCSV), store.ImportOptions{Format: store.FormatCSV, Change: ch})
+	if err != nil || !report.OK() {
+		t.Fatalf("import: %+v (%v)", report, err)
+	}
+	q, err := s.GetQuote("i1")
+	if err != nil {
+		t.Fatalf("GetQuote: %v", err)
+	}
+	want := models.Quote{
+		ID: "i1", Text: "First imported.", PhilosopherID: "epictetus", PhilosophyID: "stoic",
+		ThemeIDs: []string{"control", "virtue"}, EvidenceIDs: []string{"cognitive-reappraisal"},
+		Meta: map[string]any{"sheet": "A"}, Status: store.StatusDraft,
+	}
+	if !reflect.DeepEqual(q, want) {
+		t.Errorf("imported quote:\n got %+v\nwant %+v", q, want)
+	}
+	if revs, _ := s.ListRevisions("quote", "i2"); len(revs) != 1 || revs[0].Author != "ana" {
+		t.Errorf("expected one create revision by ana, got %+v", revs)
+	}
+}
+
+func TestImportReportsProblemsAndWritesNothing(t *testing.T) {
+	s := store.New()
+	yaml := `
+- id: e1
+  text: Duplicate of a seeded id.
+- id: y1
+  text: Fine on its own.
+  themes: [control, no-such-theme]
+- id: y1
+  text: Fine on its own.
+  philosopher: Nobody
+  colour: blue
+- text: No id at all.
+`
+	report, err := store.Import(s, strings.NewReader(yaml), store.ImportOptions{Format: store.FormatYAML})
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	var got []string
+	for _, p := range report.Problems {
+		got = append(got, fmt.Sprintf("%s@%d", p.Field, p.Row))
+	}
+	want := []string{"id@1", "themes@2", "colour@3", "philosopher@3", "id@3", "text@3", "id@4"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("problems = %v, want %v\n%+v", got, want, report.Problems)
+	}
+	if _, err := s.GetQuote("y1"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("a failed import must write nothing, found y1 (%v)", err)
+	}
+
+	if _, err := store.Import(s, strings.NewReader(`{"quotes": 3}`), store.ImportOptions{Format: store.FormatJSON}); err == nil {
+		t.Error("expected an error for JSON that isn't a list of quotes")
+	}
+}
+
+func TestBatchRollsBackOnError(t *testing.T) {
+	s := store.New()
+	boom := errors.New("boom")
+	err := s.Batch(func(rw store.ReadWriter) error {
+		if err := rw.CreateQuote(models.Quote{ID: "batch-1", Text: "Kept?"}, store.Change{}); err != nil {
+			return err
+		}
+		return boom
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("expected the batch's error, got %v", err)
+	}
+	if _, err := s.GetQuote("batch-1"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("write inside a failed batch was kept: %v", err)
+	}
+	if revs, _ := s.ListRevisions("quote", "batch-1"); len(revs) != 0 {
+		t.Errorf("revision inside a failed batch was kept: %+v", revs)
+	}
+}
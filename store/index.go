@@ -0,0 +1,373 @@
+package store
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"perennial-wisdom/models"
+)
+
+// BM25 parameters: k1 is how quickly repeating a term stops paying off,
+// b how much a long document is penalised for its length.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Index is an in-process inverted index over quotes, for the backends
+// with no full-text search of their own: the in-memory store and SQLite.
+// It folds away case and diacritics, stems and drops stopwords the way
+// PostgreSQL's english configuration does, and ranks with BM25, term
+// frequencies weighted by field as quoteFields lists. A query word the
+// index has never seen also matches the words a typo or two away.
+//
+// The index holds quotes of every status and applies Live when
+// searched, so a scheduled quote turns up once its time comes without a
+// write. It is safe for concurrent use.
+type Index struct {
+	mu     sync.RWMutex
+	quotes map[string]models.Quote
+	text   *inverted
+}
+
+// NewIndex indexes quotes.
+func NewIndex(quotes []models.Quote) *Index {
+	x := &Index{}
+	x.Reset(quotes)
+	return x
+}
+
+// Reset replaces everything in the index with quotes.
+func (x *Index) Reset(quotes []models.Quote) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.quotes = make(map[string]models.Quote, len(quotes))
+	x.text = newInverted()
+	for _, q := range quotes {
+		x.quotes[q.ID] = q
+		x.text.add(q.ID, quoteFields(q))
+	}
+}
+
+// Put adds q to the index, replacing any earlier version of it.
+func (x *Index) Put(q models.Quote) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.text.drop(q.ID)
+	x.quotes[q.ID] = q
+	x.text.add(q.ID, quoteFields(q))
+}
+
+// Remove drops a quote from the index; unknown IDs are ignored.
+func (x *Index) Remove(id string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.text.drop(id)
+	delete(x.quotes, id)
+}
+
+// Len is the number of quotes indexed.
+func (x *Index) Len() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return len(x.quotes)
+}
+
+// Vocabulary counts the live quotes that use each indexed word, as the
+// package-level Vocabulary does for a list of quotes. Words only drafts
+// use stay out of it: suggestions must not leak them.
+func (x *Index) Vocabulary() map[string]int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	vocab := map[string]int{}
+	for id, q := range x.quotes {
+		if Live(q) {
+			for _, w := range x.text.docs[id].words {
+				vocab[w]++
+			}
+		}
+	}
+	return vocab
+}
+
+// Search answers a SearchQuery from the index with the contract of the
+// PostgreSQL backend: every query term must match, after folding and
+// stemming, and a term written -term must not; stopwords are ignored, so
+// a query of nothing else finds nothing. Only live quotes are found.
+// name gives facets their display names, by kind ("philosophy" or
+// "theme") and ID.
+func (x *Index) Search(s SearchQuery, name func(kind, id string) string) SearchResults {
+	res := SearchResults{Hits: []SearchHit{}, Facets: map[string][]Facet{"tradition": {}, "theme": {}}, Names: []NameMatch{}}
+	// Search is public: a status condition can't widen it.
+	f := Filter{}
+	for dim, c := range s.Filter {
+		if dim != "status" {
+			f[dim] = c
+		}
+	}
+
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	scores, find := x.text.match(s.Text)
+	var hits []SearchHit
+	var matched []models.Quote
+	for id, score := range scores {
+		q := x.quotes[id]
+		if !Live(q) || !f.Match(QuoteValues(q)) {
+			continue
+		}
+		matched = append(matched, q)
+		hits = append(hits, SearchHit{Quote: q, Rank: score})
+	}
+	slices.SortFunc(hits, func(a, b SearchHit) int {
+		return cmp.Or(cmp.Compare(b.Rank, a.Rank), cmp.Compare(a.Quote.ID, b.Quote.ID))
+	})
+
+	res.Total = len(hits)
+	res.Facets = countFacets(matched, name)
+	res.Hits = PageHits(hits, s)
+	for i := range res.Hits {
+		res.Hits[i].Snippet = snippet(quoteFields(res.Hits[i].Quote), find)
+	}
+	return res
+}
+
+// inverted is the text side of an index: postings plus the statistics
+// BM25 needs. Index keeps one for quotes; SearchAll builds one per kind
+// of entity for each search. It does no locking of its own.
+type inverted struct {
+	docs     map[string]docStats
+	postings map[string]map[string]float64 // stem → doc ID → weighted term frequency
+	words    map[string]int                // folded word → docs using it, for typos
+	length   float64                       // weighted length of every doc, summed
+}
+
+// docStats is what inverted keeps of one document.
+type docStats struct {
+	length float64  // weighted count of the doc's indexed terms
+	stems  []string // distinct, so drop can find its postings
+	words  []string // distinct, for vocabularies
+}
+
+func newInverted() *inverted {
+	return &inverted{docs: map[string]docStats{}, postings: map[string]map[string]float64{}, words: map[string]int{}}
+}
+
+// add indexes a document's fields under id, which must not be indexed yet.
+func (v *inverted) add(id string, fields []field) {
+	tf, length := stemFrequencies(fields)
+	doc := docStats{length: length}
+	for s, n := range tf {
+		if v.postings[s] == nil {
+			v.postings[s] = map[string]float64{}
+		}
+		v.postings[s][id] = n
+		doc.stems = append(doc.stems, s)
+	}
+	for w := range fieldWords(fields) {
+		v.words[w]++
+		doc.words = append(doc.words, w)
+	}
+	v.docs[id] = doc
+	v.length += doc.length
+}
+
+// stemFrequencies counts the stems of fields' words, stopwords aside,
+// each occurrence weighted by its field; length is the sum of them all.
+func stemFrequencies(fields []field) (tf map[string]float64, length float64) {
+	tf = map[string]float64{}
+	for _, f := range fields {
+		for _, tok := range tokenize(f.text) {
+			if stopwords[tok.word] {
+				continue
+			}
+			tf[stem(tok.word)] += f.weight
+			length += f.weight
+		}
+	}
+	return tf, length
+}
+
+// drop removes a document; unknown IDs are ignored.
+func (v *inverted) drop(id string) {
+	doc, ok := v.docs[id]
+	if !ok {
+		return
+	}
+	for _, s := range doc.stems {
+		delete(v.postings[s], id)
+		if len(v.postings[s]) == 0 {
+			delete(v.postings, s)
+		}
+	}
+	for _, w := range doc.words {
+		if v.words[w]--; v.words[w] == 0 {
+			delete(v.words, w)
+		}
+	}
+	delete(v.docs, id)
+	v.length -= doc.length
+}
+
+// match scores every document that matches search text with BM25,
+// rounded so backends agree, and returns a finder for the words that
+// matched, to mark snippets up with. A query term no document has
+// matches the words a typo away instead, at fuzzyWeight.
+func (v *inverted) match(text string) (map[string]float64, func(string) [][]int) {
+	scores := map[string]float64{}
+	include, exclude := queryTerms(text)
+	if len(include) == 0 {
+		return scores, stemMatcher(nil)
+	}
+	type term struct {
+		stems  []string // the stem, or the stems of the words a typo away
+		weight float64
+	}
+	terms := make([]term, len(include))
+	var marked []string
+	for i, t := range include {
+		terms[i] = term{stems: []string{t.stem}, weight: 1}
+		if v.postings[t.stem] == nil {
+			terms[i] = term{stems: v.nearStems(t.word), weight: fuzzyWeight}
+		}
+		marked = append(marked, terms[i].stems...)
+	}
+	// Every match is in the postings of the rarest term; walk those.
+	candidates := func(t term) map[string]bool {
+		ids := map[string]bool{}
+		for _, st := range t.stems {
+			for id := range v.postings[st] {
+				ids[id] = true
+			}
+		}
+		return ids
+	}
+	walk := candidates(terms[0])
+	for _, t := range terms[1:] {
+		if ids := candidates(t); len(ids) < len(walk) {
+			walk = ids
+		}
+	}
+
+	n := float64(len(v.docs))
+	avg := v.length / max(n, 1)
+	for id := range walk {
+		doc := v.docs[id]
+		score, ok := 0.0, true
+		for _, t := range terms {
+			best, has := 0.0, false
+			for _, st := range t.stems {
+				tf, in := v.postings[st][id]
+				if !in {
+					continue
+				}
+				df := float64(len(v.postings[st]))
+				idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+				best = max(best, idf*tf*(bm25K1+1)/(tf+bm25K1*(1-bm25B+bm25B*doc.length/max(avg, 1e-9))))
+				has = true
+			}
+			if !has {
+				ok = false
+				break
+			}
+			score += t.weight * best
+		}
+		for _, st := range exclude {
+			if _, has := v.postings[st][id]; has {
+				ok = false
+			}
+		}
+		if ok {
+			scores[id] = math.Round(score*1e6) / 1e6
+		}
+	}
+	return scores, stemMatcher(marked)
+}
+
+// nearStems are the stems of the indexed words within typos of word.
+func (v *inverted) nearStems(word string) []string {
+	var stems []string
+	limit := typos(utf8.RuneCountInString(word))
+	if limit == 0 {
+		return nil
+	}
+	for w := range v.words {
+		if abs(utf8.RuneCountInString(w)-utf8.RuneCountInString(word)) > limit || editDistance(word, w) > limit {
+			continue
+		}
+		if st := stem(w); !slices.Contains(stems, st) {
+			stems = append(stems, st)
+		}
+	}
+	return stems
+}
+
+func abs(n int) int { return max(n, -n) }
+
+// queryTerm is a query word and its stem.
+type queryTerm struct{ word, stem string }
+
+// queryTerms splits search text into the terms a match must have and
+// the stems of those, written -term, it must not. Stopwords are dropped
+// from both.
+func queryTerms(text string) (include []queryTerm, exclude []string) {
+	for _, tok := range tokenize(text) {
+		if stopwords[tok.word] {
+			continue
+		}
+		t := queryTerm{tok.word, stem(tok.word)}
+		negated := tok.start > 0 && text[tok.start-1] == '-' &&
+			(tok.start == 1 || unicode.IsSpace(rune(text[tok.start-2])))
+		switch {
+		case negated && !slices.Contains(exclude, t.stem):
+			exclude = append(exclude, t.stem)
+		case !negated && !slices.ContainsFunc(include, func(u queryTerm) bool { return u.stem == t.stem }):
+			include = append(include, t)
+		}
+	}
+	return include, exclude
+}
+
+// stemMatcher finds the words in a text whose stems are among stems,
+// for marking up snippets.
+func stemMatcher(stems []string) func(string) [][]int {
+	return func(text string) [][]int {
+		var found [][]int
+		for _, tok := range tokenize(text) {
+			if !stopwords[tok.word] && slices.Contains(stems, stem(tok.word)) {
+				found = append(found, []int{tok.start, tok.end})
+			}
+		}
+		return found
+	}
+}
+
+// token is one word of a text, folded with Unaccent, and where it was found.
+type token struct {
+	word       string
+	start, end int // byte offsets into the text
+}
+
+// tokenize splits text into runs of letters and digits.
+func tokenize(text string) []token {
+	var toks []token
+	start := -1
+	for i, r := range text {
+		word := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case word && start < 0:
+			start = i
+		case !word && start >= 0:
+			toks = append(toks, token{Unaccent(text[start:i]), start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		toks = append(toks, token{Unaccent(text[start:]), start, len(text)})
+	}
+	return toks
+}
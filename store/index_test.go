@@ -0,0 +1,129 @@
+package store_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"perennial-wisdom/models"
+	"perennial-wisdom/store"
+)
+
+func noNames(kind, id string) string { return "" }
+
+func hitIDs(res store.SearchResults) []string {
+	ids := make([]string, len(res.Hits))
+	for i, h := range res.Hits {
+		ids[i] = h.Quote.ID
+	}
+	return ids
+}
+
+func TestIndexStemsAndDropsStopwords(t *testing.T) {
+	x := store.NewIndex([]models.Quote{
+		{ID: "a", Text: "Meditation quiets the restless mind."},
+		{ID: "b", Text: "He meditated on the relations of things."},
+		{ID: "c", Text: "The mind is restless."},
+	})
+	for query, want := range map[string][]string{
+		"meditating":        {"a", "b"},
+		"MEDITATE related":  {"b"},
+		"mind -meditations": {"c"},
+		"restlessness":      {"a", "c"},
+		"the is of":         {},
+	} {
+		res := x.Search(store.SearchQuery{Text: query}, noNames)
+		got := hitIDs(res)
+		slices.Sort(got)
+		if len(got) != len(want) {
+			t.Errorf("%q: got %v, want %v", query, got, want)
+			continue
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("%q: got %v, want %v", query, got, want)
+				break
+			}
+		}
+	}
+	res := x.Search(store.SearchQuery{Text: "meditating quiet"}, noNames)
+	if got, want := res.Hits[0].Snippet, "<mark>Meditation</mark> <mark>quiets</mark> the restless mind."; got != want {
+		t.Errorf("snippet:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestIndexRanksWithBM25(t *testing.T) {
+	x := store.NewIndex([]models.Quote{
+		{ID: "rare", Text: "Stillness and ataraxia."},
+		{ID: "common", Text: "Stillness, stillness, stillness."},
+		{ID: "long", Text: "Stillness", ExpositionBrief: "A long brief that goes on and on about many other matters entirely."},
+		{ID: "other", Text: "Nothing to see."},
+	})
+	res := x.Search(store.SearchQuery{Text: "stillness"}, noNames)
+	// Repetition pays off, with diminishing returns; length costs.
+	if got := hitIDs(res); len(got) != 3 || got[0] != "common" || got[2] != "long" {
+		t.Errorf("ranking: %v", got)
+	}
+	both := x.Search(store.SearchQuery{Text: "stillness ataraxia"}, noNames)
+	if got := hitIDs(both); len(got) != 1 || got[0] != "rare" || both.Hits[0].Rank <= res.Hits[0].Rank {
+		t.Errorf("a rare term should weigh more: %v", both.Hits)
+	}
+}
+
+func TestIndexFollowsWrites(t *testing.T) {
+	s := store.New()
+	found := func(query string) []string {
+		t.Helper()
+		res, err := s.SearchQuotes(store.SearchQuery{Text: query})
+		if err != nil {
+			t.Fatalf("SearchQuotes: %v", err)
+		}
+		return hitIDs(res)
+	}
+	q := models.Quote{ID: "idx-1", Text: "The quincunx of virtue.", PhilosopherID: "seneca", PhilosophyID: "stoic",
+		Status: store.StatusPublished}
+	if err := s.CreateQuote(q, store.Change{Author: "ana"}); err != nil {
+		t.Fatalf("CreateQuote: %v", err)
+	}
+	if got := found("quincunx"); len(got) != 1 {
+		t.Fatalf("created quote not found: %v", got)
+	}
+
+	q.Text = "The heptagon of virtue."
+	if err := s.UpdateQuote(q, store.Change{Author: "ana"}); err != nil {
+		t.Fatalf("UpdateQuote: %v", err)
+	}
+	if got := found("quincunx"); len(got) != 0 {
+		t.Errorf("old text still found: %v", got)
+	}
+	if got := found("heptagons"); len(got) != 1 {
+		t.Errorf("new text not found: %v", got)
+	}
+
+	// A batch that fails leaves the index as it was.
+	err := s.Batch(func(rw store.ReadWriter) error {
+		if err := rw.DeleteQuote("idx-1", store.Change{Author: "ana"}); err != nil {
+			return err
+		}
+		return errors.New("abandon")
+	})
+	if err == nil || len(found("heptagon")) != 1 {
+		t.Errorf("rolled-back delete changed the index (err %v)", err)
+	}
+
+	// Deleting a school clears it from the indexed quote's facets.
+	if err := s.DeletePhilosophy("stoic", store.Change{Author: "ana"}); err != nil {
+		t.Fatalf("DeletePhilosophy: %v", err)
+	}
+	res, _ := s.SearchQuotes(store.SearchQuery{Text: "heptagon"})
+	if len(res.Facets["tradition"]) != 0 {
+		t.Errorf("tradition facets after delete: %+v", res.Facets["tradition"])
+	}
+
+	if err := s.DeleteQuote("idx-1", store.Change{Author: "ana"}); err != nil {
+		t.Fatalf("DeleteQuote: %v", err)
+	}
+	if got := found("heptagon"); len(got) != 0 {
+		t.Errorf("deleted quote still found: %v", got)
+	}
+}
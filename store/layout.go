@@ -0,0 +1,209 @@
+package store
+
+import (
+	"math"
+	"slices"
+)
+
+// Force layout parameters: how many rounds to run, and how far apart
+// connected nodes settle, relative to the space each node would have if
+// they shared the canvas evenly.
+const (
+	layoutRounds  = 300
+	layoutSpacing = 0.9
+	layoutMargin  = 60 // canvas units kept clear around the drawing, for labels
+)
+
+// Layout is a graph placed on a width × height canvas, ready to draw.
+type Layout struct {
+	Width, Height float64
+	Root          string // the node the layout centres on, if any
+	Nodes         []PlacedNode
+	Edges         []PlacedEdge
+}
+
+// PlacedNode is a node and where it is drawn.
+type PlacedNode struct {
+	GraphNode
+	X, Y   float64
+	Degree int
+	Root   bool
+}
+
+// PlacedEdge is an edge and the ends of the line drawn for it.
+type PlacedEdge struct {
+	GraphEdge
+	X1, Y1, X2, Y2 float64
+}
+
+// Only is the subgraph of the nodes of the given kinds and the edges
+// between them.
+func (g Graph) Only(kinds ...string) Graph {
+	var sub Graph
+	keep := map[string]bool{}
+	for _, n := range g.Nodes {
+		if slices.Contains(kinds, n.Kind) {
+			sub.Nodes = append(sub.Nodes, n)
+			keep[n.ID] = true
+		}
+	}
+	for _, e := range g.Edges {
+		if keep[e.Source] && keep[e.Target] {
+			sub.Edges = append(sub.Edges, e)
+		}
+	}
+	return sub
+}
+
+// Layout places g with a force-directed (Fruchterman–Reingold) layout:
+// every pair of nodes pushes apart, every edge pulls its ends together,
+// and the moves shrink round by round until the drawing settles. Nodes
+// start on rings by their distance in links from the centre — root, if
+// it is a node of g, else the best-connected node — which stays pinned
+// in the middle of the canvas.
+//
+// There is no randomness: the same graph always comes out the same, so
+// pages can be cached and every replica draws alike.
+func (g Graph) Layout(root string, width, height float64) Layout {
+	l := Layout{Width: width, Height: height}
+	n := len(g.Nodes)
+	if n == 0 {
+		return l
+	}
+	index := make(map[string]int, n)
+	for i, node := range g.Nodes {
+		index[node.ID] = i
+	}
+	degree := g.degrees()
+	adjacent := make([][]int, n)
+	for _, e := range g.Edges {
+		s, t := index[e.Source], index[e.Target]
+		adjacent[s] = append(adjacent[s], t)
+		adjacent[t] = append(adjacent[t], s)
+	}
+
+	centre, ok := index[root]
+	if !ok {
+		for i, node := range g.Nodes {
+			if degree[node.ID] > degree[g.Nodes[centre].ID] {
+				centre = i
+			}
+		}
+	} else {
+		l.Root = root
+	}
+
+	// Rings by breadth-first distance; the unreachable go outside.
+	dist := make([]int, n)
+	for i := range dist {
+		dist[i] = -1
+	}
+	dist[centre] = 0
+	queue, far := []int{centre}, 0
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+		for _, j := range adjacent[i] {
+			if dist[j] < 0 {
+				dist[j] = dist[i] + 1
+				far = max(far, dist[j])
+				queue = append(queue, j)
+			}
+		}
+	}
+	rings := map[int][]int{}
+	for i := range dist {
+		if dist[i] < 0 {
+			dist[i] = far + 1
+		}
+		rings[dist[i]] = append(rings[dist[i]], i)
+	}
+	x, y := make([]float64, n), make([]float64, n)
+	for r, members := range rings {
+		for pos, i := range members {
+			angle := 2*math.Pi*float64(pos)/float64(len(members)) + float64(r)*0.5
+			x[i] = float64(r) * math.Cos(angle)
+			y[i] = float64(r) * math.Sin(angle)
+		}
+	}
+
+	// Work in a square of side √n, where each node has room 1 and so
+	// the ideal distance between neighbours is about 1 whatever the size.
+	side := math.Sqrt(float64(n))
+	for i := range x {
+		x[i] *= side / float64(far+2)
+		y[i] *= side / float64(far+2)
+	}
+	const k = layoutSpacing
+	dx, dy := make([]float64, n), make([]float64, n)
+	for round := range layoutRounds {
+		clear(dx)
+		clear(dy)
+		for i := range n {
+			for j := i + 1; j < n; j++ {
+				ux, uy := x[i]-x[j], y[i]-y[j]
+				d := max(math.Hypot(ux, uy), 0.01)
+				f := k * k / d / d
+				dx[i] += ux * f
+				dy[i] += uy * f
+				dx[j] -= ux * f
+				dy[j] -= uy * f
+			}
+		}
+		for _, e := range g.Edges {
+			i, j := index[e.Source], index[e.Target]
+			ux, uy := x[i]-x[j], y[i]-y[j]
+			f := math.Hypot(ux, uy) / k
+			dx[i] -= ux * f
+			dy[i] -= uy * f
+			dx[j] += ux * f
+			dy[j] += uy * f
+		}
+		temp := side / 10 * (1 - float64(round)/layoutRounds)
+		for i := range n {
+			if i == centre {
+				continue
+			}
+			if d := math.Hypot(dx[i], dy[i]); d > 0 {
+				step := min(d, temp) / d
+				x[i] += dx[i] * step
+				y[i] += dy[i] * step
+			}
+		}
+	}
+
+	// Fit the drawing to the canvas around the centre.
+	reachX, reachY := 1e-9, 1e-9
+	for i := range n {
+		reachX = max(reachX, math.Abs(x[i]-x[centre]))
+		reachY = max(reachY, math.Abs(y[i]-y[centre]))
+	}
+	sx := (width/2 - layoutMargin) / reachX
+	sy := (height/2 - layoutMargin) / reachY
+	place := func(i int) (float64, float64) {
+		return round1(width/2 + (x[i]-x[centre])*sx), round1(height/2 + (y[i]-y[centre])*sy)
+	}
+	for i, node := range g.Nodes {
+		px, py := place(i)
+		l.Nodes = append(l.Nodes, PlacedNode{GraphNode: node, X: px, Y: py, Degree: degree[node.ID], Root: node.ID == l.Root})
+	}
+	for _, e := range g.Edges {
+		x1, y1 := place(index[e.Source])
+		x2, y2 := place(index[e.Target])
+		l.Edges = append(l.Edges, PlacedEdge{GraphEdge: e, X1: x1, Y1: y1, X2: x2, Y2: y2})
+	}
+	return l
+}
+
+// degrees counts each node's links, either way.
+func (g Graph) degrees() map[string]int {
+	d := make(map[string]int, len(g.Nodes))
+	for _, e := range g.Edges {
+		d[e.Source]++
+		d[e.Target]++
+	}
+	return d
+}
+
+// round1 rounds to one decimal place, plenty for a drawing.
+func round1(x float64) float64 { return math.Round(x*10) / 10 }
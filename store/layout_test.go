@@ -0,0 +1,62 @@
+package store_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"perennial-wisdom/store"
+)
+
+func TestGraphLayoutFitsAndRepeats(t *testing.T) {
+	g, err := store.BuildGraph(store.New())
+	if err != nil {
+		t.Fatalf("BuildGraph: %v", err)
+	}
+	g = g.Only("philosophy", "philosopher", "theme", "evidence")
+	for _, n := range g.Nodes {
+		if n.Kind == "quote" {
+			t.Fatalf("Only kept a quote: %+v", n)
+		}
+	}
+
+	l := g.Layout("philosophy:stoic", 800, 600)
+	if len(l.Nodes) != len(g.Nodes) || len(l.Edges) != len(g.Edges) || l.Root != "philosophy:stoic" {
+		t.Fatalf("layout: %d nodes, %d edges, root %q", len(l.Nodes), len(l.Edges), l.Root)
+	}
+	at := map[string][2]float64{}
+	for _, n := range l.Nodes {
+		if n.X < 0 || n.X > 800 || n.Y < 0 || n.Y > 600 {
+			t.Errorf("%s off the canvas at (%v, %v)", n.ID, n.X, n.Y)
+		}
+		if n.Root != (n.ID == "philosophy:stoic") {
+			t.Errorf("%s: root %v", n.ID, n.Root)
+		}
+		at[n.ID] = [2]float64{n.X, n.Y}
+	}
+	if at["philosophy:stoic"] != [2]float64{400, 300} {
+		t.Errorf("the root belongs in the middle, not at %v", at["philosophy:stoic"])
+	}
+	for i, a := range l.Nodes {
+		for _, b := range l.Nodes[i+1:] {
+			if math.Hypot(a.X-b.X, a.Y-b.Y) < 5 {
+				t.Errorf("%s and %s overlap", a.ID, b.ID)
+			}
+		}
+	}
+	for _, e := range l.Edges {
+		if at[e.Source] != [2]float64{e.X1, e.Y1} || at[e.Target] != [2]float64{e.X2, e.Y2} {
+			t.Errorf("edge %+v doesn't meet its nodes", e)
+		}
+	}
+
+	if again := g.Layout("philosophy:stoic", 800, 600); !reflect.DeepEqual(again, l) {
+		t.Error("the same graph must lay out the same way")
+	}
+	if whole := g.Layout("", 800, 600); whole.Root != "" || len(whole.Nodes) != len(g.Nodes) {
+		t.Errorf("unrooted layout: root %q, %d nodes", whole.Root, len(whole.Nodes))
+	}
+	if empty := (store.Graph{}).Layout("", 800, 600); len(empty.Nodes) != 0 {
+		t.Errorf("empty graph: %+v", empty)
+	}
+}
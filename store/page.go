@@ -0,0 +1,159 @@
+package store
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Page sizes for list endpoints.
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// ErrBadCursor is returned by ParsePage for cursors it didn't issue.
+var ErrBadCursor = errors.New("invalid cursor")
+
+// Page selects one window of a list ordered by a key: the ID, or for
+// lists ordered by name, NameKey. Lists are keyset paginated: a page
+// starts strictly after (or ends strictly before) a known key, so
+// inserts and deletes never shift or repeat items.
+// The zero Page means "everything".
+type Page struct {
+	Limit  int    // 0 = no limit
+	After  string // items with key > After
+	Before string // items with key < Before (walking backwards)
+}
+
+// keySep joins the parts of a composite key. It sorts before any other
+// character, so keys compare as the tuples of their parts do.
+const keySep = "\x00"
+
+// NameKey is the key of an item in a list ordered by name: the name,
+// then the ID to order items of the same name.
+func NameKey(name, id string) string { return name + keySep + id }
+
+// KeyParts splits key into the n parts of a composite key, as SQL
+// repositories need to compare them column by column. Parts a key
+// lacks are empty.
+func KeyParts(key string, n int) []string {
+	parts := strings.SplitN(key, keySep, n)
+	for len(parts) < n {
+		parts = append(parts, "")
+	}
+	return parts
+}
+
+// Cursors point at the pages either side of the one returned.
+// Empty means there is no such page.
+type Cursors struct {
+	Next string
+	Prev string
+}
+
+// ParsePage reads ?limit= and ?cursor= from URL query parameters.
+// limit defaults to DefaultLimit and is capped at MaxLimit.
+func ParsePage(q url.Values) (Page, error) {
+	p := Page{Limit: DefaultLimit}
+	if raw := q.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			return Page{}, errors.New("limit must be a positive integer")
+		}
+		p.Limit = min(n, MaxLimit)
+	}
+	if raw := q.Get("cursor"); raw != "" {
+		b, err := base64.RawURLEncoding.DecodeString(raw)
+		if err != nil {
+			return Page{}, ErrBadCursor
+		}
+		dir, key, ok := strings.Cut(string(b), ":")
+		switch {
+		case !ok || key == "":
+			return Page{}, ErrBadCursor
+		case dir == "a":
+			p.After = key
+		case dir == "b":
+			p.Before = key
+		default:
+			return Page{}, ErrBadCursor
+		}
+	}
+	return p, nil
+}
+
+// after and before encode opaque cursors; clients pass them back verbatim.
+func after(key string) string  { return base64.RawURLEncoding.EncodeToString([]byte("a:" + key)) }
+func before(key string) string { return base64.RawURLEncoding.EncodeToString([]byte("b:" + key)) }
+
+// Window applies p to items already sorted by ascending key.
+// The in-memory store uses it directly; SQL repositories fetch the
+// window themselves and call Trim.
+func Window[T any](p Page, items []T, key func(T) string) ([]T, Cursors) {
+	if p.Limit <= 0 {
+		return items, Cursors{}
+	}
+	var win []T
+	switch {
+	case p.Before != "":
+		for _, it := range items {
+			if key(it) < p.Before {
+				win = append(win, it)
+			}
+		}
+		if len(win) > p.Limit+1 {
+			win = win[len(win)-p.Limit-1:]
+		}
+	default:
+		for _, it := range items {
+			if key(it) > p.After {
+				win = append(win, it)
+				if len(win) > p.Limit {
+					break
+				}
+			}
+		}
+	}
+	return Trim(p, win, key)
+}
+
+// Trim cuts a fetched window down to p.Limit items and works out the
+// cursors. win is in ascending key order and holds up to Limit+1 items:
+// the extra one, on the side p travels towards, signals another page.
+func Trim[T any](p Page, win []T, key func(T) string) ([]T, Cursors) {
+	if p.Limit <= 0 {
+		return win, Cursors{}
+	}
+	more := len(win) > p.Limit
+	backwards := p.Before != ""
+	if more {
+		if backwards {
+			win = win[1:]
+		} else {
+			win = win[:p.Limit]
+		}
+	}
+	if len(win) == 0 {
+		return win, Cursors{}
+	}
+
+	var c Cursors
+	first, last := key(win[0]), key(win[len(win)-1])
+	if backwards {
+		c.Next = after(last)
+		if more {
+			c.Prev = before(first)
+		}
+	} else {
+		if more {
+			c.Next = after(last)
+		}
+		if p.After != "" {
+			c.Prev = before(first)
+		}
+	}
+	return win, c
+}
@@ -0,0 +1,126 @@
+package store_test
+
+import (
+	"cmp"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"perennial-wisdom/models"
+	"perennial-wisdom/store"
+)
+
+func TestParsePage(t *testing.T) {
+	p, err := store.ParsePage(url.Values{})
+	if err != nil || p.Limit != store.DefaultLimit {
+		t.Errorf("default page: %+v, %v", p, err)
+	}
+	p, _ = store.ParsePage(url.Values{"limit": {"100000"}})
+	if p.Limit != store.MaxLimit {
+		t.Errorf("limit not capped: %d", p.Limit)
+	}
+	for _, bad := range []url.Values{
+		{"limit": {"0"}},
+		{"limit": {"ten"}},
+		{"cursor": {"!!"}},
+		{"cursor": {"eDp4"}}, // "x:x" — unknown direction
+	} {
+		if _, err := store.ParsePage(bad); err == nil {
+			t.Errorf("ParsePage(%v): expected error", bad)
+		}
+	}
+}
+
+// Walking next links forward and prev links back must visit every item
+// exactly once, in ID order, in both directions.
+func TestWindowRoundTrip(t *testing.T) {
+	items := []string{"a", "b", "c", "d", "e", "f", "g"}
+	id := func(s string) string { return s }
+
+	var forward []string
+	var pages []store.Cursors
+	p := store.Page{Limit: 3}
+	for {
+		got, cur := store.Window(p, items, id)
+		forward = append(forward, got...)
+		pages = append(pages, cur)
+		if cur.Next == "" {
+			break
+		}
+		p = mustParse(t, cur.Next)
+	}
+	if !reflect.DeepEqual(forward, items) {
+		t.Fatalf("forward walk: %v", forward)
+	}
+	if len(pages) != 3 || pages[0].Prev != "" {
+		t.Fatalf("expected 3 pages with no prev on the first, got %+v", pages)
+	}
+
+	var backward []string
+	p = mustParse(t, pages[2].Prev)
+	for {
+		got, cur := store.Window(p, items, id)
+		backward = append(got, backward...)
+		if cur.Prev == "" {
+			break
+		}
+		p = mustParse(t, cur.Prev)
+	}
+	if !reflect.DeepEqual(backward, items[:6]) {
+		t.Errorf("backward walk: %v", backward)
+	}
+}
+
+func TestWindowZeroPageReturnsEverything(t *testing.T) {
+	items := []string{"a", "b"}
+	got, cur := store.Window(store.Page{}, items, func(s string) string { return s })
+	if len(got) != 2 || cur != (store.Cursors{}) {
+		t.Errorf("zero page: %v %+v", got, cur)
+	}
+}
+
+func mustParse(t *testing.T, cursor string) store.Page {
+	t.Helper()
+	p, err := store.ParsePage(url.Values{"cursor": {cursor}, "limit": {"3"}})
+	if err != nil {
+		t.Fatalf("ParsePage(%q): %v", cursor, err)
+	}
+	return p
+}
+
+// Traditions and themes list by name; paging through them must keep
+// that order, and keep items that share a name apart by ID.
+func TestNameOrderedListsPageByName(t *testing.T) {
+	s := store.New()
+	death, _ := s.GetTheme("death")
+	twin := models.Theme{ID: "a-death", Name: death.Name, Description: "Another theme of the same name."}
+	if err := s.CreateTheme(twin, store.Change{Author: "ana"}); err != nil {
+		t.Fatalf("CreateTheme: %v", err)
+	}
+
+	all, _, _ := s.ListThemes(store.Page{})
+	if !slices.IsSortedFunc(all, func(a, b models.Theme) int {
+		return cmp.Or(strings.Compare(a.Name, b.Name), strings.Compare(a.ID, b.ID))
+	}) {
+		t.Fatalf("themes not in name order: %v", all)
+	}
+
+	var walked []models.Theme
+	p := store.Page{Limit: 3}
+	for {
+		got, cur, err := s.ListThemes(p)
+		if err != nil {
+			t.Fatalf("ListThemes: %v", err)
+		}
+		walked = append(walked, got...)
+		if cur.Next == "" {
+			break
+		}
+		p = mustParse(t, cur.Next)
+	}
+	if !reflect.DeepEqual(walked, all) {
+		t.Errorf("paged walk differs from the full list:\nwalked %v\nall    %v", walked, all)
+	}
+}
@@ -0,0 +1,90 @@
+package store
+
+import (
+	"cmp"
+	"slices"
+
+	"perennial-wisdom/models"
+)
+
+// Parallels is a theme's live quotes side by side, one column per
+// tradition: the same thread followed across schools.
+type Parallels struct {
+	Theme   models.Theme     `json:"theme"`
+	Columns []ParallelColumn `json:"traditions"`
+	Gaps    []string         `json:"gaps"`    // listed traditions with no quote yet
+	Covered int              `json:"covered"` // listed traditions with a quote
+}
+
+// Unattributed names the column of quotes that belong to no tradition.
+const Unattributed = "Unattributed"
+
+// ParallelColumn is one tradition's quotes on a theme.
+type ParallelColumn struct {
+	PhilosophyID string         `json:"philosophy_id"` // "" for the Unattributed column
+	Name         string         `json:"name"`          // "" if the tradition is unknown
+	Listed       bool           `json:"listed"`        // in the theme's PhilosophyIDs
+	Gap          bool           `json:"gap"`           // listed, but no quote yet
+	Quotes       []models.Quote `json:"quotes"`
+}
+
+// ThemeParallels groups the live quotes on theme id by tradition. Every
+// tradition the theme lists gets a column, in the theme's order, even
+// with no quote — flagged as a gap for editors to fill. Traditions with
+// quotes on the theme that it doesn't list follow, by ID, and quotes
+// with no tradition come last, in a column named Unattributed.
+func ThemeParallels(repo Repository, id string) (Parallels, error) {
+	t, err := repo.GetTheme(id)
+	if err != nil {
+		return Parallels{}, err
+	}
+	quotes, _, err := repo.ListQuotes(Where("theme", t.ID), Page{})
+	if err != nil {
+		return Parallels{}, err
+	}
+
+	p := Parallels{Theme: t, Columns: []ParallelColumn{}, Gaps: []string{}}
+	at := map[string]int{}
+	column := func(pid string, listed bool) int {
+		if i, ok := at[pid]; ok {
+			return i
+		}
+		ph, _ := repo.GetPhilosophy(pid)
+		if pid == "" {
+			ph.Name = Unattributed
+		}
+		at[pid] = len(p.Columns)
+		p.Columns = append(p.Columns, ParallelColumn{PhilosophyID: pid, Name: ph.Name, Listed: listed, Quotes: []models.Quote{}})
+		return at[pid]
+	}
+	for _, pid := range t.PhilosophyIDs {
+		column(pid, true)
+	}
+	listed := len(p.Columns)
+	// Unlisted traditions come in ID order, unattributed quotes last;
+	// quotes keep their order.
+	slices.SortStableFunc(quotes, func(a, b models.Quote) int {
+		switch {
+		case a.PhilosophyID == b.PhilosophyID:
+			return 0
+		case a.PhilosophyID == "":
+			return 1
+		case b.PhilosophyID == "":
+			return -1
+		}
+		return cmp.Compare(a.PhilosophyID, b.PhilosophyID)
+	})
+	for _, q := range quotes {
+		i := column(q.PhilosophyID, false)
+		p.Columns[i].Quotes = append(p.Columns[i].Quotes, q)
+	}
+	for i := range p.Columns[:listed] {
+		c := &p.Columns[i]
+		if c.Gap = len(c.Quotes) == 0; c.Gap {
+			p.Gaps = append(p.Gaps, c.PhilosophyID)
+		} else {
+			p.Covered++
+		}
+	}
+	return p, nil
+}
@@ -0,0 +1,69 @@
+package store_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"perennial-wisdom/models"
+	"perennial-wisdom/store"
+)
+
+func TestThemeParallelsFlagsGaps(t *testing.T) {
+	s := store.New()
+	ch := store.Change{Author: "ana"}
+	if err := s.CreateTheme(models.Theme{ID: "thaw", Name: "Thaw", PhilosophyIDs: []string{"taoist", "stoic", "sufi"}}, ch); err != nil {
+		t.Fatalf("CreateTheme: %v", err)
+	}
+	for _, q := range []models.Quote{
+		{ID: "par-1", Text: "Ice becomes water.", PhilosopherID: "laozi", PhilosophyID: "taoist", Status: store.StatusPublished},
+		{ID: "par-2", Text: "What melts was never yours.", PhilosopherID: "seneca", PhilosophyID: "stoic", Status: store.StatusPublished},
+		{ID: "par-3", Text: "The river forgets the ice.", PhilosopherID: "buddha", PhilosophyID: "buddhist", Status: store.StatusPublished},
+		{ID: "par-4", Text: "A Sufi draft.", PhilosopherID: "rumi", PhilosophyID: "sufi", Status: store.StatusDraft},
+		{ID: "par-5", Text: "Spring again.", PhilosopherID: "laozi", PhilosophyID: "taoist", Status: store.StatusPublished},
+		{ID: "par-6", Text: "No one knows who first said it.", Status: store.StatusPublished},
+	} {
+		q.ThemeIDs = []string{"thaw"}
+		if err := s.CreateQuote(q, ch); err != nil {
+			t.Fatalf("CreateQuote %s: %v", q.ID, err)
+		}
+	}
+
+	p, err := store.ThemeParallels(s, "thaw")
+	if err != nil {
+		t.Fatalf("ThemeParallels: %v", err)
+	}
+	type column struct {
+		id             string
+		listed, gap    bool
+		quotes         []string
+		namedTradition bool
+	}
+	var got []column
+	for _, c := range p.Columns {
+		col := column{id: c.PhilosophyID, listed: c.Listed, gap: c.Gap, namedTradition: c.Name != ""}
+		for _, q := range c.Quotes {
+			col.quotes = append(col.quotes, q.ID)
+		}
+		got = append(got, col)
+	}
+	want := []column{
+		{"taoist", true, false, []string{"par-1", "par-5"}, true},
+		{"stoic", true, false, []string{"par-2"}, true},
+		{"sufi", true, true, nil, true}, // the draft doesn't count
+		{"buddhist", false, false, []string{"par-3"}, true},
+		{"", false, false, []string{"par-6"}, true}, // labelled Unattributed
+	}
+	if !slices.EqualFunc(got, want, func(a, b column) bool {
+		return a.id == b.id && a.listed == b.listed && a.gap == b.gap && a.namedTradition == b.namedTradition && slices.Equal(a.quotes, b.quotes)
+	}) {
+		t.Errorf("columns:\n got %+v\nwant %+v", got, want)
+	}
+	if !slices.Equal(p.Gaps, []string{"sufi"}) || p.Covered != 2 {
+		t.Errorf("gaps %v, covered %d", p.Gaps, p.Covered)
+	}
+
+	if _, err := store.ThemeParallels(s, "nope"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("unknown theme: %v", err)
+	}
+}
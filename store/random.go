@@ -0,0 +1,172 @@
+package store
+
+import (
+	"cmp"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"perennial-wisdom/models"
+)
+
+// WeightKey is the quote Meta key editors weight a single quote with.
+const WeightKey = "weight"
+
+// Weights make some quotes come up at random more often than others,
+// keyed like graph nodes: "philosopher:epictetus", "philosophy:stoic"
+// (or "tradition:stoic"), "theme:death", "quote:e1". A quote's weight
+// is the product of every weight that applies to it and of its own
+// Meta weight; anything without one weighs 1. A weight of 0 keeps
+// quotes out of the draw altogether.
+type Weights map[string]float64
+
+// ParseWeights reads weights written as "kind:id=weight", separated by
+// commas, e.g. "philosopher:epictetus=3, theme:death=0.5".
+func ParseWeights(s string) (Weights, error) {
+	w := Weights{}
+	for _, item := range splitValues(s) {
+		key, value, ok := strings.Cut(item, "=")
+		kind, id, _ := strings.Cut(strings.TrimSpace(key), ":")
+		if kind == "tradition" {
+			kind = "philosophy"
+		}
+		if !ok || id == "" || !slices.Contains([]string{"philosopher", "philosophy", "theme", "quote"}, kind) {
+			return nil, fmt.Errorf("weight %q: want kind:id=weight, kind one of philosopher, philosophy, theme or quote", item)
+		}
+		f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || f < 0 || math.IsInf(f, 0) {
+			return nil, fmt.Errorf("weight %q: not a number of at least 0", item)
+		}
+		w[kind+":"+id] = f
+	}
+	return w, nil
+}
+
+// Of is q's weight.
+func (w Weights) Of(q models.Quote) float64 {
+	of := 1.0
+	if f, ok := w["quote:"+q.ID]; ok {
+		of *= f
+	}
+	if f, ok := w["philosopher:"+q.PhilosopherID]; ok {
+		of *= f
+	}
+	if f, ok := w["philosophy:"+q.PhilosophyID]; ok {
+		of *= f
+	}
+	for _, t := range q.ThemeIDs {
+		if f, ok := w["theme:"+t]; ok {
+			of *= f
+		}
+	}
+	if f, err := QuoteWeight(q); err == nil {
+		of *= f
+	}
+	return of
+}
+
+// QuoteWeight is the weight an editor gave q in its Meta, 1 if none. It
+// is an error for it not to be a number of at least 0.
+func QuoteWeight(q models.Quote) (float64, error) {
+	var f float64
+	switch v := q.Meta[WeightKey].(type) {
+	case nil:
+		return 1, nil
+	case float64:
+		f = v
+	case int:
+		f = float64(v)
+	case string:
+		var err error
+		if f, err = strconv.ParseFloat(strings.TrimSpace(v), 64); err != nil {
+			return 0, fmt.Errorf("%q is not a number", v)
+		}
+	default:
+		return 0, fmt.Errorf("%v is not a number", v)
+	}
+	if f < 0 || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, fmt.Errorf("%v is not a number of at least 0", f)
+	}
+	return f, nil
+}
+
+// Shuffle is a reproducible run of random quotes: the live quotes that
+// pass Filter, in an order drawn by weight from Seed. Every quote that
+// can come up does once before any comes up again, and the same seed
+// gives the same run, so a reader stepping through one never sees a
+// repeat until they have seen them all.
+type Shuffle struct {
+	Filter  Filter
+	Backed  bool // only quotes linked to evidence
+	Weights Weights
+	Seed    string
+}
+
+// Nth returns quote n of the run, counting from 0. ErrNotFound means
+// no quote can come up.
+//
+// Each pass through the quotes is a weighted shuffle without
+// replacement: every quote draws a key from a hash of the seed, the
+// pass and its ID, scaled by its weight, and the highest key goes
+// first. A quote's draw doesn't depend on the others, so a newly
+// published quote slots into a run without reordering it. A pass
+// doesn't begin with the quote the last one ended on.
+func (s Shuffle) Nth(repo Repository, n int) (models.Quote, error) {
+	quotes, _, err := repo.ListQuotes(s.Filter, Page{})
+	if err != nil {
+		return models.Quote{}, err
+	}
+	var pool []models.Quote
+	var weights []float64
+	for _, q := range quotes {
+		if w := s.Weights.Of(q); w > 0 && (!s.Backed || len(q.EvidenceIDs) > 0) {
+			pool = append(pool, q)
+			weights = append(weights, w)
+		}
+	}
+	m := len(pool)
+	if m == 0 {
+		return models.Quote{}, ErrNotFound
+	}
+	pass, pos := n/m, n%m
+	if m <= 2 {
+		// Two quotes can only take turns.
+		return s.pass(pool, weights, 0)[pos], nil
+	}
+	order := s.pass(pool, weights, pass)
+	if pass > 0 && order[0].ID == s.pass(pool, weights, pass-1)[m-1].ID {
+		order[0], order[1] = order[1], order[0]
+	}
+	return order[pos], nil
+}
+
+// pass is the weighted order of one pass through the pool, by the
+// Efraimidis–Spirakis method: the key of a quote of weight w is u^(1/w)
+// for u uniform in (0, 1), compared here as log(u)/w.
+func (s Shuffle) pass(pool []models.Quote, weights []float64, pass int) []models.Quote {
+	keys := make(map[string]float64, len(pool))
+	for i, q := range pool {
+		u := (float64(hash64(s.Seed, pass, q.ID)>>11) + 0.5) / (1 << 53)
+		keys[q.ID] = math.Log(u) / weights[i]
+	}
+	order := slices.Clone(pool)
+	slices.SortFunc(order, func(a, b models.Quote) int {
+		return cmp.Or(cmp.Compare(keys[b.ID], keys[a.ID]), strings.Compare(a.ID, b.ID))
+	})
+	return order
+}
+
+// hash64 hashes parts to 64 well-mixed bits: FNV-1a, then the SplitMix64
+// finalizer, as FNV alone barely stirs the high bits when only the last
+// byte differs.
+func hash64(parts ...any) uint64 {
+	h := fnv.New64a()
+	fmt.Fprintln(h, parts...)
+	x := h.Sum64()
+	x = (x ^ x>>30) * 0xbf58476d1ce4e5b9
+	x = (x ^ x>>27) * 0x94d049bb133111eb
+	return x ^ x>>31
+}
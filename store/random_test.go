@@ -0,0 +1,127 @@
+package store_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"perennial-wisdom/models"
+	"perennial-wisdom/store"
+)
+
+func TestParseWeights(t *testing.T) {
+	w, err := store.ParseWeights("philosopher:epictetus=3, tradition:stoic=1.5,theme:death=0")
+	if err != nil {
+		t.Fatalf("ParseWeights: %v", err)
+	}
+	if w["philosopher:epictetus"] != 3 || w["philosophy:stoic"] != 1.5 || w["theme:death"] != 0 || len(w) != 3 {
+		t.Errorf("weights: %v", w)
+	}
+	for _, bad := range []string{"epictetus=3", "planet:mars=2", "theme:death=-1", "theme:death=lots", "theme:death"} {
+		if _, err := store.ParseWeights(bad); err == nil {
+			t.Errorf("%q: expected an error", bad)
+		}
+	}
+	if w, err := store.ParseWeights(""); err != nil || len(w) != 0 {
+		t.Errorf("empty: %v, %v", w, err)
+	}
+}
+
+func TestWeightsOf(t *testing.T) {
+	w := store.Weights{"philosopher:epictetus": 3, "philosophy:stoic": 2, "theme:death": 0.5, "quote:e1": 10}
+	q := models.Quote{ID: "e2", PhilosopherID: "epictetus", PhilosophyID: "stoic", ThemeIDs: []string{"death", "control"}}
+	if got := w.Of(q); got != 3 {
+		t.Errorf("e2 weighs %v, want 3·2·0.5", got)
+	}
+	q.ID = "e1"
+	q.Meta = map[string]any{store.WeightKey: "0.1"}
+	if got := w.Of(q); got < 2.999 || got > 3.001 {
+		t.Errorf("e1 weighs %v, want 10·3·2·0.5·0.1", got)
+	}
+	if got := store.Weights(nil).Of(models.Quote{ID: "x"}); got != 1 {
+		t.Errorf("unweighted quote weighs %v", got)
+	}
+
+	s := store.New()
+	e1, _ := s.GetQuote("e1")
+	e1.Meta = map[string]any{store.WeightKey: -2.0}
+	var verr *store.ValidationError
+	if err := s.UpdateQuote(e1, store.Change{Author: "ana"}); !errors.As(err, &verr) || verr.Problems[0].Field != "meta.weight" {
+		t.Errorf("expected a negative weight to be refused, got %v", err)
+	}
+}
+
+func TestShuffleRunsThroughEveryQuote(t *testing.T) {
+	s := store.New()
+	sh := store.Shuffle{Filter: store.Where("philosophy", "stoic"), Weights: store.Weights{"philosopher:epictetus": 2}, Seed: "reader-1"}
+	stoic, _, _ := s.ListQuotes(sh.Filter, store.Page{})
+	m := len(stoic)
+
+	var run []string
+	for n := range 3 * m {
+		q, err := sh.Nth(s, n)
+		if err != nil {
+			t.Fatalf("Nth(%d): %v", n, err)
+		}
+		if q.PhilosophyID != "stoic" {
+			t.Errorf("%s is not stoic", q.ID)
+		}
+		if n > 0 && q.ID == run[n-1] {
+			t.Errorf("%s twice running at %d", q.ID, n)
+		}
+		run = append(run, q.ID)
+	}
+	for pass := range 3 {
+		seen := map[string]bool{}
+		for _, id := range run[pass*m : (pass+1)*m] {
+			if seen[id] {
+				t.Errorf("pass %d repeats %s", pass, id)
+			}
+			seen[id] = true
+		}
+	}
+
+	again, _ := sh.Nth(s, 4)
+	if again.ID != run[4] {
+		t.Errorf("seed not reproducible: %s then %s", run[4], again.ID)
+	}
+	sh.Seed = "reader-2"
+	differs := false
+	for n := range m {
+		if q, _ := sh.Nth(s, n); q.ID != run[n] {
+			differs = true
+		}
+	}
+	if !differs {
+		t.Error("two seeds gave the same run")
+	}
+}
+
+func TestShuffleFiltersAndWeights(t *testing.T) {
+	s := store.New()
+	backed := store.Shuffle{Backed: true, Seed: "x"}
+	for n := range 10 {
+		if q, err := backed.Nth(s, n); err != nil || len(q.EvidenceIDs) == 0 {
+			t.Errorf("evidence-backed pick %d: %+v, %v", n, q, err)
+		}
+	}
+
+	// Weight 0 keeps a quote out; with nothing left there is no pick.
+	none := store.Shuffle{Filter: store.Where("philosopher", "epictetus"), Weights: store.Weights{"philosopher:epictetus": 0}}
+	if _, err := none.Nth(s, 0); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	// Heavily weighted, e1 leads almost every run.
+	heavy := store.Shuffle{Weights: store.Weights{"quote:e1": 1000}}
+	first := 0
+	for i := range 200 {
+		heavy.Seed = fmt.Sprint(i)
+		if q, _ := heavy.Nth(s, 0); q.ID == "e1" {
+			first++
+		}
+	}
+	if first < 180 {
+		t.Errorf("e1 first in %d of 200 runs", first)
+	}
+}
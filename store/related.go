@@ -0,0 +1,172 @@
+package store
+
+import (
+	"math"
+	"slices"
+
+	"perennial-wisdom/models"
+)
+
+// How Related weighs what two quotes share: text and themes make up a
+// score between 0 and 1.
+const (
+	textWeight  = 0.6
+	themeWeight = 0.4
+
+	// traditionDecay discounts a quote for each one already chosen from
+	// its tradition, counting the quote's own, so the same truth found
+	// again elsewhere outranks a neighbour saying it in the same school.
+	traditionDecay = 0.5
+
+	// RelatedLimit is how many related quotes are shown by default.
+	RelatedLimit = 5
+)
+
+// RelatedQuote is a quote that resonates with another, and why.
+type RelatedQuote struct {
+	Quote          models.Quote `json:"quote"`
+	Score          float64      `json:"score"`
+	Similarity     float64      `json:"similarity"`      // cosine of the TF-IDF vectors, 0 to 1
+	SharedThemes   []string     `json:"shared_themes"`   // theme IDs both quotes carry
+	CrossTradition bool         `json:"cross_tradition"` // from a tradition other than the quote's
+}
+
+// Related finds the live quotes that resonate most with the quote id
+// (or slug), best first, at most limit of them. Resonance is the cosine
+// similarity of TF-IDF vectors over text, title and expositions —
+// weighted, folded and stemmed as the search Index does — plus the
+// Jaccard similarity of ThemeIDs. Quotes sharing neither a word nor a
+// theme are not related at all. Quotes are then chosen best first, each
+// discounted by traditionDecay for every quote already chosen from its
+// tradition, so the list spreads across traditions.
+//
+// Everything is computed here from the live quotes, read whole on each
+// call: no index to keep up to date and no embedding service to call.
+// A quote that isn't live is ErrNotFound.
+func Related(repo Repository, id string, limit int) ([]RelatedQuote, error) {
+	q, err := repo.GetQuote(id)
+	if err == nil && !Live(q) {
+		err = ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	quotes, _, err := repo.ListQuotes(nil, Page{})
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = RelatedLimit
+	}
+
+	if !slices.ContainsFunc(quotes, func(o models.Quote) bool { return o.ID == q.ID }) {
+		quotes = append(quotes, q)
+	}
+	vectors := tfidf(quotes)
+	self := vectors[q.ID]
+	var candidates []RelatedQuote
+	for _, other := range quotes {
+		if other.ID == q.ID {
+			continue
+		}
+		r := RelatedQuote{
+			Quote:          other,
+			Similarity:     round6(cosine(self, vectors[other.ID])),
+			SharedThemes:   sharedThemes(q.ThemeIDs, other.ThemeIDs),
+			CrossTradition: other.PhilosophyID != q.PhilosophyID,
+		}
+		themes := 0.0
+		if n := len(union(q.ThemeIDs, other.ThemeIDs)); n > 0 {
+			themes = float64(len(r.SharedThemes)) / float64(n)
+		}
+		if r.Score = textWeight*r.Similarity + themeWeight*themes; r.Score > 0 {
+			candidates = append(candidates, r)
+		}
+	}
+
+	related := []RelatedQuote{}
+	chosen := map[string]int{q.PhilosophyID: 1}
+	for len(related) < limit && len(candidates) > 0 {
+		best, bestScore := 0, -1.0
+		for i, r := range candidates {
+			score := round6(r.Score * math.Pow(traditionDecay, float64(chosen[r.Quote.PhilosophyID])))
+			if score > bestScore || score == bestScore && r.Quote.ID < candidates[best].Quote.ID {
+				best, bestScore = i, score
+			}
+		}
+		r := candidates[best]
+		r.Score = bestScore
+		related = append(related, r)
+		chosen[r.Quote.PhilosophyID]++
+		candidates = slices.Delete(candidates, best, best+1)
+	}
+	return related, nil
+}
+
+// tfidf is each quote's unit-length TF-IDF vector, by quote ID: the log
+// of its weighted stem frequencies times the smoothed inverse document
+// frequency of each stem among quotes.
+func tfidf(quotes []models.Quote) map[string]map[string]float64 {
+	freqs := make(map[string]map[string]float64, len(quotes))
+	df := map[string]int{}
+	for _, q := range quotes {
+		tf, _ := stemFrequencies(quoteFields(q))
+		freqs[q.ID] = tf
+		for s := range tf {
+			df[s]++
+		}
+	}
+	n := float64(len(quotes))
+	vectors := make(map[string]map[string]float64, len(quotes))
+	for id, tf := range freqs {
+		v, norm := make(map[string]float64, len(tf)), 0.0
+		for s, f := range tf {
+			w := (1 + math.Log(1+f)) * (1 + math.Log((1+n)/(1+float64(df[s]))))
+			v[s] = w
+			norm += w * w
+		}
+		if norm = math.Sqrt(norm); norm > 0 {
+			for s := range v {
+				v[s] /= norm
+			}
+		}
+		vectors[id] = v
+	}
+	return vectors
+}
+
+// cosine is the dot product of two unit vectors.
+func cosine(a, b map[string]float64) float64 {
+	if len(b) < len(a) {
+		a, b = b, a
+	}
+	dot := 0.0
+	for s, w := range a {
+		dot += w * b[s]
+	}
+	return min(dot, 1)
+}
+
+// sharedThemes are the IDs in both a and b, in a's order.
+func sharedThemes(a, b []string) []string {
+	shared := []string{}
+	for _, id := range a {
+		if slices.Contains(b, id) && !slices.Contains(shared, id) {
+			shared = append(shared, id)
+		}
+	}
+	return shared
+}
+
+// union is the distinct IDs in a or b.
+func union(a, b []string) map[string]bool {
+	set := map[string]bool{}
+	for _, id := range append(slices.Clone(a), b...) {
+		set[id] = true
+	}
+	return set
+}
+
+// round6 rounds to six decimal places, so scores compare the same on
+// every backend and machine.
+func round6(x float64) float64 { return math.Round(x*1e6) / 1e6 }
@@ -0,0 +1,75 @@
+package store_test
+
+import (
+	"errors"
+	"testing"
+
+	"perennial-wisdom/models"
+	"perennial-wisdom/store"
+)
+
+func TestRelatedFavorsOtherTraditions(t *testing.T) {
+	s := store.New()
+	for _, q := range []models.Quote{
+		{ID: "rel-1", Text: "The quillwort mind unravels; stillness gathers the quillwort.", PhilosopherID: "seneca", PhilosophyID: "stoic"},
+		{ID: "rel-2", Text: "Quillwort mind, quillwort stillness.", PhilosopherID: "epictetus", PhilosophyID: "stoic"},
+		{ID: "rel-3", Text: "The quillwort mind at rest.", PhilosopherID: "buddha", PhilosophyID: "buddhist"},
+		{ID: "rel-4", Text: "A quillwort draft.", PhilosopherID: "laozi", PhilosophyID: "taoist", Status: store.StatusDraft},
+		{ID: "rel-5", Text: "Quillwort stillness, quillwort mind.", PhilosopherID: "marcus-aurelius", PhilosophyID: "stoic", Status: store.StatusDraft},
+	} {
+		if q.Status == "" {
+			q.Status = store.StatusPublished
+		}
+		if err := s.CreateQuote(q, store.Change{Author: "ana"}); err != nil {
+			t.Fatalf("CreateQuote %s: %v", q.ID, err)
+		}
+	}
+
+	related, err := store.Related(s, "rel-1", 2)
+	if err != nil {
+		t.Fatalf("Related: %v", err)
+	}
+	if len(related) != 2 {
+		t.Fatalf("expected 2 related quotes, got %+v", related)
+	}
+	// rel-2 says more of the same, but in rel-1's own tradition.
+	if related[0].Quote.ID != "rel-3" || !related[0].CrossTradition || related[1].Quote.ID != "rel-2" || related[1].CrossTradition {
+		t.Errorf("order: %s, %s", related[0].Quote.ID, related[1].Quote.ID)
+	}
+	if related[1].Similarity <= related[0].Similarity || related[0].Score <= related[1].Score {
+		t.Errorf("scores: %+v", related)
+	}
+
+	all, _ := store.Related(s, "rel-1", store.MaxLimit)
+	for _, r := range all {
+		if r.Quote.ID == "rel-1" || !store.Live(r.Quote) {
+			t.Errorf("related must be other, live quotes: %s", r.Quote.ID)
+		}
+		if r.Score <= 0 {
+			t.Errorf("%s: score %v", r.Quote.ID, r.Score)
+		}
+	}
+	if _, err := store.Related(s, "rel-4", 5); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("a draft has no related quotes: %v", err)
+	}
+}
+
+func TestRelatedByThemes(t *testing.T) {
+	related, err := store.Related(store.New(), "e1", store.RelatedLimit)
+	if err != nil {
+		t.Fatalf("Related: %v", err)
+	}
+	if len(related) != store.RelatedLimit {
+		t.Fatalf("expected %d related quotes, got %d", store.RelatedLimit, len(related))
+	}
+	traditions := map[string]bool{}
+	for _, r := range related {
+		if r.Similarity == 0 && len(r.SharedThemes) == 0 {
+			t.Errorf("%s shares nothing with e1", r.Quote.ID)
+		}
+		traditions[r.Quote.PhilosophyID] = true
+	}
+	if len(traditions) < 3 {
+		t.Errorf("expected quotes from several traditions, got %v", traditions)
+	}
+}
@@ -0,0 +1,290 @@
+package store
+
+import (
+	"errors"
+	"math/rand/v2"
+	"sort"
+	"time"
+
+	"perennial-wisdom/models"
+)
+
+// ErrNotFound is returned by Repository lookups for unknown IDs.
+var ErrNotFound = errors.New("not found")
+
+// Repository is the single read contract for the corpus.
+// The JSON API and the HTML pages both go through it, so they can never
+// disagree. Two implementations: *Store (in-memory seeds) and
+// db.Repository (SQL).
+//
+// Lists are ordered by ID — schools and themes by name — and paginated
+// by Page; pass the zero Page to get everything.
+type Repository interface {
+	ListQuotes(f Filter, p Page) ([]models.Quote, Cursors, error)
+	GetQuote(idOrSlug string) (models.Quote, error)
+	RandomQuote() (models.Quote, error)
+
+	ListPhilosophers(f Filter, p Page) ([]models.Philosopher, Cursors, error)
+	GetPhilosopher(id string) (models.Philosopher, error)
+
+	ListPhilosophies(p Page) ([]models.Philosophy, Cursors, error)
+	GetPhilosophy(id string) (models.Philosophy, error)
+
+	ListThemes(p Page) ([]models.Theme, Cursors, error)
+	GetTheme(id string) (models.Theme, error)
+
+	ListEvidence(f Filter, p Page) ([]models.Evidence, Cursors, error)
+	GetEvidence(id string) (models.Evidence, error)
+
+	// SearchQuotes ranks live quotes against free text (see SearchQuery).
+	SearchQuotes(s SearchQuery) (SearchResults, error)
+
+	// QuoteStamps says when each quote, of any status, went public and
+	// when it last changed.
+	QuoteStamps() (map[string]Stamp, error)
+}
+
+// Stamp dates a quote. Published is when it went public, or will: when
+// it last moved to published, or its PublishAt if that is later. It is
+// zero for a quote that has never been published. Updated is its last
+// write.
+type Stamp struct {
+	Published time.Time
+	Updated   time.Time
+}
+
+// Filter dimensions understood by each list query. "tradition" is an
+// alias for "philosophy" — the SQL schema's name for a school.
+var (
+	QuoteDimensions       = []string{"philosopher", "philosophy", "tradition", "theme", "evidence"}
+	PhilosopherDimensions = []string{"philosophy", "tradition"}
+	EvidenceDimensions    = []string{"field", "theme"}
+)
+
+// QuoteValues returns a quote's values for a filter dimension.
+func QuoteValues(q models.Quote) func(dim string) []string {
+	return func(dim string) []string {
+		switch dim {
+		case "philosopher":
+			return []string{q.PhilosopherID}
+		case "philosophy", "tradition":
+			return []string{q.PhilosophyID}
+		case "theme":
+			return q.ThemeIDs
+		case "evidence":
+			return q.EvidenceIDs
+		case "status":
+			return []string{EffectiveStatus(q, time.Now())}
+		}
+		return nil
+	}
+}
+
+// PhilosopherValues returns a philosopher's values for a filter dimension.
+func PhilosopherValues(p models.Philosopher) func(dim string) []string {
+	return func(dim string) []string {
+		switch dim {
+		case "philosophy", "tradition":
+			return []string{p.PhilosophyID}
+		}
+		return nil
+	}
+}
+
+// EvidenceValues returns an evidence entry's values for a filter dimension.
+func EvidenceValues(e models.Evidence) func(dim string) []string {
+	return func(dim string) []string {
+		switch dim {
+		case "field":
+			return []string{e.Field}
+		case "theme":
+			return e.ThemeIDs
+		}
+		return nil
+	}
+}
+
+// --- In-memory implementation ---
+
+var _ Repository = (*Store)(nil)
+
+// ListQuotes returns one page of quotes matching f, ordered by ID.
+// Without a status condition only live quotes match.
+func (s *Store) ListQuotes(f Filter, p Page) ([]models.Quote, Cursors, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	f = WithStatus(f)
+	var results []models.Quote
+	for _, id := range sortedKeys(s.Quotes) {
+		if q := s.Quotes[id]; f.Match(QuoteValues(q)) {
+			results = append(results, q)
+		}
+	}
+	results, c := Window(p, results, func(q models.Quote) string { return q.ID })
+	return results, c, nil
+}
+
+// GetQuote returns a quote by ID or slug.
+func (s *Store) GetQuote(idOrSlug string) (models.Quote, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if q, ok := s.Quotes[idOrSlug]; ok {
+		return q, nil
+	}
+	for _, q := range s.Quotes {
+		if q.Slug != "" && q.Slug == idOrSlug {
+			return q, nil
+		}
+	}
+	return models.Quote{}, ErrNotFound
+}
+
+// RandomQuote returns a uniformly random live quote.
+func (s *Store) RandomQuote() (models.Quote, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var ids []string
+	for _, id := range sortedKeys(s.Quotes) {
+		if Live(s.Quotes[id]) {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return models.Quote{}, ErrNotFound
+	}
+	return s.Quotes[ids[rand.IntN(len(ids))]], nil
+}
+
+// QuoteStamps says when each quote went public and last changed. The
+// seed quotes date from when the store was made.
+func (s *Store) QuoteStamps() (map[string]Stamp, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	stamps := make(map[string]Stamp, len(s.Quotes))
+	for id, q := range s.Quotes {
+		st := s.stamps[id]
+		if q.PublishAt != nil && q.PublishAt.After(st.Published) {
+			st.Published = *q.PublishAt
+		}
+		stamps[id] = st
+	}
+	return stamps, nil
+}
+
+// ListPhilosophers returns one page of philosophers matching f, ordered by ID.
+func (s *Store) ListPhilosophers(f Filter, p Page) ([]models.Philosopher, Cursors, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var results []models.Philosopher
+	for _, id := range sortedKeys(s.Philosophers) {
+		if ph := s.Philosophers[id]; f.Match(PhilosopherValues(ph)) {
+			results = append(results, ph)
+		}
+	}
+	results, c := Window(p, results, func(ph models.Philosopher) string { return ph.ID })
+	return results, c, nil
+}
+
+// GetPhilosopher returns a philosopher by ID.
+func (s *Store) GetPhilosopher(id string) (models.Philosopher, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	p, ok := s.Philosophers[id]
+	if !ok {
+		return models.Philosopher{}, ErrNotFound
+	}
+	return p, nil
+}
+
+// ListPhilosophies returns one page of schools, ordered by name.
+func (s *Store) ListPhilosophies(p Page) ([]models.Philosophy, Cursors, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	key := func(ph models.Philosophy) string { return NameKey(ph.Name, ph.ID) }
+	results := make([]models.Philosophy, 0, len(s.Philosophies))
+	for _, ph := range s.Philosophies {
+		results = append(results, ph)
+	}
+	sort.Slice(results, func(i, j int) bool { return key(results[i]) < key(results[j]) })
+	results, c := Window(p, results, key)
+	return results, c, nil
+}
+
+// GetPhilosophy returns a school by ID.
+func (s *Store) GetPhilosophy(id string) (models.Philosophy, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	p, ok := s.Philosophies[id]
+	if !ok {
+		return models.Philosophy{}, ErrNotFound
+	}
+	return p, nil
+}
+
+// ListThemes returns one page of themes, ordered by name.
+func (s *Store) ListThemes(p Page) ([]models.Theme, Cursors, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	key := func(t models.Theme) string { return NameKey(t.Name, t.ID) }
+	results := make([]models.Theme, 0, len(s.Themes))
+	for _, t := range s.Themes {
+		results = append(results, t)
+	}
+	sort.Slice(results, func(i, j int) bool { return key(results[i]) < key(results[j]) })
+	results, c := Window(p, results, key)
+	return results, c, nil
+}
+
+// GetTheme returns a theme by ID.
+func (s *Store) GetTheme(id string) (models.Theme, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	t, ok := s.Themes[id]
+	if !ok {
+		return models.Theme{}, ErrNotFound
+	}
+	return t, nil
+}
+
+// ListEvidence returns one page of evidence matching f, ordered by ID.
+func (s *Store) ListEvidence(f Filter, p Page) ([]models.Evidence, Cursors, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var results []models.Evidence
+	for _, id := range sortedKeys(s.Evidence) {
+		if e := s.Evidence[id]; f.Match(EvidenceValues(e)) {
+			results = append(results, e)
+		}
+	}
+	results, c := Window(p, results, func(e models.Evidence) string { return e.ID })
+	return results, c, nil
+}
+
+// GetEvidence returns an evidence entry by ID.
+func (s *Store) GetEvidence(id string) (models.Evidence, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	e, ok := s.Evidence[id]
+	if !ok {
+		return models.Evidence{}, ErrNotFound
+	}
+	return e, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func contains(slice []string, item string) bool {
+	for _, s := range slice {
+		if s == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,290 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"time"
+
+	"perennial-wisdom/models"
+)
+
+// Change says who made a write and why. It is recorded on the revision
+// the write creates.
+type Change struct {
+	Author string
+	Note   string
+}
+
+// History is the revision log Writer keeps. Kinds are "quote",
+// "philosopher", "philosophy", "theme" and "evidence".
+//
+// Entities that existed before any revision was recorded (seeded ones)
+// get a "baseline" revision of their prior state on their first write,
+// so every change can be diffed and undone.
+type History interface {
+	// ListRevisions returns an entity's revisions, newest first.
+	ListRevisions(kind, id string) ([]models.Revision, error)
+	// GetRevision returns one revision, or ErrNotFound.
+	GetRevision(kind, id string, number int) (models.Revision, error)
+}
+
+// Cascaded describes the edit a delete of kind/id makes to each entity
+// that referred to it, for that entity's history: the same author, and a
+// note naming what was deleted.
+func Cascaded(ch Change, kind, id string) Change {
+	note := fmt.Sprintf("%s %q deleted", kind, id)
+	if ch.Note != "" {
+		note += ": " + ch.Note
+	}
+	return Change{Author: ch.Author, Note: note}
+}
+
+// FieldChange is one field that differs between two snapshots.
+// A nil side means the field was absent.
+type FieldChange struct {
+	Field  string `json:"field"`
+	Before any    `json:"before"`
+	After  any    `json:"after"`
+}
+
+// Diff compares two entity snapshots field by field, in field name
+// order. Either side may be empty: before a create or after a delete.
+func Diff(before, after json.RawMessage) []FieldChange {
+	var a, b map[string]any
+	if len(before) > 0 {
+		json.Unmarshal(before, &a)
+	}
+	if len(after) > 0 {
+		json.Unmarshal(after, &b)
+	}
+	var fields []string
+	for f := range a {
+		fields = append(fields, f)
+	}
+	for f := range b {
+		if _, ok := a[f]; !ok {
+			fields = append(fields, f)
+		}
+	}
+	slices.Sort(fields)
+
+	var changes []FieldChange
+	for _, f := range fields {
+		if !reflect.DeepEqual(a[f], b[f]) {
+			changes = append(changes, FieldChange{Field: f, Before: a[f], After: b[f]})
+		}
+	}
+	return changes
+}
+
+// DiffRevision compares a revision with the one before it.
+func DiffRevision(h History, rev models.Revision) ([]FieldChange, error) {
+	var before json.RawMessage
+	if rev.Number > 1 {
+		prev, err := h.GetRevision(rev.Kind, rev.EntityID, rev.Number-1)
+		if err != nil {
+			return nil, err
+		}
+		before = prev.Data
+	}
+	return Diff(before, rev.Data), nil
+}
+
+// Rollback restores an entity to the state recorded in revision number.
+// The rollback is itself a write, so history only ever grows. Rolling
+// back to a delete deletes the entity; rolling back a deleted entity
+// recreates it. References the old state makes to entities that no
+// longer exist fail validation like any other write.
+//
+// Recreating an entity doesn't restore the references its delete
+// cleared. Those edits are in the referring entities' own histories,
+// so rolling each of them back to the revision before restores them.
+func Rollback(rw ReadWriter, kind, id string, number int, ch Change) error {
+	rev, err := rw.GetRevision(kind, id, number)
+	if err != nil {
+		return err
+	}
+	note := fmt.Sprintf("roll back to revision %d", number)
+	if ch.Note != "" {
+		note += ": " + ch.Note
+	}
+	ch.Note = note
+
+	if len(rev.Data) == 0 {
+		err := deleteKind(rw, kind, id, ch)
+		if errors.Is(err, ErrNotFound) {
+			return nil // already gone
+		}
+		return err
+	}
+	switch kind {
+	case "quote":
+		// Only content rolls back: status stays where the workflow put
+		// it, and a recreated quote goes back through review.
+		update := func(q models.Quote, ch Change) error {
+			cur, err := rw.GetQuote(id)
+			if err != nil {
+				return err
+			}
+			q.Status, q.PublishAt = cur.Status, cur.PublishAt
+			return rw.UpdateQuote(q, ch)
+		}
+		create := func(q models.Quote, ch Change) error {
+			q.Status, q.PublishAt = StatusDraft, nil
+			return rw.CreateQuote(q, ch)
+		}
+		return restore(rev.Data, ch, update, create)
+	case "philosopher":
+		return restore(rev.Data, ch, rw.UpdatePhilosopher, rw.CreatePhilosopher)
+	case "philosophy":
+		return restore(rev.Data, ch, rw.UpdatePhilosophy, rw.CreatePhilosophy)
+	case "theme":
+		return restore(rev.Data, ch, rw.UpdateTheme, rw.CreateTheme)
+	case "evidence":
+		return restore(rev.Data, ch, rw.UpdateEvidence, rw.CreateEvidence)
+	}
+	return ErrNotFound
+}
+
+func restore[T any](data json.RawMessage, ch Change, update, create func(T, Change) error) error {
+	var v T
+	if err := json.Unmarshal(data, &v); err != nil {
+		return fmt.Errorf("decode revision: %w", err)
+	}
+	err := update(v, ch)
+	if errors.Is(err, ErrNotFound) {
+		err = create(v, ch)
+	}
+	return err
+}
+
+func deleteKind(w Writer, kind, id string, ch Change) error {
+	switch kind {
+	case "quote":
+		return w.DeleteQuote(id, ch)
+	case "philosopher":
+		return w.DeletePhilosopher(id, ch)
+	case "philosophy":
+		return w.DeletePhilosophy(id, ch)
+	case "theme":
+		return w.DeleteTheme(id, ch)
+	case "evidence":
+		return w.DeleteEvidence(id, ch)
+	}
+	return ErrNotFound
+}
+
+// Baseline describes baseline revisions.
+var Baseline = Change{Author: "system", Note: "state before revision history"}
+
+// --- In-memory implementation ---
+
+// revised runs write and, if it succeeds, records its revision (after a
+// baseline, for an entity without history). Callers hold s.mu.
+func (s *Store) revised(kind, id, action string, ch Change, write func() error) error {
+	key := kind + "/" + id
+	var base any
+	if action != "create" && len(s.revisions[key]) == 0 {
+		base = s.lookup(kind, id)
+	}
+	if err := write(); err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	if base != nil {
+		s.record(kind, id, "baseline", Baseline, base, now)
+	}
+	s.record(kind, id, action, ch, s.lookup(kind, id), now)
+	s.reindex(kind, id)
+	return nil
+}
+
+// reindex brings the search index up to date with a write. Deleting
+// anything but a quote edits the quotes that referred to it, so that
+// rebuilds the index. Callers hold s.mu.
+func (s *Store) reindex(kind, id string) {
+	switch {
+	case s.index == nil:
+	case kind != "quote":
+		if s.lookup(kind, id) == nil {
+			s.indexAll()
+		}
+	default:
+		if q, ok := s.Quotes[id]; ok {
+			s.index.Put(q)
+		} else {
+			s.index.Remove(id)
+		}
+	}
+}
+
+// indexAll (re)builds the search index from every quote. Callers hold s.mu.
+func (s *Store) indexAll() {
+	quotes := slices.Collect(maps.Values(s.Quotes))
+	if s.index == nil {
+		s.index = NewIndex(quotes)
+		return
+	}
+	s.index.Reset(quotes)
+}
+
+func (s *Store) record(kind, id, action string, ch Change, v any, at time.Time) {
+	if s.revisions == nil {
+		s.revisions = make(map[string][]models.Revision)
+	}
+	key := kind + "/" + id
+	rev := models.Revision{
+		Kind: kind, EntityID: id, Number: len(s.revisions[key]) + 1, Action: action,
+		Author: ch.Author, Note: ch.Note, CreatedAt: at,
+	}
+	if v != nil {
+		rev.Data, _ = json.Marshal(v)
+	}
+	s.revisions[key] = append(s.revisions[key], rev)
+}
+
+// lookup returns the stored entity, or nil.
+func (s *Store) lookup(kind, id string) any {
+	var v any
+	var ok bool
+	switch kind {
+	case "quote":
+		v, ok = s.Quotes[id]
+	case "philosopher":
+		v, ok = s.Philosophers[id]
+	case "philosophy":
+		v, ok = s.Philosophies[id]
+	case "theme":
+		v, ok = s.Themes[id]
+	case "evidence":
+		v, ok = s.Evidence[id]
+	}
+	if !ok {
+		return nil
+	}
+	return v
+}
+
+// ListRevisions returns an entity's revisions, newest first.
+func (s *Store) ListRevisions(kind, id string) ([]models.Revision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	revs := slices.Clone(s.revisions[kind+"/"+id])
+	slices.Reverse(revs)
+	return revs, nil
+}
+
+// GetRevision returns one revision.
+func (s *Store) GetRevision(kind, id string, number int) (models.Revision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	revs := s.revisions[kind+"/"+id]
+	if number < 1 || number > len(revs) {
+		return models.Revision{}, ErrNotFound
+	}
+	return revs[number-1], nil
+}
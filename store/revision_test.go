@@ -0,0 +1,80 @@
+package store_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"perennial-wisdom/store"
+)
+
+func TestDiff(t *testing.T) {
+	before := json.RawMessage(`{"id":"q","text":"old","theme_ids":["a","b"],"source":"S"}`)
+	after := json.RawMessage(`{"id":"q","text":"new","theme_ids":["a","b"],"meta":{"k":1}}`)
+
+	got := store.Diff(before, after)
+	want := []store.FieldChange{
+		{Field: "meta", Before: nil, After: map[string]any{"k": float64(1)}},
+		{Field: "source", Before: "S", After: nil},
+		{Field: "text", Before: "old", After: "new"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff = %#v, want %#v", got, want)
+	}
+	if n := len(store.Diff(nil, after)); n != 4 {
+		t.Errorf("diff from nothing should list every field, got %d", n)
+	}
+}
+
+func TestStoreRevisionsAndRollback(t *testing.T) {
+	s := store.New()
+	original, _ := s.GetQuote("e1")
+
+	q := original
+	q.Source = "Enchiridion 5"
+	if err := s.UpdateQuote(q, store.Change{Author: "ana", Note: "fix source"}); err != nil {
+		t.Fatalf("UpdateQuote: %v", err)
+	}
+
+	revs, _ := s.ListRevisions("quote", "e1")
+	if len(revs) != 2 || revs[1].Action != "baseline" || revs[0].Action != "update" || revs[0].Number != 2 {
+		t.Fatalf("expected baseline then update, got %+v", revs)
+	}
+	if revs[0].Author != "ana" || revs[0].Note != "fix source" || revs[0].CreatedAt.IsZero() {
+		t.Errorf("revision should record author, note and time: %+v", revs[0])
+	}
+	changes, err := store.DiffRevision(s, revs[0])
+	if err != nil || len(changes) != 1 || changes[0].Field != "source" || changes[0].After != "Enchiridion 5" {
+		t.Errorf("expected one source change, got %+v (%v)", changes, err)
+	}
+
+	if err := store.Rollback(s, "quote", "e1", 1, store.Change{Author: "ben"}); err != nil {
+		t.Fatalf("Rollback: %v", err)
+	}
+	if got, _ := s.GetQuote("e1"); got.Source != original.Source {
+		t.Errorf("rollback should restore the source, got %q", got.Source)
+	}
+	revs, _ = s.ListRevisions("quote", "e1")
+	if len(revs) != 3 || revs[0].Note != "roll back to revision 1" {
+		t.Errorf("rollback should be recorded as a new revision, got %+v", revs[0])
+	}
+
+	// Deleted entities keep their history and can be brought back.
+	if err := s.DeleteQuote("e1", store.Change{Author: "ana"}); err != nil {
+		t.Fatalf("DeleteQuote: %v", err)
+	}
+	revs, _ = s.ListRevisions("quote", "e1")
+	if revs[0].Action != "delete" || len(revs[0].Data) != 0 {
+		t.Errorf("delete revision should have no snapshot: %+v", revs[0])
+	}
+	if err := store.Rollback(s, "quote", "e1", 3, store.Change{Author: "ana"}); err != nil {
+		t.Fatalf("Rollback of deleted quote: %v", err)
+	}
+	if _, err := s.GetQuote("e1"); err != nil {
+		t.Errorf("rollback should recreate the quote: %v", err)
+	}
+	if err := store.Rollback(s, "quote", "e1", 99, store.Change{}); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("unknown revision: expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,259 @@
+package store
+
+import (
+	"cmp"
+	"errors"
+	"html"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"perennial-wisdom/models"
+)
+
+// SearchQuery is a ranked full-text search over live quotes.
+type SearchQuery struct {
+	Text   string
+	Filter Filter // narrows matches (and facets) by QuoteDimensions
+	Limit  int    // hits per page; 0 means DefaultLimit
+	Offset int    // hits to skip — results are ranked, not keyed by ID
+}
+
+// SearchResults is one page of ranked hits plus counts over every match.
+type SearchResults struct {
+	Total  int                `json:"total"`
+	Hits   []SearchHit        `json:"hits"`
+	Facets map[string][]Facet `json:"facets"` // "tradition", "theme"
+
+	// Names are the philosophers, traditions and themes the text names,
+	// allowing for typos, diacritics and other spellings.
+	Names []NameMatch `json:"names"`
+	// DidYouMean is a corrected query, offered when nothing matched.
+	DidYouMean string `json:"did_you_mean,omitempty"`
+}
+
+// SearchHit is a matching quote, how well it matched, and where.
+type SearchHit struct {
+	Quote models.Quote `json:"quote"`
+	Rank  float64      `json:"rank"`
+
+	// Snippet is HTML: the matched passage, escaped, with each match
+	// wrapped in <mark>.
+	Snippet string `json:"snippet"`
+}
+
+// Facet counts the matches carrying one value of a dimension.
+type Facet struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// Snippet markers. Backends delimit matches with these, and SnippetHTML
+// turns them into <mark> once the text around them is escaped.
+const (
+	MarkStart = "\x02"
+	MarkEnd   = "\x03"
+)
+
+// ParseSearch reads ?q=, ?limit=, ?offset= and the quote filter
+// dimensions from URL query parameters.
+func ParseSearch(q url.Values) (SearchQuery, error) {
+	s := SearchQuery{
+		Text:   strings.TrimSpace(q.Get("q")),
+		Filter: ParseFilter(q, QuoteDimensions...),
+		Limit:  DefaultLimit,
+	}
+	if raw := q.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			return SearchQuery{}, errors.New("limit must be a positive integer")
+		}
+		s.Limit = min(n, MaxLimit)
+	}
+	if raw := q.Get("offset"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			return SearchQuery{}, errors.New("offset must be a non-negative integer")
+		}
+		s.Offset = n
+	}
+	return s, nil
+}
+
+// Window is the page a query asks for, with defaults applied.
+func (s SearchQuery) Window() (limit, offset int) {
+	limit = s.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	return min(limit, MaxLimit), max(s.Offset, 0)
+}
+
+// SearchTerms splits search text into the lowercase terms SQL LIKE
+// matching looks for.
+func SearchTerms(text string) []string {
+	return strings.Fields(strings.ToLower(text))
+}
+
+// field is one searchable text and its weight — the 1.0/0.4/0.2
+// ts_rank gives PostgreSQL's A/B/C labels.
+type field struct {
+	text   string
+	weight float64
+}
+
+// quoteFields are q's searchable fields, in the order snippets prefer them.
+func quoteFields(q models.Quote) []field {
+	return []field{
+		{q.Text, 1.0},
+		{q.Title, 1.0},
+		{q.ExpositionBrief, 0.4},
+		{q.ExpositionStandard, 0.2},
+		{q.ModernReinterpretation, 0.2},
+	}
+}
+
+// snippetChars bounds a snippet cut from a long field.
+const snippetChars = 240
+
+// snippet is the first field with a match, cut down around the first
+// match and marked up; the first field when nothing matches. find
+// returns the [start, end) byte ranges of the matches in a text.
+func snippet(fields []field, find func(string) [][]int) string {
+	text, found := fields[0].text, [][]int(nil)
+	for _, f := range fields {
+		if m := find(f.text); len(m) > 0 {
+			text, found = f.text, m
+			break
+		}
+	}
+
+	start, end := 0, len(text)
+	prefix, suffix := "", ""
+	if len(text) > snippetChars {
+		if len(found) > 0 && found[0][0] > snippetChars/3 {
+			at := found[0][0]
+			start = runeStart(text, at-snippetChars/3)
+			if i := strings.IndexByte(text[start:at], ' '); i >= 0 {
+				start += i + 1
+			}
+			prefix = "…"
+		}
+		end = runeStart(text, min(start+snippetChars, len(text)))
+		if end < len(text) {
+			if i := strings.LastIndexByte(text[start:end], ' '); i > 0 {
+				end = start + i
+			}
+			suffix = "…"
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(prefix)
+	at := start
+	for _, m := range found {
+		if m[0] < start || m[1] > end {
+			continue
+		}
+		b.WriteString(text[at:m[0]])
+		b.WriteString(MarkStart + text[m[0]:m[1]] + MarkEnd)
+		at = m[1]
+	}
+	b.WriteString(text[at:end])
+	b.WriteString(suffix)
+	return SnippetHTML(b.String())
+}
+
+// runeStart backs i up to the start of the UTF-8 sequence it falls in.
+func runeStart(s string, i int) int {
+	for i > 0 && i < len(s) && !utf8.RuneStart(s[i]) {
+		i--
+	}
+	return i
+}
+
+// SnippetHTML escapes a snippet delimited with MarkStart/MarkEnd and
+// marks the matches up with <mark>.
+func SnippetHTML(raw string) string {
+	s := html.EscapeString(raw)
+	s = strings.ReplaceAll(s, MarkStart, "<mark>")
+	return strings.ReplaceAll(s, MarkEnd, "</mark>")
+}
+
+// PageHits cuts one page of hits out of all of them.
+func PageHits(hits []SearchHit, s SearchQuery) []SearchHit {
+	limit, offset := s.Window()
+	if offset >= len(hits) {
+		return []SearchHit{}
+	}
+	return hits[offset:min(offset+limit, len(hits))]
+}
+
+// countFacets tallies matched quotes by tradition and theme, most
+// common first, naming each value with name.
+func countFacets(quotes []models.Quote, name func(kind, id string) string) map[string][]Facet {
+	counts := map[string]map[string]int{"tradition": {}, "theme": {}}
+	for _, q := range quotes {
+		if q.PhilosophyID != "" {
+			counts["tradition"][q.PhilosophyID]++
+		}
+		for _, t := range q.ThemeIDs {
+			counts["theme"][t]++
+		}
+	}
+	facets := make(map[string][]Facet, len(counts))
+	for dim, byID := range counts {
+		kind := map[string]string{"tradition": "philosophy", "theme": "theme"}[dim]
+		list := []Facet{}
+		for id, n := range byID {
+			list = append(list, Facet{ID: id, Name: cmp.Or(name(kind, id), id), Count: n})
+		}
+		SortFacets(list)
+		facets[dim] = list
+	}
+	return facets
+}
+
+// SortFacets orders facets most common first, then by name.
+func SortFacets(list []Facet) {
+	slices.SortFunc(list, func(a, b Facet) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Name, b.Name), cmp.Compare(a.ID, b.ID))
+	})
+}
+
+// --- In-memory implementation ---
+
+// SearchQuotes answers from the store's Index, kept up to date by
+// every write, and matches names against the philosophers, traditions
+// and themes.
+func (s *Store) SearchQuotes(sq SearchQuery) (SearchResults, error) {
+	s.mu.Lock()
+	if s.index == nil {
+		s.indexAll()
+	}
+	s.mu.Unlock()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	res := s.index.Search(sq, func(kind, id string) string {
+		if kind == "philosophy" {
+			return s.Philosophies[id].Name
+		}
+		return s.Themes[id].Name
+	})
+	var names []NameMatch
+	for _, id := range sortedKeys(s.Philosophers) {
+		names = append(names, NameMatch{Kind: "philosopher", ID: id, Name: s.Philosophers[id].Name})
+	}
+	for _, id := range sortedKeys(s.Philosophies) {
+		names = append(names, NameMatch{Kind: "philosophy", ID: id, Name: s.Philosophies[id].Name})
+	}
+	for _, id := range sortedKeys(s.Themes) {
+		names = append(names, NameMatch{Kind: "theme", ID: id, Name: s.Themes[id].Name})
+	}
+	err := Fuzzy(&res, sq.Text, names, func() (map[string]int, error) { return s.index.Vocabulary(), nil })
+	return res, err
+}
@@ -0,0 +1,94 @@
+package store_test
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"perennial-wisdom/models"
+	"perennial-wisdom/store"
+)
+
+// searchFixture adds quotes that only match through their longer fields.
+func searchFixture(t *testing.T, s store.ReadWriter) {
+	t.Helper()
+	for _, q := range []models.Quote{
+		{ID: "srch-1", Text: "Begin at once to live.", PhilosopherID: "seneca", PhilosophyID: "stoic",
+			ThemeIDs: []string{"death"}, ExpositionStandard: "Procrastination <wastes> the zephyrine hours.", Status: store.StatusPublished},
+		{ID: "srch-2", Text: "Zephyrine winds carry the zephyrine seed.", PhilosopherID: "seneca", PhilosophyID: "stoic",
+			ThemeIDs: []string{"death", "impermanence"}, Status: store.StatusPublished},
+		{ID: "srch-3", Text: "Not yet public.", PhilosopherID: "seneca", PhilosophyID: "stoic",
+			ModernReinterpretation: "A zephyrine draft.", Status: store.StatusDraft},
+	} {
+		if err := s.CreateQuote(q, store.Change{Author: "ana"}); err != nil {
+			t.Fatalf("CreateQuote %s: %v", q.ID, err)
+		}
+	}
+}
+
+func TestSearchQuotesRanksAndHighlights(t *testing.T) {
+	s := store.New()
+	searchFixture(t, s)
+
+	res, err := s.SearchQuotes(store.SearchQuery{Text: "Zephyrine"})
+	if err != nil {
+		t.Fatalf("SearchQuotes: %v", err)
+	}
+	if res.Total != 2 || len(res.Hits) != 2 {
+		t.Fatalf("expected the two live matches, got %d: %+v", res.Total, res.Hits)
+	}
+	// Two matches in the quote text outrank one in the exposition.
+	if res.Hits[0].Quote.ID != "srch-2" || res.Hits[1].Quote.ID != "srch-1" || res.Hits[0].Rank <= res.Hits[1].Rank {
+		t.Errorf("ranking: %s (%v), %s (%v)", res.Hits[0].Quote.ID, res.Hits[0].Rank, res.Hits[1].Quote.ID, res.Hits[1].Rank)
+	}
+	if got, want := res.Hits[1].Snippet, "Procrastination &lt;wastes&gt; the <mark>zephyrine</mark> hours."; got != want {
+		t.Errorf("snippet:\n got %q\nwant %q", got, want)
+	}
+
+	if got := res.Facets["theme"]; len(got) != 2 || got[0] != (store.Facet{ID: "death", Name: "Contemplation of Death", Count: 2}) {
+		t.Errorf("theme facets: %+v", got)
+	}
+	if got := res.Facets["tradition"]; len(got) != 1 || got[0].ID != "stoic" || got[0].Count != 2 {
+		t.Errorf("tradition facets: %+v", got)
+	}
+
+	page, _ := s.SearchQuotes(store.SearchQuery{Text: "zephyrine", Limit: 1, Offset: 1})
+	if page.Total != 2 || len(page.Hits) != 1 || page.Hits[0].Quote.ID != "srch-1" {
+		t.Errorf("second page: %+v", page)
+	}
+	narrowed, _ := s.SearchQuotes(store.SearchQuery{Text: "zephyrine", Filter: store.Where("theme", "impermanence")})
+	if narrowed.Total != 1 || narrowed.Hits[0].Quote.ID != "srch-2" {
+		t.Errorf("filtered: %+v", narrowed)
+	}
+	// Search is public whatever the filter says.
+	drafts, _ := s.SearchQuotes(store.SearchQuery{Text: "zephyrine draft", Filter: store.Where("status", store.StatusDraft)})
+	if drafts.Total != 0 {
+		t.Errorf("drafts must not be searchable: %+v", drafts.Hits)
+	}
+	if empty, _ := s.SearchQuotes(store.SearchQuery{Text: "  "}); empty.Total != 0 || empty.Hits == nil {
+		t.Errorf("blank query: %+v", empty)
+	}
+}
+
+func TestSearchSnippetTrimsLongFields(t *testing.T) {
+	long := strings.Repeat("Filler words go here. ", 30) + "The quokka appears late. " + strings.Repeat("More filler. ", 30)
+	x := store.NewIndex([]models.Quote{{ID: "x", Text: "Short.", ExpositionStandard: long}})
+	res := x.Search(store.SearchQuery{Text: "quokka"}, func(kind, id string) string { return "" })
+	if len(res.Hits) != 1 {
+		t.Fatalf("hits: %+v", res.Hits)
+	}
+	got := res.Hits[0].Snippet
+	if !strings.HasPrefix(got, "…") || !strings.HasSuffix(got, "…") || !strings.Contains(got, "<mark>quokka</mark>") || len(got) > 300 {
+		t.Errorf("snippet: %q", got)
+	}
+}
+
+func TestParseSearch(t *testing.T) {
+	s, err := store.ParseSearch(url.Values{"q": {" stillness "}, "limit": {"500"}, "offset": {"40"}, "theme": {"control"}})
+	if err != nil || s.Text != "stillness" || s.Limit != store.MaxLimit || s.Offset != 40 || len(s.Filter["theme"].Values) != 1 {
+		t.Errorf("ParseSearch: %+v (%v)", s, err)
+	}
+	if _, err := store.ParseSearch(url.Values{"offset": {"-1"}}); err == nil {
+		t.Error("expected an error for a negative offset")
+	}
+}
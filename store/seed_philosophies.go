@@ -17,7 +17,7 @@ func SeedPhilosophies() []models.Philosophy {
 				"Negative visualization (premeditatio malorum)",
 				"The obstacle is the way",
 			},
-			RelatedIDs: []string{"buddhist", "vedantic", "taoist"},
+			RelatedIDs: []string{"buddhist", "vedantic", "taoist", "epicurean", "cynic", "socratic"},
 		},
 		{
 			ID:   "epicurean",
@@ -69,7 +69,7 @@ func SeedPhilosophies() []models.Philosophy {
 				"The Middle Way between asceticism and indulgence",
 				"Mindfulness and meditation as liberation",
 			},
-			RelatedIDs: []string{"stoic", "vedantic", "taoist"},
+			RelatedIDs: []string{"stoic", "vedantic", "taoist", "epicurean", "cynic", "sufi", "krishnamurti"},
 		},
 		{
 			ID:   "sufi",
@@ -95,7 +95,7 @@ func SeedPhilosophies() []models.Philosophy {
 				"Liberation (moksha) through knowledge (jnana)",
 				"Neti neti — not this, not this — via negativa",
 			},
-			RelatedIDs: []string{"buddhist", "sufi", "stoic"},
+			RelatedIDs: []string{"buddhist", "sufi", "stoic", "socratic", "krishnamurti"},
 		},
 		{
 			ID:   "taoist",
@@ -108,7 +108,7 @@ func SeedPhilosophies() []models.Philosophy {
 				"Simplicity (pu) — the uncarved block",
 				"Yin-yang: complementary opposites",
 			},
-			RelatedIDs: []string{"stoic", "buddhist", "cynic"},
+			RelatedIDs: []string{"stoic", "buddhist", "cynic", "krishnamurti"},
 		},
 		{
 			ID:   "krishnamurti",
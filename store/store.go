@@ -1,20 +1,34 @@
 package store
 
-import "perennial-wisdom/models"
+import (
+	"sync"
+	"time"
+
+	"perennial-wisdom/models"
+)
 
 // Store holds all in-memory data, indexed by ID for fast lookup.
 // Flat maps — no ORM, no magic, fully transparent to agents and humans.
+// The Repository and Writer methods guard the maps with mu; code that
+// touches the maps directly must not race with writes.
 type Store struct {
+	mu    sync.RWMutex
+	batch sync.Mutex // serializes Batch
+
 	Quotes       map[string]models.Quote
 	Philosophers map[string]models.Philosopher
 	Philosophies map[string]models.Philosophy
 	Themes       map[string]models.Theme
 	Evidence     map[string]models.Evidence
+
+	revisions map[string][]models.Revision // by "kind/id", oldest first
+	stamps    map[string]Stamp             // quote dates, by ID
+	index     *Index                       // serves SearchQuotes; built on first use if nil
 }
 
 // New creates a Store pre-loaded with seed data.
-// All data is in memory; swap this for a database layer later
-// by implementing the same method signatures on a DB-backed store.
+// All data is in memory; db.Repository serves the same Repository
+// contract from a database.
 func New() *Store {
 	s := &Store{
 		Quotes:       make(map[string]models.Quote),
@@ -36,9 +50,14 @@ func New() *Store {
 	for _, e := range SeedEvidence() {
 		s.Evidence[e.ID] = e
 	}
+	now := time.Now().UTC()
+	s.stamps = map[string]Stamp{}
 	for _, q := range SeedQuotes() {
+		q.Status = StatusPublished
 		s.Quotes[q.ID] = q
+		s.stamps[q.ID] = Stamp{Published: now, Updated: now}
 	}
+	s.indexAll()
 
 	return s
 }
@@ -0,0 +1,160 @@
+package store
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// Entity kinds SearchAll groups its results by, in order.
+var SearchKinds = []string{"quote", "philosopher", "philosophy", "theme", "evidence"}
+
+// UnifiedResults is a search across every kind of entity.
+type UnifiedResults struct {
+	Total  int            `json:"total"`  // matches of every kind
+	Counts map[string]int `json:"counts"` // matches by kind
+
+	// Quotes are SearchQuotes' results, with facets and paging.
+	Quotes SearchResults `json:"quotes"`
+	// Groups hold the best philosophers, traditions, themes and
+	// evidence, in SearchKinds order, empty ones included.
+	Groups []SearchGroup `json:"groups"`
+}
+
+// SearchGroup is one kind of entity's share of a unified search.
+type SearchGroup struct {
+	Kind  string      `json:"kind"`
+	Total int         `json:"total"`
+	Hits  []EntityHit `json:"hits"`
+}
+
+// EntityHit is a philosopher, tradition, theme or evidence entry that
+// matches a search.
+type EntityHit struct {
+	Kind    string  `json:"kind"`
+	ID      string  `json:"id"`
+	Title   string  `json:"title"` // name, or evidence title
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet"` // HTML, as SearchHit.Snippet
+	Entity  any     `json:"entity"`
+}
+
+// searchDoc is an entity as SearchAll indexes it: its fields in the
+// order snippets prefer them, descriptions before the name they repeat.
+type searchDoc struct {
+	hit    EntityHit
+	fields []field
+}
+
+// SearchAll searches quotes with repo.SearchQuotes and, the same way the
+// Index does, philosophers (name, key teachings, bio), traditions (name,
+// core principles, origin), themes (name, description) and evidence
+// (title, finding, source). s.Limit caps every group; s.Offset and
+// s.Filter, which speak of quotes, apply to quotes alone.
+//
+// The other kinds are few, so each search reads them whole and indexes
+// them on the spot; ranks compare only within a kind.
+func SearchAll(repo Repository, s SearchQuery) (UnifiedResults, error) {
+	res := UnifiedResults{Counts: map[string]int{}}
+	var err error
+	if res.Quotes, err = repo.SearchQuotes(s); err != nil {
+		return res, err
+	}
+	res.Counts["quote"] = res.Quotes.Total
+	res.Total = res.Quotes.Total
+
+	docs, err := searchDocs(repo)
+	if err != nil {
+		return res, err
+	}
+	limit, _ := s.Window()
+	for _, kind := range SearchKinds[1:] {
+		g := searchGroup(kind, docs[kind], s.Text, limit)
+		res.Groups = append(res.Groups, g)
+		res.Counts[kind] = g.Total
+		res.Total += g.Total
+	}
+	if res.Total > 0 {
+		res.Quotes.DidYouMean = ""
+	}
+	return res, nil
+}
+
+// searchGroup ranks one kind of entity against text.
+func searchGroup(kind string, docs []searchDoc, text string, limit int) SearchGroup {
+	g := SearchGroup{Kind: kind, Hits: []EntityHit{}}
+	if strings.TrimSpace(text) == "" {
+		return g
+	}
+	idx := newInverted()
+	byID := map[string]searchDoc{}
+	for _, d := range docs {
+		idx.add(d.hit.ID, d.fields)
+		byID[d.hit.ID] = d
+	}
+	scores, find := idx.match(text)
+	for id, score := range scores {
+		h := byID[id].hit
+		h.Rank = score
+		g.Hits = append(g.Hits, h)
+	}
+	slices.SortFunc(g.Hits, func(a, b EntityHit) int {
+		return cmp.Or(cmp.Compare(b.Rank, a.Rank), cmp.Compare(a.Title, b.Title), cmp.Compare(a.ID, b.ID))
+	})
+	g.Total = len(g.Hits)
+	g.Hits = g.Hits[:min(limit, len(g.Hits))]
+	for i, h := range g.Hits {
+		g.Hits[i].Snippet = snippet(byID[h.ID].fields, find)
+	}
+	return g
+}
+
+// searchDocs reads the entities SearchAll searches besides quotes.
+func searchDocs(repo Repository) (map[string][]searchDoc, error) {
+	docs := map[string][]searchDoc{}
+	philosophers, _, err := repo.ListPhilosophers(nil, Page{})
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range philosophers {
+		docs["philosopher"] = append(docs["philosopher"], searchDoc{
+			hit: EntityHit{Kind: "philosopher", ID: p.ID, Title: p.Name, Entity: p},
+			fields: []field{
+				{p.Bio, 0.2}, {strings.Join(p.KeyTeachings, " · "), 0.4}, {p.Name, 1.0},
+			},
+		})
+	}
+	philosophies, _, err := repo.ListPhilosophies(Page{})
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range philosophies {
+		docs["philosophy"] = append(docs["philosophy"], searchDoc{
+			hit: EntityHit{Kind: "philosophy", ID: p.ID, Title: p.Name, Entity: p},
+			fields: []field{
+				{strings.Join(p.CorePrinciples, " · "), 0.4}, {p.Origin, 0.2}, {p.Name, 1.0},
+			},
+		})
+	}
+	themes, _, err := repo.ListThemes(Page{})
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range themes {
+		docs["theme"] = append(docs["theme"], searchDoc{
+			hit:    EntityHit{Kind: "theme", ID: t.ID, Title: t.Name, Entity: t},
+			fields: []field{{t.Description, 0.4}, {t.Name, 1.0}},
+		})
+	}
+	evidence, _, err := repo.ListEvidence(nil, Page{})
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range evidence {
+		docs["evidence"] = append(docs["evidence"], searchDoc{
+			hit:    EntityHit{Kind: "evidence", ID: e.ID, Title: e.Title, Entity: e},
+			fields: []field{{e.Finding, 0.4}, {e.Source, 0.2}, {e.Title, 1.0}},
+		})
+	}
+	return docs, nil
+}
@@ -0,0 +1,62 @@
+package store_test
+
+import (
+	"strings"
+	"testing"
+
+	"perennial-wisdom/models"
+	"perennial-wisdom/store"
+)
+
+func TestSearchAllGroupsByKind(t *testing.T) {
+	s := store.New()
+	montaigne := models.Philosopher{ID: "montaigne", Name: "Michel de Montaigne", Era: "1533–1592",
+		Bio: "French essayist who held that to philosophize is to learn to die, and wrote on death as a companion to be made familiar."}
+	if err := s.CreatePhilosopher(montaigne, store.Change{Author: "ana"}); err != nil {
+		t.Fatalf("CreatePhilosopher: %v", err)
+	}
+	res, err := store.SearchAll(s, store.SearchQuery{Text: "death"})
+	if err != nil {
+		t.Fatalf("SearchAll: %v", err)
+	}
+	found := map[string]bool{}
+	for _, g := range res.Groups {
+		if g.Total != res.Counts[g.Kind] || len(g.Hits) > g.Total {
+			t.Errorf("%s: total %d, count %d, %d hits", g.Kind, g.Total, res.Counts[g.Kind], len(g.Hits))
+		}
+		for _, h := range g.Hits {
+			if h.Kind != g.Kind {
+				t.Errorf("%s hit in the %s group", h.Kind, g.Kind)
+			}
+			found[h.Kind+"/"+h.ID] = true
+		}
+	}
+	for _, want := range []string{"theme/death", "philosopher/montaigne", "evidence/death-awareness"} {
+		if !found[want] {
+			t.Errorf("missing %s in %+v", want, res.Groups)
+		}
+	}
+	if res.Counts["quote"] != res.Quotes.Total || res.Quotes.Total == 0 {
+		t.Errorf("quote count %d, quotes total %d", res.Counts["quote"], res.Quotes.Total)
+	}
+	sum := 0
+	for _, n := range res.Counts {
+		sum += n
+	}
+	if sum != res.Total || len(res.Groups) != len(store.SearchKinds)-1 {
+		t.Errorf("total %d, counts %v, %d groups", res.Total, res.Counts, len(res.Groups))
+	}
+
+	// Theme names outrank passing mentions; snippets mark the match.
+	theme := res.Groups[2]
+	if theme.Kind != "theme" || theme.Hits[0].ID != "death" || !strings.Contains(theme.Hits[0].Snippet, "<mark>") {
+		t.Errorf("theme group: %+v", theme)
+	}
+
+	limited, _ := store.SearchAll(s, store.SearchQuery{Text: "death", Limit: 1})
+	for _, g := range limited.Groups {
+		if len(g.Hits) > 1 || g.Total != res.Counts[g.Kind] {
+			t.Errorf("%s: limit ignored or total changed: %d hits of %d", g.Kind, len(g.Hits), g.Total)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Integrity checks, as reported in Issue.Check.
+const (
+	CheckInvalid        = "invalid"             // a field Check* rejects: missing, malformed or dangling
+	CheckAsymmetric     = "asymmetric-relation" // a school lists another that doesn't list it back
+	CheckUnthemed       = "unthemed-evidence"   // evidence linked to no theme
+	CheckOrphan         = "orphan-philosopher"  // a philosopher with no school or no quotes
+	CheckDuplicateQuote = "duplicate-quote"     // two quotes with the same text
+	CheckPinClash       = "pin-clash"           // two quotes pinned to the same day
+)
+
+// Issue severities. Errors are broken data; warnings are gaps a curator
+// should look at but that nothing depends on.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// IntegrityReport is the outcome of Validate, shaped for machines: the
+// validate command prints it as JSON.
+type IntegrityReport struct {
+	Counts map[string]int `json:"counts"` // entities checked, by kind
+	Issues []Issue        `json:"issues"`
+}
+
+// Issue is one problem with one entity.
+type Issue struct {
+	Check    string `json:"check"`
+	Severity string `json:"severity"`
+	Kind     string `json:"kind"`
+	ID       string `json:"id"`
+	Field    string `json:"field,omitempty"`
+	Message  string `json:"message"`
+}
+
+// Errors counts the issues of error severity.
+func (r *IntegrityReport) Errors() int {
+	n := 0
+	for _, is := range r.Issues {
+		if is.Severity == SeverityError {
+			n++
+		}
+	}
+	return n
+}
+
+// OK reports whether the corpus has no errors; warnings are allowed.
+func (r *IntegrityReport) OK() bool { return r.Errors() == 0 }
+
+// Validate checks the whole graph in repo, quotes of every status
+// included. On top of what the Check* functions enforce per write, it
+// looks across entities: no two quotes may share a text, and school
+// relations should be mutual, evidence should support some theme, every
+// philosopher should have a school and a quote, and no two quotes should
+// share a pinned day.
+//
+// Validate reads the whole corpus into memory; errors are lookup
+// failures, not findings.
+func Validate(repo Repository) (*IntegrityReport, error) {
+	philosophies, _, err := repo.ListPhilosophies(Page{})
+	if err != nil {
+		return nil, err
+	}
+	philosophers, _, err := repo.ListPhilosophers(nil, Page{})
+	if err != nil {
+		return nil, err
+	}
+	themes, _, err := repo.ListThemes(Page{})
+	if err != nil {
+		return nil, err
+	}
+	evidence, _, err := repo.ListEvidence(nil, Page{})
+	if err != nil {
+		return nil, err
+	}
+	quotes, _, err := repo.ListQuotes(Where("status", Statuses...), Page{})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := map[string]map[string]bool{
+		"philosophy": {}, "philosopher": {}, "theme": {}, "evidence": {},
+	}
+	related := map[string][]string{}
+	for _, p := range philosophies {
+		ids["philosophy"][p.ID] = true
+		related[p.ID] = p.RelatedIDs
+	}
+	for _, p := range philosophers {
+		ids["philosopher"][p.ID] = true
+	}
+	for _, t := range themes {
+		ids["theme"][t.ID] = true
+	}
+	for _, e := range evidence {
+		ids["evidence"][e.ID] = true
+	}
+	exists := func(kind, id string) (bool, error) { return ids[kind][id], nil }
+
+	r := &IntegrityReport{
+		Counts: map[string]int{
+			"philosophy": len(philosophies), "philosopher": len(philosophers),
+			"theme": len(themes), "evidence": len(evidence), "quote": len(quotes),
+		},
+		Issues: []Issue{},
+	}
+	add := func(check, severity, kind, id, field, format string, args ...any) {
+		r.Issues = append(r.Issues, Issue{
+			Check: check, Severity: severity, Kind: kind, ID: id, Field: field,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+	// invalid reports the problems a Check* found; any other error
+	// means the check itself failed.
+	invalid := func(kind, id string, err error) error {
+		var verr *ValidationError
+		if !errors.As(err, &verr) {
+			return err
+		}
+		for _, p := range verr.Problems {
+			add(CheckInvalid, SeverityError, kind, id, p.Field, "%s", p.Message)
+		}
+		return nil
+	}
+
+	for _, p := range philosophies {
+		if err := invalid("philosophy", p.ID, CheckPhilosophy(p, exists)); err != nil {
+			return nil, err
+		}
+		for _, other := range p.RelatedIDs {
+			if ids["philosophy"][other] && other != p.ID && !slices.Contains(related[other], p.ID) {
+				add(CheckAsymmetric, SeverityWarning, "philosophy", p.ID, "related_ids",
+					"related to %q, which does not list %q back", other, p.ID)
+			}
+		}
+	}
+
+	quoted := map[string]bool{}
+	texts := map[string]string{}
+	pinned := map[string]string{}
+	for _, q := range quotes {
+		if err := invalid("quote", q.ID, CheckQuote(q, exists)); err != nil {
+			return nil, err
+		}
+		quoted[q.PhilosopherID] = true
+		t := fold(q.Text)
+		if first, dup := texts[t]; dup && t != "" {
+			add(CheckDuplicateQuote, SeverityError, "quote", q.ID, "text", "same text as quote %q", first)
+		} else {
+			texts[t] = q.ID
+		}
+		dates, _ := PinnedDates(q)
+		for _, day := range dates {
+			if first, dup := pinned[day]; dup {
+				add(CheckPinClash, SeverityWarning, "quote", q.ID, "meta."+PinKey,
+					"pinned to %s, which quote %q already has", day, first)
+			} else {
+				pinned[day] = q.ID
+			}
+		}
+	}
+
+	for _, p := range philosophers {
+		if err := invalid("philosopher", p.ID, CheckPhilosopher(p, exists)); err != nil {
+			return nil, err
+		}
+		if p.PhilosophyID == "" {
+			add(CheckOrphan, SeverityWarning, "philosopher", p.ID, "philosophy_id", "belongs to no school")
+		}
+		if !quoted[p.ID] {
+			add(CheckOrphan, SeverityWarning, "philosopher", p.ID, "", "has no quotes")
+		}
+	}
+
+	for _, t := range themes {
+		if err := invalid("theme", t.ID, CheckTheme(t, exists)); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, e := range evidence {
+		if err := invalid("evidence", e.ID, CheckEvidence(e, exists)); err != nil {
+			return nil, err
+		}
+		if len(e.ThemeIDs) == 0 {
+			add(CheckUnthemed, SeverityWarning, "evidence", e.ID, "theme_ids", "supports no theme")
+		}
+	}
+	return r, nil
+}
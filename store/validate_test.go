@@ -0,0 +1,63 @@
+package store_test
+
+import (
+	"reflect"
+	"testing"
+
+	"perennial-wisdom/models"
+	"perennial-wisdom/store"
+)
+
+func TestValidateSeedCorpus(t *testing.T) {
+	report, err := store.Validate(store.New())
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if !report.OK() {
+		t.Errorf("seed corpus has integrity errors: %+v", report.Issues)
+	}
+	if report.Counts["quote"] == 0 || report.Counts["philosophy"] == 0 {
+		t.Errorf("counts: %v", report.Counts)
+	}
+}
+
+func TestValidateFindsGraphProblems(t *testing.T) {
+	s := store.New()
+	// Written straight into the maps: the Writer would refuse most of these.
+	s.Themes["control"] = models.Theme{ID: "control", Name: "Control", PhilosophyIDs: []string{"stoic", "atlantean"}}
+	s.Philosophies["cynic-2"] = models.Philosophy{ID: "cynic-2", Name: "Neo-Cynicism", RelatedIDs: []string{"stoic"}}
+	s.Philosophers["hermit"] = models.Philosopher{ID: "hermit", Name: "A Hermit"}
+	s.Evidence["loose"] = models.Evidence{ID: "loose", Title: "Loose end"}
+	dup := s.Quotes["e1"]
+	dup.ID, dup.Slug, dup.Text = "zz-dup", "", "  it's NOT what happens to you, but how you react to it that matters "
+	s.Quotes[dup.ID] = dup
+
+	report, err := store.Validate(s)
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	// Only the problems planted above count: the seed has warnings of its own.
+	seed, _ := store.Validate(store.New())
+	type key struct{ Check, Kind, ID, Field string }
+	got := map[key]string{}
+	for _, is := range report.Issues {
+		got[key{is.Check, is.Kind, is.ID, is.Field}] = is.Severity
+	}
+	for _, is := range seed.Issues {
+		delete(got, key{is.Check, is.Kind, is.ID, is.Field})
+	}
+	want := map[key]string{
+		{store.CheckInvalid, "theme", "control", "philosophy_ids"}:      store.SeverityError,
+		{store.CheckAsymmetric, "philosophy", "cynic-2", "related_ids"}: store.SeverityWarning,
+		{store.CheckOrphan, "philosopher", "hermit", "philosophy_id"}:   store.SeverityWarning,
+		{store.CheckOrphan, "philosopher", "hermit", ""}:                store.SeverityWarning,
+		{store.CheckUnthemed, "evidence", "loose", "theme_ids"}:         store.SeverityWarning,
+		{store.CheckDuplicateQuote, "quote", "zz-dup", "text"}:          store.SeverityError,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("issues:\n got %v\nwant %v", got, want)
+	}
+	if report.OK() || report.Errors() != 2 {
+		t.Errorf("expected 2 errors, got %d", report.Errors())
+	}
+}
@@ -0,0 +1,189 @@
+package store
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"perennial-wisdom/models"
+)
+
+// Quote statuses. New quotes start as drafts; only published quotes are
+// public. Quotes stored before the workflow existed have no status and
+// count as published.
+const (
+	StatusDraft     = "draft"
+	StatusInReview  = "in_review"
+	StatusPublished = "published"
+	StatusRetired   = "retired"
+
+	// StatusScheduled is a published quote whose PublishAt is still
+	// ahead. It is derived, never stored, but can be filtered on.
+	StatusScheduled = "scheduled"
+)
+
+// Statuses lists every value the "status" filter dimension can take.
+var Statuses = []string{StatusDraft, StatusInReview, StatusScheduled, StatusPublished, StatusRetired}
+
+// EffectiveStatus is q's status at time now.
+func EffectiveStatus(q models.Quote, now time.Time) string {
+	switch {
+	case q.Status == "" || q.Status == StatusPublished && (q.PublishAt == nil || !q.PublishAt.After(now)):
+		return StatusPublished
+	case q.Status == StatusPublished:
+		return StatusScheduled
+	}
+	return q.Status
+}
+
+// Live reports whether the public may see q right now.
+func Live(q models.Quote) bool {
+	return EffectiveStatus(q, time.Now()) == StatusPublished
+}
+
+// WithStatus restricts a quote filter to live quotes unless it already
+// says which statuses it wants. Every ListQuotes implementation applies
+// it, so public queries can't leak drafts by forgetting to ask.
+func WithStatus(f Filter) Filter {
+	if _, ok := f["status"]; ok {
+		return f
+	}
+	out := Filter{"status": {Values: []string{StatusPublished}}}
+	for dim, c := range f {
+		out[dim] = c
+	}
+	return out
+}
+
+// Workflow moves quotes through their lifecycle:
+//
+//	draft → in_review → published → retired → draft
+//
+// A quote in review can be sent back to draft. A published quote can be
+// rescheduled. Drafts may be published directly unless SecondApprover is
+// set, in which case publishing needs a review approved by someone who
+// wrote none of the quote's content since it was last published — or
+// since it was created, if it never was.
+//
+// Under SecondApprover, Edit sends edited published quotes back to
+// review, so their new content needs approval too.
+//
+// The rule trusts Change.Author and the revision history's authors, so
+// it only holds where authors can't pick their names: over HTTP, each
+// curator's own token names them.
+type Workflow struct {
+	SecondApprover bool
+}
+
+var transitions = map[string][]string{
+	StatusDraft:     {StatusInReview, StatusPublished},
+	StatusInReview:  {StatusDraft, StatusPublished},
+	StatusPublished: {StatusPublished, StatusRetired},
+	StatusRetired:   {StatusDraft},
+}
+
+// Moves lists the statuses q may move to next. Publishing from review
+// may still be refused by the second-approver rule, depending on who asks.
+func (w Workflow) Moves(q models.Quote) []string {
+	from := cmp.Or(q.Status, StatusPublished)
+	var moves []string
+	for _, to := range transitions[from] {
+		if w.SecondApprover && from == StatusDraft && to == StatusPublished {
+			continue
+		}
+		moves = append(moves, to)
+	}
+	return moves
+}
+
+// Transition moves quote id to status to, on behalf of ch.Author.
+// publishAt schedules publication; it is only allowed when publishing.
+// Disallowed moves fail with a *ValidationError on "status".
+func (w Workflow) Transition(rw ReadWriter, id, to string, publishAt *time.Time, ch Change) error {
+	q, err := rw.GetQuote(id)
+	if err != nil {
+		return err
+	}
+	from := cmp.Or(q.Status, StatusPublished)
+	invalid := func(format string, args ...any) error {
+		return &ValidationError{Problems: []Problem{{Field: "status", Message: fmt.Sprintf(format, args...)}}}
+	}
+
+	if !slices.Contains(transitions[from], to) {
+		return invalid("a %s quote can't move to %q", from, to)
+	}
+	if publishAt != nil && to != StatusPublished {
+		return invalid("publish_at only applies when publishing")
+	}
+	if to == StatusPublished && from != StatusPublished && w.SecondApprover {
+		if from != StatusInReview {
+			return invalid("quotes must be reviewed before publishing")
+		}
+		reviewers, err := reviewAuthors(rw, q.ID)
+		if err != nil {
+			return err
+		}
+		if slices.Contains(reviewers, ch.Author) {
+			return invalid("needs a second approver: %s wrote it since it was last published", strings.Join(reviewers, ", "))
+		}
+	}
+
+	q.Status = to
+	q.PublishAt = nil
+	if publishAt != nil {
+		t := publishAt.UTC().Truncate(time.Second)
+		q.PublishAt = &t
+	}
+	return rw.UpdateQuote(q, ch)
+}
+
+// Edit saves q, an edit that leaves its status alone. Under
+// SecondApprover, new content for a published or scheduled quote hasn't
+// been approved, so the edit takes it off the site and back to review.
+func (w Workflow) Edit(rw ReadWriter, q models.Quote, ch Change) error {
+	if w.SecondApprover && cmp.Or(q.Status, StatusPublished) == StatusPublished {
+		stored, err := rw.GetQuote(q.ID)
+		if err != nil {
+			return err
+		}
+		before, _ := json.Marshal(stored)
+		after, _ := json.Marshal(q)
+		if len(Diff(before, after)) > 0 {
+			q.Status, q.PublishAt = StatusInReview, nil
+		}
+	}
+	return rw.UpdateQuote(q, ch)
+}
+
+// reviewAuthors lists who wrote the quote's content since it was last
+// published, or since it was created. Revisions that only moved it
+// through the workflow don't count: submitting a quote isn't writing it.
+func reviewAuthors(h History, id string) ([]string, error) {
+	revs, err := h.ListRevisions("quote", id)
+	if err != nil {
+		return nil, err
+	}
+	var authors []string
+	for i, rev := range revs {
+		var snap struct {
+			Status string `json:"status"`
+		}
+		if err := json.Unmarshal(rev.Data, &snap); err != nil || cmp.Or(snap.Status, StatusPublished) == StatusPublished {
+			break
+		}
+		var before json.RawMessage
+		if i+1 < len(revs) {
+			before = revs[i+1].Data
+		}
+		wrote := slices.ContainsFunc(Diff(before, rev.Data), func(c FieldChange) bool {
+			return c.Field != "status" && c.Field != "publish_at"
+		})
+		if wrote && !slices.Contains(authors, rev.Author) {
+			authors = append(authors, rev.Author)
+		}
+	}
+	return authors, nil
+}
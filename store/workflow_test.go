@@ -0,0 +1,139 @@
+package store_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"perennial-wisdom/models"
+	"perennial-wisdom/store"
+)
+
+func TestWorkflowTransitions(t *testing.T) {
+	s := store.New()
+	wf := store.Workflow{SecondApprover: true}
+	ana := store.Change{Author: "ana"}
+	ben := store.Change{Author: "ben"}
+
+	if err := s.CreateQuote(models.Quote{ID: "w1", Text: "Begin."}, ana); err != nil {
+		t.Fatalf("CreateQuote: %v", err)
+	}
+	if q, _ := s.GetQuote("w1"); q.Status != store.StatusDraft || store.Live(q) {
+		t.Errorf("new quotes should be hidden drafts, got status %q", q.Status)
+	}
+
+	move := func(to string, at *time.Time, ch store.Change) error {
+		return wf.Transition(s, "w1", to, at, ch)
+	}
+	if err := move(store.StatusRetired, nil, ana); !errors.Is(err, store.ErrInvalid) {
+		t.Errorf("draft → retired: expected ErrInvalid, got %v", err)
+	}
+	if err := move(store.StatusPublished, nil, ben); !errors.Is(err, store.ErrInvalid) {
+		t.Errorf("publishing an unreviewed draft: expected ErrInvalid, got %v", err)
+	}
+	if err := move(store.StatusInReview, nil, ana); err != nil {
+		t.Fatalf("draft → in_review: %v", err)
+	}
+	if err := move(store.StatusPublished, nil, ana); !errors.Is(err, store.ErrInvalid) {
+		t.Errorf("self-approval: expected ErrInvalid, got %v", err)
+	}
+	if err := move(store.StatusDraft, nil, ben); err != nil {
+		t.Fatalf("in_review → draft: %v", err)
+	}
+	if err := move(store.StatusInReview, nil, ben); err != nil {
+		t.Fatalf("draft → in_review: %v", err)
+	}
+	if err := move(store.StatusPublished, nil, ana); !errors.Is(err, store.ErrInvalid) {
+		t.Errorf("approving your own text someone else submitted: expected ErrInvalid, got %v", err)
+	}
+	if err := move(store.StatusPublished, nil, ben); err != nil {
+		t.Fatalf("second approver should publish: %v", err)
+	}
+	if q, _ := s.GetQuote("w1"); !store.Live(q) {
+		t.Errorf("expected w1 live, got status %q", q.Status)
+	}
+	if err := move(store.StatusRetired, nil, ben); err != nil {
+		t.Fatalf("published → retired: %v", err)
+	}
+
+	if err := s.CreateQuote(models.Quote{ID: "w2", Text: "Go.", Status: "archived"}, ana); !errors.Is(err, store.ErrInvalid) {
+		t.Errorf("unknown status: expected ErrInvalid, got %v", err)
+	}
+}
+
+func TestWorkflowScheduledPublishing(t *testing.T) {
+	s := store.New()
+	ana := store.Change{Author: "ana"}
+	s.CreateQuote(models.Quote{ID: "w1", Text: "Later."}, ana)
+
+	future := time.Now().Add(time.Hour)
+	if err := (store.Workflow{}).Transition(s, "w1", store.StatusInReview, &future, ana); !errors.Is(err, store.ErrInvalid) {
+		t.Errorf("publish_at outside publishing: expected ErrInvalid, got %v", err)
+	}
+	if err := (store.Workflow{}).Transition(s, "w1", store.StatusPublished, &future, ana); err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+	q, _ := s.GetQuote("w1")
+	if got := store.EffectiveStatus(q, time.Now()); got != store.StatusScheduled {
+		t.Errorf("expected scheduled, got %q", got)
+	}
+	if got := store.EffectiveStatus(q, future.Add(time.Second)); got != store.StatusPublished {
+		t.Errorf("expected published once the time passes, got %q", got)
+	}
+
+	public, _, _ := s.ListQuotes(nil, store.Page{})
+	for _, p := range public {
+		if p.ID == "w1" {
+			t.Errorf("scheduled quote listed before its time")
+		}
+	}
+	queued, _, _ := s.ListQuotes(store.Where("status", store.StatusScheduled), store.Page{})
+	if len(queued) != 1 || queued[0].ID != "w1" {
+		t.Errorf("expected w1 among scheduled quotes, got %v", queued)
+	}
+
+	if err := store.Rollback(s, "quote", "w1", 1, ana); err != nil {
+		t.Fatalf("Rollback: %v", err)
+	}
+	if q, _ := s.GetQuote("w1"); q.Status != store.StatusPublished || q.PublishAt == nil {
+		t.Errorf("rollback should leave the status alone, got %q %v", q.Status, q.PublishAt)
+	}
+}
+
+func TestWorkflowEditsAfterPublishing(t *testing.T) {
+	s := store.New()
+	ana := store.Change{Author: "ana"}
+	e1, _ := s.GetQuote("e1")
+
+	// Without a second approver, edits go straight out.
+	e1.Text = "Edited once."
+	if err := (store.Workflow{}).Edit(s, e1, ana); err != nil {
+		t.Fatalf("Edit: %v", err)
+	}
+	if q, _ := s.GetQuote("e1"); !store.Live(q) || q.Text != "Edited once." {
+		t.Errorf("expected the edit live, got %q (%s)", q.Text, q.Status)
+	}
+
+	wf := store.Workflow{SecondApprover: true}
+	e1, _ = s.GetQuote("e1")
+	if err := wf.Edit(s, e1, ana); err != nil {
+		t.Fatalf("Edit: %v", err)
+	}
+	if q, _ := s.GetQuote("e1"); !store.Live(q) {
+		t.Errorf("an edit that changes nothing should leave e1 live, got %q", q.Status)
+	}
+
+	e1.Text = "Edited twice."
+	if err := wf.Edit(s, e1, ana); err != nil {
+		t.Fatalf("Edit: %v", err)
+	}
+	if q, _ := s.GetQuote("e1"); q.Status != store.StatusInReview || store.Live(q) {
+		t.Errorf("expected the edit back in review, got %q", q.Status)
+	}
+	if err := wf.Transition(s, "e1", store.StatusPublished, nil, ana); !errors.Is(err, store.ErrInvalid) {
+		t.Errorf("approving your own edit: expected ErrInvalid, got %v", err)
+	}
+	if err := wf.Transition(s, "e1", store.StatusPublished, nil, store.Change{Author: "ben"}); err != nil {
+		t.Errorf("second approver should publish the edit: %v", err)
+	}
+}
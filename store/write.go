@@ -0,0 +1,589 @@
+package store
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"strings"
+	"time"
+
+	"perennial-wisdom/models"
+)
+
+// Write errors. Implementations wrap them with detail, so match with errors.Is.
+var (
+	ErrConflict = errors.New("conflict") // id or slug already taken
+	ErrInvalid  = errors.New("invalid")  // missing fields or dangling references
+)
+
+// ValidationError lists every problem found with an entity, keyed by its
+// JSON field name, so forms can show each one next to its input.
+// It matches ErrInvalid.
+type ValidationError struct {
+	Problems []Problem
+}
+
+// Problem is one invalid field.
+type Problem struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		msgs[i] = p.Field + ": " + p.Message
+	}
+	return "invalid: " + strings.Join(msgs, "; ")
+}
+
+func (e *ValidationError) Unwrap() error { return ErrInvalid }
+
+// Fields groups the problems by field.
+func (e *ValidationError) Fields() map[string]string {
+	m := make(map[string]string, len(e.Problems))
+	for _, p := range e.Problems {
+		if m[p.Field] != "" {
+			m[p.Field] += "; "
+		}
+		m[p.Field] += p.Message
+	}
+	return m
+}
+
+// Writer is the curation contract. Each call is atomic: it validates
+// references, then writes the entity and its links, or changes nothing.
+//
+// Create fails with ErrConflict if the ID (or a quote's slug) is taken;
+// Update replaces the whole entity and fails with ErrNotFound if it
+// doesn't exist. A quote stored without a status counts as published;
+// Workflow is the way to move it through review. Delete follows the schema's foreign keys: references to
+// the deleted entity are cleared, never left dangling.
+//
+// Every successful call records a revision described by ch (see History),
+// and a delete records one for every entity whose references it cleared,
+// described by Cascaded(ch, ...).
+type Writer interface {
+	CreateQuote(q models.Quote, ch Change) error
+	UpdateQuote(q models.Quote, ch Change) error
+	DeleteQuote(id string, ch Change) error
+
+	CreatePhilosopher(p models.Philosopher, ch Change) error
+	UpdatePhilosopher(p models.Philosopher, ch Change) error
+	DeletePhilosopher(id string, ch Change) error
+
+	CreatePhilosophy(p models.Philosophy, ch Change) error
+	UpdatePhilosophy(p models.Philosophy, ch Change) error
+	DeletePhilosophy(id string, ch Change) error
+
+	CreateTheme(t models.Theme, ch Change) error
+	UpdateTheme(t models.Theme, ch Change) error
+	DeleteTheme(id string, ch Change) error
+
+	CreateEvidence(e models.Evidence, ch Change) error
+	UpdateEvidence(e models.Evidence, ch Change) error
+	DeleteEvidence(id string, ch Change) error
+}
+
+// ReadWriter is a Repository that can also be curated, with the history
+// of its curation.
+type ReadWriter interface {
+	Repository
+	Writer
+	History
+
+	// Batch runs fn as one atomic write: if fn fails, none of the writes
+	// it made through its argument are kept.
+	Batch(fn func(ReadWriter) error) error
+}
+
+// Exists reports whether an entity of kind ("philosopher", "philosophy",
+// "theme", "evidence") exists. Each implementation binds it to its own
+// storage — inside the write's transaction, for SQL.
+type Exists func(kind, id string) (bool, error)
+
+// CheckQuote validates a quote's required fields and references.
+func CheckQuote(q models.Quote, exists Exists) error {
+	c := checker{exists: exists}
+	c.id("id", q.ID)
+	if q.Slug != "" {
+		c.id("slug", q.Slug)
+	}
+	c.required("text", q.Text)
+	c.ref("philosopher_id", "philosopher", q.PhilosopherID)
+	c.ref("philosophy_id", "philosophy", q.PhilosophyID)
+	c.refs("theme_ids", "theme", q.ThemeIDs)
+	c.refs("evidence_ids", "evidence", q.EvidenceIDs)
+	if _, ok := transitions[q.Status]; q.Status != "" && !ok {
+		c.problem("status", "unknown status %q", q.Status)
+	}
+	if _, err := PinnedDates(q); err != nil {
+		c.problem("meta."+PinKey, "%v", err)
+	}
+	if _, err := QuoteWeight(q); err != nil {
+		c.problem("meta."+WeightKey, "%v", err)
+	}
+	return c.result()
+}
+
+// CheckPhilosopher validates a philosopher's required fields and school.
+func CheckPhilosopher(p models.Philosopher, exists Exists) error {
+	c := checker{exists: exists}
+	c.id("id", p.ID)
+	c.required("name", p.Name)
+	c.ref("philosophy_id", "philosophy", p.PhilosophyID)
+	return c.result()
+}
+
+// CheckPhilosophy validates a school's required fields and relations.
+func CheckPhilosophy(p models.Philosophy, exists Exists) error {
+	c := checker{exists: exists}
+	c.id("id", p.ID)
+	c.required("name", p.Name)
+	if contains(p.RelatedIDs, p.ID) {
+		c.problem("related_ids", "a school can't be related to itself")
+	}
+	c.refs("related_ids", "philosophy", p.RelatedIDs)
+	return c.result()
+}
+
+// CheckTheme validates a theme's required fields and schools.
+func CheckTheme(t models.Theme, exists Exists) error {
+	c := checker{exists: exists}
+	c.id("id", t.ID)
+	c.required("name", t.Name)
+	c.refs("philosophy_ids", "philosophy", t.PhilosophyIDs)
+	return c.result()
+}
+
+// CheckEvidence validates an evidence entry's required fields and themes.
+func CheckEvidence(e models.Evidence, exists Exists) error {
+	c := checker{exists: exists}
+	c.id("id", e.ID)
+	c.required("title", e.Title)
+	c.refs("theme_ids", "theme", e.ThemeIDs)
+	return c.result()
+}
+
+// idPattern keeps IDs and slugs URL-safe: they appear in /api and /pages paths.
+var idPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// checker collects every problem with an entity so clients can fix them
+// in one round trip.
+type checker struct {
+	exists   Exists
+	problems []Problem
+	err      error // lookup failure — not the client's fault
+}
+
+func (c *checker) problem(field, format string, args ...any) {
+	c.problems = append(c.problems, Problem{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (c *checker) required(field, v string) {
+	if strings.TrimSpace(v) == "" {
+		c.problem(field, "required")
+	}
+}
+
+func (c *checker) id(field, v string) {
+	if v == "" {
+		c.problem(field, "required")
+	} else if !idPattern.MatchString(v) {
+		c.problem(field, "%q must be lowercase letters, digits and dashes", v)
+	}
+}
+
+func (c *checker) ref(field, kind, id string) {
+	if id == "" || c.err != nil {
+		return
+	}
+	ok, err := c.exists(kind, id)
+	if err != nil {
+		c.err = err
+		return
+	}
+	if !ok {
+		c.problem(field, "unknown %s %q", kind, id)
+	}
+}
+
+func (c *checker) refs(field, kind string, ids []string) {
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if id == "" || seen[id] {
+			c.problem(field, "empty or duplicate id %q", id)
+			continue
+		}
+		seen[id] = true
+		c.ref(field, kind, id)
+	}
+}
+
+func (c *checker) result() error {
+	if c.err != nil {
+		return c.err
+	}
+	if len(c.problems) > 0 {
+		return &ValidationError{Problems: c.problems}
+	}
+	return nil
+}
+
+// --- In-memory implementation ---
+
+var _ ReadWriter = (*Store)(nil)
+
+// exists looks IDs up in the maps; callers hold s.mu.
+func (s *Store) exists(kind, id string) (bool, error) {
+	var ok bool
+	switch kind {
+	case "philosopher":
+		_, ok = s.Philosophers[id]
+	case "philosophy":
+		_, ok = s.Philosophies[id]
+	case "theme":
+		_, ok = s.Themes[id]
+	case "evidence":
+		_, ok = s.Evidence[id]
+	}
+	return ok, nil
+}
+
+// CreateQuote adds a new quote. Quotes without a status start as drafts.
+func (s *Store) CreateQuote(q models.Quote, ch Change) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.Quotes[q.ID]; ok {
+		return fmt.Errorf("%w: quote %q already exists", ErrConflict, q.ID)
+	}
+	if q.Status == "" {
+		q.Status = StatusDraft
+	}
+	return s.revised("quote", q.ID, "create", ch, func() error { return s.putQuote(q) })
+}
+
+// UpdateQuote replaces an existing quote.
+func (s *Store) UpdateQuote(q models.Quote, ch Change) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.Quotes[q.ID]; !ok {
+		return ErrNotFound
+	}
+	return s.revised("quote", q.ID, "update", ch, func() error { return s.putQuote(q) })
+}
+
+func (s *Store) putQuote(q models.Quote) error {
+	if err := CheckQuote(q, s.exists); err != nil {
+		return err
+	}
+	if q.Status == "" {
+		q.Status = StatusPublished
+	}
+	if q.Slug != "" {
+		for _, other := range s.Quotes {
+			if other.Slug == q.Slug && other.ID != q.ID {
+				return fmt.Errorf("%w: slug %q is taken by quote %q", ErrConflict, q.Slug, other.ID)
+			}
+		}
+	}
+	now := time.Now().UTC()
+	if s.stamps == nil {
+		s.stamps = map[string]Stamp{}
+	}
+	st := s.stamps[q.ID]
+	st.Updated = now
+	if prev, ok := s.Quotes[q.ID]; q.Status == StatusPublished && (!ok || cmp.Or(prev.Status, StatusPublished) != StatusPublished) {
+		st.Published = now
+	}
+	s.stamps[q.ID] = st
+	s.Quotes[q.ID] = q
+	return nil
+}
+
+// DeleteQuote removes a quote.
+func (s *Store) DeleteQuote(id string, ch Change) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.Quotes[id]; !ok {
+		return ErrNotFound
+	}
+	return s.revised("quote", id, "delete", ch, func() error {
+		delete(s.Quotes, id)
+		delete(s.stamps, id)
+		return nil
+	})
+}
+
+// CreatePhilosopher adds a new philosopher.
+func (s *Store) CreatePhilosopher(p models.Philosopher, ch Change) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.Philosophers[p.ID]; ok {
+		return fmt.Errorf("%w: philosopher %q already exists", ErrConflict, p.ID)
+	}
+	return s.revised("philosopher", p.ID, "create", ch, func() error { return s.putPhilosopher(p) })
+}
+
+// UpdatePhilosopher replaces an existing philosopher.
+func (s *Store) UpdatePhilosopher(p models.Philosopher, ch Change) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.Philosophers[p.ID]; !ok {
+		return ErrNotFound
+	}
+	return s.revised("philosopher", p.ID, "update", ch, func() error { return s.putPhilosopher(p) })
+}
+
+func (s *Store) putPhilosopher(p models.Philosopher) error {
+	if err := CheckPhilosopher(p, s.exists); err != nil {
+		return err
+	}
+	s.Philosophers[p.ID] = p
+	return nil
+}
+
+// DeletePhilosopher removes a philosopher; their quotes become unattributed.
+func (s *Store) DeletePhilosopher(id string, ch Change) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.Philosophers[id]; !ok {
+		return ErrNotFound
+	}
+	return s.revised("philosopher", id, "delete", ch, func() error {
+		delete(s.Philosophers, id)
+		cascaded := Cascaded(ch, "philosopher", id)
+		for qid, q := range s.Quotes {
+			if q.PhilosopherID == id {
+				q.PhilosopherID = ""
+				s.cascade("quote", qid, cascaded, func() { s.Quotes[qid] = q })
+			}
+		}
+		return nil
+	})
+}
+
+// CreatePhilosophy adds a new school.
+func (s *Store) CreatePhilosophy(p models.Philosophy, ch Change) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.Philosophies[p.ID]; ok {
+		return fmt.Errorf("%w: philosophy %q already exists", ErrConflict, p.ID)
+	}
+	return s.revised("philosophy", p.ID, "create", ch, func() error { return s.putPhilosophy(p) })
+}
+
+// UpdatePhilosophy replaces an existing school.
+func (s *Store) UpdatePhilosophy(p models.Philosophy, ch Change) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.Philosophies[p.ID]; !ok {
+		return ErrNotFound
+	}
+	return s.revised("philosophy", p.ID, "update", ch, func() error { return s.putPhilosophy(p) })
+}
+
+func (s *Store) putPhilosophy(p models.Philosophy) error {
+	if err := CheckPhilosophy(p, s.exists); err != nil {
+		return err
+	}
+	s.Philosophies[p.ID] = p
+	return nil
+}
+
+// DeletePhilosophy removes a school and every reference to it.
+func (s *Store) DeletePhilosophy(id string, ch Change) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.Philosophies[id]; !ok {
+		return ErrNotFound
+	}
+	return s.revised("philosophy", id, "delete", ch, func() error {
+		delete(s.Philosophies, id)
+		cascaded := Cascaded(ch, "philosophy", id)
+		for pid, p := range s.Philosophies {
+			if contains(p.RelatedIDs, id) {
+				p.RelatedIDs = without(p.RelatedIDs, id)
+				s.cascade("philosophy", pid, cascaded, func() { s.Philosophies[pid] = p })
+			}
+		}
+		for pid, p := range s.Philosophers {
+			if p.PhilosophyID == id {
+				p.PhilosophyID = ""
+				s.cascade("philosopher", pid, cascaded, func() { s.Philosophers[pid] = p })
+			}
+		}
+		for tid, t := range s.Themes {
+			if contains(t.PhilosophyIDs, id) {
+				t.PhilosophyIDs = without(t.PhilosophyIDs, id)
+				s.cascade("theme", tid, cascaded, func() { s.Themes[tid] = t })
+			}
+		}
+		for qid, q := range s.Quotes {
+			if q.PhilosophyID == id {
+				q.PhilosophyID = ""
+				s.cascade("quote", qid, cascaded, func() { s.Quotes[qid] = q })
+			}
+		}
+		return nil
+	})
+}
+
+// CreateTheme adds a new theme.
+func (s *Store) CreateTheme(t models.Theme, ch Change) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.Themes[t.ID]; ok {
+		return fmt.Errorf("%w: theme %q already exists", ErrConflict, t.ID)
+	}
+	return s.revised("theme", t.ID, "create", ch, func() error { return s.putTheme(t) })
+}
+
+// UpdateTheme replaces an existing theme.
+func (s *Store) UpdateTheme(t models.Theme, ch Change) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.Themes[t.ID]; !ok {
+		return ErrNotFound
+	}
+	return s.revised("theme", t.ID, "update", ch, func() error { return s.putTheme(t) })
+}
+
+func (s *Store) putTheme(t models.Theme) error {
+	if err := CheckTheme(t, s.exists); err != nil {
+		return err
+	}
+	s.Themes[t.ID] = t
+	return nil
+}
+
+// DeleteTheme removes a theme and unlinks it from quotes and evidence.
+func (s *Store) DeleteTheme(id string, ch Change) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.Themes[id]; !ok {
+		return ErrNotFound
+	}
+	return s.revised("theme", id, "delete", ch, func() error {
+		delete(s.Themes, id)
+		cascaded := Cascaded(ch, "theme", id)
+		for qid, q := range s.Quotes {
+			if contains(q.ThemeIDs, id) {
+				q.ThemeIDs = without(q.ThemeIDs, id)
+				s.cascade("quote", qid, cascaded, func() { s.Quotes[qid] = q })
+			}
+		}
+		for eid, e := range s.Evidence {
+			if contains(e.ThemeIDs, id) {
+				e.ThemeIDs = without(e.ThemeIDs, id)
+				s.cascade("evidence", eid, cascaded, func() { s.Evidence[eid] = e })
+			}
+		}
+		return nil
+	})
+}
+
+// CreateEvidence adds a new evidence entry.
+func (s *Store) CreateEvidence(e models.Evidence, ch Change) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.Evidence[e.ID]; ok {
+		return fmt.Errorf("%w: evidence %q already exists", ErrConflict, e.ID)
+	}
+	return s.revised("evidence", e.ID, "create", ch, func() error { return s.putEvidence(e) })
+}
+
+// UpdateEvidence replaces an existing evidence entry.
+func (s *Store) UpdateEvidence(e models.Evidence, ch Change) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.Evidence[e.ID]; !ok {
+		return ErrNotFound
+	}
+	return s.revised("evidence", e.ID, "update", ch, func() error { return s.putEvidence(e) })
+}
+
+func (s *Store) putEvidence(e models.Evidence) error {
+	if err := CheckEvidence(e, s.exists); err != nil {
+		return err
+	}
+	s.Evidence[e.ID] = e
+	return nil
+}
+
+// DeleteEvidence removes an evidence entry and unlinks it from quotes.
+func (s *Store) DeleteEvidence(id string, ch Change) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.Evidence[id]; !ok {
+		return ErrNotFound
+	}
+	return s.revised("evidence", id, "delete", ch, func() error {
+		delete(s.Evidence, id)
+		cascaded := Cascaded(ch, "evidence", id)
+		for qid, q := range s.Quotes {
+			if contains(q.EvidenceIDs, id) {
+				q.EvidenceIDs = without(q.EvidenceIDs, id)
+				s.cascade("quote", qid, cascaded, func() { s.Quotes[qid] = q })
+			}
+		}
+		return nil
+	})
+}
+
+// cascade makes a delete's edit to an entity that referred to the
+// deleted one, recording it in that entity's own history. Callers hold
+// s.mu.
+func (s *Store) cascade(kind, id string, ch Change, edit func()) {
+	s.revised(kind, id, "update", ch, func() error {
+		edit()
+		return nil
+	})
+}
+
+// Batch runs fn against the store, restoring the maps as they were if
+// fn fails. Batches run one at a time, but writes made outside a batch
+// while it runs are undone along with it — the store is for tests and
+// development, not concurrent curation.
+func (s *Store) Batch(fn func(ReadWriter) error) error {
+	s.batch.Lock()
+	defer s.batch.Unlock()
+
+	s.mu.RLock()
+	saved := Store{
+		Quotes:       maps.Clone(s.Quotes),
+		Philosophers: maps.Clone(s.Philosophers),
+		Philosophies: maps.Clone(s.Philosophies),
+		Themes:       maps.Clone(s.Themes),
+		Evidence:     maps.Clone(s.Evidence),
+		revisions:    maps.Clone(s.revisions),
+		stamps:       maps.Clone(s.stamps),
+	}
+	s.mu.RUnlock()
+
+	err := fn(s)
+	if err != nil {
+		s.mu.Lock()
+		s.Quotes, s.Philosophers, s.Philosophies = saved.Quotes, saved.Philosophers, saved.Philosophies
+		s.Themes, s.Evidence, s.revisions = saved.Themes, saved.Evidence, saved.revisions
+		s.stamps = saved.stamps
+		if s.index != nil {
+			s.indexAll()
+		}
+		s.mu.Unlock()
+	}
+	return err
+}
+
+// without returns a copy of ids minus id; stored slices are never mutated.
+func without(ids []string, id string) []string {
+	var out []string
+	for _, x := range ids {
+		if x != id {
+			out = append(out, x)
+		}
+	}
+	return out
+}
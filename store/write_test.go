@@ -0,0 +1,95 @@
+package store_test
+
+import (
+	"errors"
+	"testing"
+
+	"perennial-wisdom/models"
+	"perennial-wisdom/store"
+)
+
+// edit describes the writes tests make.
+var edit = store.Change{Author: "tester", Note: "test edit"}
+
+// The seed corpus must pass the same checks curated writes do.
+func TestSeedsPassWriteChecks(t *testing.T) {
+	s := store.New()
+	exists := func(kind, id string) (bool, error) {
+		var ok bool
+		switch kind {
+		case "philosopher":
+			_, ok = s.Philosophers[id]
+		case "philosophy":
+			_, ok = s.Philosophies[id]
+		case "theme":
+			_, ok = s.Themes[id]
+		case "evidence":
+			_, ok = s.Evidence[id]
+		}
+		return ok, nil
+	}
+	for _, q := range s.Quotes {
+		if err := store.CheckQuote(q, exists); err != nil {
+			t.Errorf("quote %s: %v", q.ID, err)
+		}
+	}
+	for _, p := range s.Philosophers {
+		if err := store.CheckPhilosopher(p, exists); err != nil {
+			t.Errorf("philosopher %s: %v", p.ID, err)
+		}
+	}
+	for _, p := range s.Philosophies {
+		if err := store.CheckPhilosophy(p, exists); err != nil {
+			t.Errorf("philosophy %s: %v", p.ID, err)
+		}
+	}
+	for _, th := range s.Themes {
+		if err := store.CheckTheme(th, exists); err != nil {
+			t.Errorf("theme %s: %v", th.ID, err)
+		}
+	}
+	for _, e := range s.Evidence {
+		if err := store.CheckEvidence(e, exists); err != nil {
+			t.Errorf("evidence %s: %v", e.ID, err)
+		}
+	}
+}
+
+func TestStoreWrites(t *testing.T) {
+	s := store.New()
+
+	q := models.Quote{ID: "new-quote", Slug: "new-quote", Text: "Know thyself.", PhilosopherID: "epictetus", ThemeIDs: []string{"control"}}
+	if err := s.CreateQuote(q, edit); err != nil {
+		t.Fatalf("CreateQuote: %v", err)
+	}
+	if err := s.CreateQuote(q, edit); !errors.Is(err, store.ErrConflict) {
+		t.Errorf("duplicate id: expected ErrConflict, got %v", err)
+	}
+	if err := s.CreateQuote(models.Quote{ID: "other", Slug: "new-quote", Text: "x"}, edit); !errors.Is(err, store.ErrConflict) {
+		t.Errorf("duplicate slug: expected ErrConflict, got %v", err)
+	}
+	if err := s.CreateQuote(models.Quote{ID: "Bad ID", PhilosopherID: "nobody", ThemeIDs: []string{"control", "control"}}, edit); !errors.Is(err, store.ErrInvalid) {
+		t.Errorf("invalid quote: expected ErrInvalid, got %v", err)
+	}
+	if err := s.UpdateQuote(models.Quote{ID: "missing", Text: "x"}, edit); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("update missing: expected ErrNotFound, got %v", err)
+	}
+
+	// Deleting a theme unlinks it; deleting a philosopher unattributes quotes.
+	if err := s.DeleteTheme("control", edit); err != nil {
+		t.Fatalf("DeleteTheme: %v", err)
+	}
+	if err := s.DeletePhilosopher("epictetus", edit); err != nil {
+		t.Fatalf("DeletePhilosopher: %v", err)
+	}
+	got, _ := s.GetQuote("new-quote")
+	if len(got.ThemeIDs) != 0 || got.PhilosopherID != "" {
+		t.Errorf("references not cleared: %+v", got)
+	}
+	if err := s.DeleteQuote("new-quote", edit); err != nil {
+		t.Errorf("DeleteQuote: %v", err)
+	}
+	if _, err := s.GetQuote("new-quote"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("quote still present after delete: %v", err)
+	}
+}